@@ -2,6 +2,7 @@ package v1alpha1
 
 import (
 	"fmt"
+	"path"
 	"time"
 
 	"github.com/open-component-model/ocm-controller/api/v1alpha1"
@@ -13,9 +14,16 @@ import (
 // the parameters that the replication controller will use to replicate a desired Component from
 // a source OCM repository to a destination OCM repository.
 type ComponentSubscriptionSpec struct {
-	// Component specifies the name of the Component that should be replicated.
-	// +required
-	Component string `json:"component"`
+	// Component specifies the name of the Component that should be replicated. Either Component or
+	// ComponentSelector must be set.
+	// +optional
+	Component string `json:"component,omitempty"`
+
+	// ComponentSelector subscribes to all components of the source repository matching the selector. A
+	// ComponentSubscription owned by this one is created for each of them, which replicates the component
+	// according to the remaining settings.
+	// +optional
+	ComponentSelector *ComponentSelector `json:"componentSelector,omitempty"`
 
 	// Semver specifies an optional semver constraint that is used to evaluate the component
 	// versions that should be replicated.
@@ -44,9 +52,382 @@ type ComponentSubscriptionSpec struct {
 	ServiceAccountName string `json:"serviceAccountName,omitempty"`
 
 	// Verify specifies a list signatures that must be verified before a ComponentVersion
-	// is replicated.
+	// is replicated. Multiple entries with the same name are treated as alternative keys for
+	// that signature, e.g. while the publisher rotates its signing key.
+	// +optional
+	Verify []v1alpha1.Signature `json:"verify,omitempty"`
+
+	// VerifyCertificates specifies a list of signatures that must be verified against trusted
+	// root certificate authorities before a ComponentVersion is replicated. Other than Verify,
+	// these signatures are not checked against a pinned public key, but against the certificate
+	// chain that was used to create them.
+	// +optional
+	VerifyCertificates []CertificateSignature `json:"verifyCertificates,omitempty"`
+
+	// VerificationPolicy defines how many of the signatures configured in Verify and VerifyCertificates
+	// must be valid before a ComponentVersion is replicated. By default, all of them must be valid.
+	// +optional
+	VerificationPolicy *VerificationPolicy `json:"verificationPolicy,omitempty"`
+
+	// VerifySecretNamespace specifies the namespace of the Secrets referenced by Verify, VerifyCertificates and
+	// VerifyReferences. It defaults to the namespace of the subscription. Secrets of other namespaces must be
+	// granted to it with a SecretReferenceGrant.
+	// +optional
+	VerifySecretNamespace string `json:"verifySecretNamespace,omitempty"`
+
+	// TrustPolicies references TrustPolicy objects by name that must be satisfied in addition to the
+	// signatures configured on the subscription. TrustPolicies matching the component are always applied.
+	// +optional
+	TrustPolicies []string `json:"trustPolicies,omitempty"`
+
+	// VerifyReferences enables the verification of every component version referenced, directly or
+	// transitively, by the replicated ComponentVersion.
+	// +optional
+	VerifyReferences *ReferenceVerification `json:"verifyReferences,omitempty"`
+
+	// FollowReferences replicates the ComponentVersion without its references. Instead, a ComponentSubscription
+	// owned by this one is created for every component referenced by the replicated version, pinned to the
+	// referenced version. These follow the references of their components in turn, so every component of the tree
+	// is replicated, verified and reported on its own.
+	// +optional
+	FollowReferences bool `json:"followReferences,omitempty"`
+
+	// Provenance adds labels to the replicated ComponentVersion in the destination repository recording
+	// where it was replicated from and by whom. The labels aren't relevant for signing, so signatures
+	// of the source stay valid. The provenance is recorded in a signed attestation as well, as if
+	// Attestation was set.
+	// +optional
+	Provenance bool `json:"provenance,omitempty"`
+
+	// Attestation stores a signed in-toto attestation of every replication next to the replicated
+	// ComponentVersion in the destination repository. It records the source and destination, the results
+	// of the signature verification and the transfer options.
+	// +optional
+	Attestation bool `json:"attestation,omitempty"`
+
+	// VerifyImages enables the verification of Notary Project signatures of the ociImage resources of the
+	// replicated ComponentVersion and its referenced components before they are transferred.
+	// +optional
+	VerifyImages *ImageVerification `json:"verifyImages,omitempty"`
+
+	// Transfer configures how the resources of the ComponentVersion are transferred to the destination repository.
+	// +optional
+	Transfer *TransferSpec `json:"transfer,omitempty"`
+
+	// MirrorImages pushes the ociImage resources of the ComponentVersion and its referenced components as plain
+	// tagged images, so workloads can pull them without OCM. It can be used with or without a Destination.
+	// +optional
+	MirrorImages *ImageMirror `json:"mirrorImages,omitempty"`
+
+	// PullSecret propagates the credentials for pulling from the destination registry to other namespaces, so
+	// workloads can pull the replicated images.
+	// +optional
+	PullSecret *PullSecretPropagation `json:"pullSecret,omitempty"`
+
+	// OCMConfigRef references a ConfigMap or Secret holding an OCM configuration document under the `.ocmconfig`
+	// key, as used by the ocm CLI. It is applied on top of the controller-wide default configuration. Credentials
+	// configured through SecretRefs or the ServiceAccount take precedence. The document must not reference files
+	// of the controller or docker credential helpers.
+	// +optional
+	OCMConfigRef *OCMConfigReference `json:"ocmConfigRef,omitempty"`
+
+	// Mirror replicates every version of the subscribed components instead of the latest one. Without a Component
+	// or ComponentSelector, every component of the source repository is mirrored. Only versions missing in the
+	// destination or changed in the source since they were mirrored are transferred, in batches spread over
+	// several reconciliations. The source digests of the mirrored versions are recorded in the ConfigMap
+	// `<name>-mirror`. Mirror requires a Destination.
+	// +optional
+	Mirror *RepositoryMirror `json:"mirror,omitempty"`
+}
+
+// RepositoryMirror configures how the versions of a repository are mirrored.
+type RepositoryMirror struct {
+	// MaxTransfers limits the number of component versions transferred per reconciliation. Remaining versions
+	// are transferred by the following reconciliations.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=10
+	// +optional
+	MaxTransfers int `json:"maxTransfers,omitempty"`
+}
+
+// GetMaxTransfers returns the number of component versions transferred per reconciliation.
+func (in *RepositoryMirror) GetMaxTransfers() int {
+	if in == nil || in.MaxTransfers < 1 {
+		return DefaultMaxTransfers
+	}
+
+	return in.MaxTransfers
+}
+
+// ComponentSelector selects components of a repository by their name. Either Pattern or Regex must be set.
+type ComponentSelector struct {
+	// Pattern specifies a component name pattern, e.g. `github.com/acme/*`. Patterns use shell file name
+	// matching as implemented by path.Match.
+	// +optional
+	Pattern string `json:"pattern,omitempty"`
+
+	// Regex specifies a regular expression the whole component name must match.
+	// +optional
+	Regex string `json:"regex,omitempty"`
+}
+
+// OCMConfigReference references a ConfigMap or Secret in the namespace of the subscription.
+type OCMConfigReference struct {
+	// Kind of the referenced object.
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	// +kubebuilder:default=Secret
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Name of the referenced object.
+	// +required
+	Name string `json:"name"`
+}
+
+// PullSecretPropagation configures a dockerconfigjson Secret that is kept in sync in other namespaces.
+type PullSecretPropagation struct {
+	// Name of the Secret created in every namespace.
+	// +required
+	Name string `json:"name"`
+
+	// Namespaces the Secret is created in. Namespaces other than the one of the subscription must grant the Secret
+	// with a SecretReferenceGrant.
+	// +required
+	Namespaces []string `json:"namespaces"`
+
+	// SecretRef optionally references a Secret with dedicated, e.g. read-only, credentials for the destination
+	// registry. By default, the credentials of the destination are propagated. The Secret may be of any format
+	// supported by the SecretRef of a repository.
+	// +optional
+	SecretRef *v1.LocalObjectReference `json:"secretRef,omitempty"`
+}
+
+// ImageMirror configures where image resources are mirrored to.
+type ImageMirror struct {
+	// Destination is the OCI repository path the images are pushed to, e.g. `ghcr.io/acme/mirror`. Every image
+	// is pushed as `<url>/<component-name>/<resource-name>:<resource-version>`.
+	// +required
+	Destination OCMRepository `json:"destination"`
+
+	// ConfigMapName optionally names a ConfigMap in the namespace of the subscription the mirrored image
+	// references are written to. The keys are the component and resource names joined with `/`, with every
+	// `/` replaced by `_`.
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+}
+
+// TransferSpec configures the transfer of resources.
+type TransferSpec struct {
+	// Platforms restricts the images of multi-arch image indexes to the given platforms, e.g. `linux/amd64`.
+	// Indexes are trimmed to the matching images and the digests of the affected resources are updated in the
+	// destination repository. Images that are not part of an index are transferred unchanged.
+	// +optional
+	Platforms []string `json:"platforms,omitempty"`
+
+	// References selects the component references that are transferred along with the ComponentVersion. By
+	// default, all of them are transferred.
+	// +optional
+	References *ReferenceSelection `json:"references,omitempty"`
+}
+
+// ReferenceSelection selects component references by the name of the reference or of the referenced component. It
+// applies to the references of referenced components as well. Excluded references and the components they reference
+// are neither transferred nor verified, and their images aren't mirrored.
+type ReferenceSelection struct {
+	// Include restricts the transferred references to those matching one of the rules.
+	// +optional
+	Include []ReferenceRule `json:"include,omitempty"`
+
+	// Exclude prevents references matching one of the rules from being transferred. It takes precedence over
+	// Include.
+	// +optional
+	Exclude []ReferenceRule `json:"exclude,omitempty"`
+
+	// Fallback is the OCM repository excluded references are resolved from instead of the destination, e.g. the
+	// repository they're published to in the target environment. Every excluded reference must be available in it.
+	// +optional
+	Fallback *OCMRepository `json:"fallback,omitempty"`
+}
+
+// ReferenceRule matches component references. If both Name and Component are set, both must match.
+type ReferenceRule struct {
+	// Name specifies a reference name pattern. Patterns use shell file name matching as implemented by
+	// path.Match.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Component specifies a pattern for the name of the referenced component, e.g. `github.com/acme/testing/*`.
+	// +optional
+	Component string `json:"component,omitempty"`
+}
+
+// Matches returns whether the rule matches a reference to a component. A rule without patterns matches nothing.
+func (in ReferenceRule) Matches(name, component string) bool {
+	if in.Name == "" && in.Component == "" {
+		return false
+	}
+
+	matches := func(pattern, value string) bool {
+		ok, _ := path.Match(pattern, value)
+
+		return pattern == "" || ok
+	}
+
+	return matches(in.Name, name) && matches(in.Component, component)
+}
+
+// GetFallback returns the repository excluded references are resolved from, if configured.
+func (in *TransferSpec) GetFallback() *OCMRepository {
+	if in == nil || in.References == nil {
+		return nil
+	}
+
+	return in.References.Fallback
+}
+
+// SelectsReference returns whether a reference to a component is transferred according to the reference selection.
+func (in *TransferSpec) SelectsReference(name, component string) bool {
+	if in == nil || in.References == nil {
+		return true
+	}
+
+	matches := func(rules []ReferenceRule) bool {
+		for _, rule := range rules {
+			if rule.Matches(name, component) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if matches(in.References.Exclude) {
+		return false
+	}
+
+	return len(in.References.Include) == 0 || matches(in.References.Include)
+}
+
+// ImageVerification configures how the OCI signatures of image resources are verified.
+type ImageVerification struct {
+	// TrustStores reference Secrets that contain PEM encoded root certificates under the `ca.crt` key.
+	// Every image must carry a Notary Project signature whose certificate chain is rooted in one of them.
+	// +required
+	TrustStores []v1.LocalObjectReference `json:"trustStores"`
+
+	// TrustedIdentities optionally restrict the signing certificates using the trusted identity syntax of
+	// Notary Project trust policies, e.g. `x509.subject: C=DE, ST=BW, O=acme`. Any signing certificate
+	// rooted in the trust stores is accepted if none are given.
+	// +optional
+	TrustedIdentities []string `json:"trustedIdentities,omitempty"`
+
+	// CopySignatures copies the signatures of the images to the destination repository along with
+	// the images.
+	// +optional
+	CopySignatures bool `json:"copySignatures,omitempty"`
+}
+
+// ReferenceVerification configures how referenced components are verified and signed.
+type ReferenceVerification struct {
+	// Components specifies the signatures referenced components must carry. Each referenced component
+	// must satisfy the first entry matching its name. Components without a matching entry must carry the
+	// signatures configured on the subscription. TrustPolicies matching a referenced component are
+	// enforced as well.
+	// +optional
+	Components []ComponentSignatures `json:"components,omitempty"`
+
+	// Sign additionally signs every referenced component with the replication signature if MPAS is enabled.
+	// +optional
+	Sign bool `json:"sign,omitempty"`
+}
+
+// ComponentSignatures defines the signatures that components matching a name pattern must carry.
+type ComponentSignatures struct {
+	// Name specifies a component name pattern, e.g. `github.com/acme/*`. Patterns use shell file name
+	// matching as implemented by path.Match.
+	// +required
+	Name string `json:"name"`
+
+	// Verify specifies a list of signatures that must be verified. Multiple entries with the same
+	// name are treated as alternative keys for that signature.
 	// +optional
 	Verify []v1alpha1.Signature `json:"verify,omitempty"`
+
+	// VerifyCertificates specifies a list of signatures that must be verified against trusted
+	// root certificate authorities.
+	// +optional
+	VerifyCertificates []CertificateSignature `json:"verifyCertificates,omitempty"`
+
+	// VerificationPolicy defines how many of the configured signatures must be valid. By default,
+	// all of them must be valid.
+	// +optional
+	VerificationPolicy *VerificationPolicy `json:"verificationPolicy,omitempty"`
+}
+
+// VerificationPolicyType defines how the results of individual signature verifications are combined.
+type VerificationPolicyType string
+
+const (
+	// VerificationPolicyAllOf requires every configured signature to be valid.
+	VerificationPolicyAllOf VerificationPolicyType = "AllOf"
+
+	// VerificationPolicyAnyOf requires at least one configured signature to be valid.
+	VerificationPolicyAnyOf VerificationPolicyType = "AnyOf"
+
+	// VerificationPolicyAtLeast requires at least MinSignatures of the configured signatures to be valid.
+	VerificationPolicyAtLeast VerificationPolicyType = "AtLeast"
+)
+
+// VerificationPolicy defines how many signatures must be valid for a ComponentVersion to be replicated.
+// +kubebuilder:validation:XValidation:rule="!has(self.type) || self.type != 'AtLeast' || has(self.minSignatures)",message="minSignatures is required if type is AtLeast"
+type VerificationPolicy struct {
+	// Type specifies how the individual signature verifications are combined.
+	// +kubebuilder:validation:Enum=AllOf;AnyOf;AtLeast
+	// +kubebuilder:default=AllOf
+	// +optional
+	Type VerificationPolicyType `json:"type,omitempty"`
+
+	// MinSignatures specifies the number of signatures that must be valid if Type is AtLeast. It's required
+	// for that type.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinSignatures int `json:"minSignatures,omitempty"`
+}
+
+// GetRequiredSignatures returns how many of the given number of configured signatures must be
+// valid according to the policy. A nil policy requires all of them.
+func (in *VerificationPolicy) GetRequiredSignatures(configured int) int {
+	if in == nil {
+		return configured
+	}
+
+	switch in.Type {
+	case VerificationPolicyAnyOf:
+		return 1
+	case VerificationPolicyAtLeast:
+		return in.MinSignatures
+	default:
+		return configured
+	}
+}
+
+// CertificateSignature defines a signature that is verified using the certificate chain embedded in
+// the signature and a set of trusted root certificates.
+type CertificateSignature struct {
+	// Name specifies the name of the signature.
+	// +required
+	Name string `json:"name"`
+
+	// RootCertificatesSecretRef references a Secret that contains the PEM encoded root certificates
+	// under the `ca.crt` key. The certificate chain of the signature must be rooted in one of them.
+	// +required
+	RootCertificatesSecretRef v1.LocalObjectReference `json:"rootCertificatesSecretRef"`
+
+	// Subject optionally constrains the subject distinguished name of the signing certificate, i.e. the
+	// leaf of the chain, e.g. `CN=team-a,O=acme`. Only the given name attributes are matched. If empty,
+	// any certificate issued by one of the root certificates is accepted.
+	// +optional
+	Subject string `json:"subject,omitempty"`
 }
 
 // OCMRepository specifies access details for an OCI based OCM Repository.
@@ -55,7 +436,84 @@ type OCMRepository struct {
 	// +required
 	URL string `json:"url"`
 
-	// SecretRef specifies the credentials used to access the OCI registry.
+	// SecretRef specifies the credentials used to access the OCI registry. It references a Secret of type
+	// kubernetes.io/dockerconfigjson, kubernetes.io/dockercfg or kubernetes.io/basic-auth, or a Secret with a
+	// `token`. The Secret is looked up in the namespace of the subscription unless a namespace is set. Secrets of
+	// other namespaces must be granted to it with a SecretReferenceGrant.
+	// +optional
+	SecretRef *v1.SecretReference `json:"secretRef,omitempty"`
+
+	// Credentials configures a source the credentials are obtained from at reconcile time, instead of a
+	// Secret. It is mutually exclusive with SecretRef.
+	// +optional
+	Credentials *RepositoryCredentials `json:"credentials,omitempty"`
+
+	// CertSecretRef references a Secret with a PEM encoded CA bundle under the `ca.crt` key that is used to
+	// verify the certificate of the OCI registry instead of the system roots. It may additionally contain a
+	// client certificate and key under `tls.crt` and `tls.key` for mutual TLS. Like SecretRef, it may reference a
+	// granted Secret of another namespace.
+	// +optional
+	CertSecretRef *v1.SecretReference `json:"certSecretRef,omitempty"`
+
+	// Insecure skips the verification of the certificate of the OCI registry.
+	// +optional
+	Insecure bool `json:"insecure,omitempty"`
+
+	// PlainHTTP accesses the OCI registry over HTTP instead of HTTPS.
+	// +optional
+	PlainHTTP bool `json:"plainHTTP,omitempty"`
+
+	// Proxy configures an HTTP proxy for requests to the OCI registry, overriding the proxy environment
+	// variables of the controller.
+	// +optional
+	Proxy *ProxyConfig `json:"proxy,omitempty"`
+}
+
+// RepositoryCredentials configures how the credentials of a repository are obtained.
+type RepositoryCredentials struct {
+	// Exec runs a credential helper plugin to obtain the credentials.
+	// +optional
+	Exec *ExecCredentials `json:"exec,omitempty"`
+}
+
+// ExecCredentials runs a credential helper compatible with the docker credential helper protocol. The command is
+// called with the `get` argument and the registry host on stdin, and prints the credentials as JSON. An optional
+// `ExpiresAt` timestamp in RFC 3339 format in the output determines how long the credentials are cached.
+type ExecCredentials struct {
+	// Command is the credential helper binary. It must be allowed by the controller.
+	// +required
+	Command string `json:"command"`
+
+	// Args are passed to the command before the `get` argument. Only options, i.e. arguments starting with `-`,
+	// are accepted.
+	// +optional
+	Args []string `json:"args,omitempty"`
+
+	// Env specifies environment variables set in addition to those of the controller. Their names must be allowed
+	// by the controller.
+	// +optional
+	Env []ExecEnvVar `json:"env,omitempty"`
+}
+
+// ExecEnvVar is an environment variable set for a credential helper.
+type ExecEnvVar struct {
+	// Name of the environment variable.
+	// +required
+	Name string `json:"name"`
+
+	// Value of the environment variable.
+	// +required
+	Value string `json:"value"`
+}
+
+// ProxyConfig configures an HTTP proxy.
+type ProxyConfig struct {
+	// URL of the proxy, e.g. `http://proxy.internal:3128`.
+	// +required
+	URL string `json:"url"`
+
+	// SecretRef optionally references a Secret with the `username` and `password` used to authenticate
+	// with the proxy.
 	// +optional
 	SecretRef *v1.LocalObjectReference `json:"secretRef,omitempty"`
 }
@@ -84,12 +542,250 @@ type ComponentSubscriptionStatus struct {
 	// +optional
 	Signature []v1alpha1.Signature `json:"signature,omitempty"`
 
+	// Verifications contains the result of verifying each configured signature of the last attempted version.
+	// +optional
+	Verifications []SignatureVerification `json:"verifications,omitempty"`
+
+	// ImageVerifications contains the result of verifying the signatures of the image resources of the
+	// last attempted version.
+	// +optional
+	ImageVerifications []ImageSignatureVerification `json:"imageVerifications,omitempty"`
+
+	// MirroredImages lists the images mirrored for the last applied version.
+	// +optional
+	MirroredImages []MirroredImage `json:"mirroredImages,omitempty"`
+
+	// PullSecrets lists the propagated pull secrets in the form `namespace/name`.
+	// +optional
+	PullSecrets []string `json:"pullSecrets,omitempty"`
+
+	// Attestation describes the attestation stored for the last applied version.
+	// +optional
+	Attestation *ReplicationAttestation `json:"attestation,omitempty"`
+
+	// References lists the component references of the last attempted version and of the referenced components,
+	// and whether they were transferred.
+	// +optional
+	References []TransferredReference `json:"references,omitempty"`
+
+	// Components lists the components matching the ComponentSelector or referenced by the last applied version,
+	// and the state of their subscriptions.
+	// +optional
+	Components []SubscribedComponent `json:"components,omitempty"`
+
+	// Mirror describes the progress of mirroring if Mirror is configured.
+	// +optional
+	Mirror *MirrorStatus `json:"mirror,omitempty"`
+
 	// +optional
 	// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description=""
 	// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].message",description=""
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+// TransferredReference describes a component reference of the replicated ComponentVersion or one of its referenced
+// components.
+type TransferredReference struct {
+	// Parent identifies the referencing component version in the form `name:version`.
+	Parent string `json:"parent"`
+
+	// Name is the name of the reference.
+	Name string `json:"name"`
+
+	// Component identifies the referenced component version in the form `name:version`.
+	Component string `json:"component"`
+
+	// Transferred is true if the referenced component version was transferred. Excluded references are resolved
+	// from the fallback repository, if one is configured.
+	Transferred bool `json:"transferred"`
+}
+
+// SubscribedComponent describes the subscription of a component matching the ComponentSelector or referenced by
+// the last applied version.
+type SubscribedComponent struct {
+	// Name of the component.
+	Name string `json:"name"`
+
+	// Version is the referenced version the subscription is pinned to. It is empty for components matching the
+	// ComponentSelector.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Subscription is the name of the ComponentSubscription replicating the component.
+	Subscription string `json:"subscription"`
+
+	// LastAppliedVersion is the last version replicated by the subscription.
+	// +optional
+	LastAppliedVersion string `json:"lastAppliedVersion,omitempty"`
+
+	// Ready is true if the subscription is ready.
+	Ready bool `json:"ready"`
+
+	// Message contains the status message of the subscription.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// MirrorStatus describes the progress of a mirror run. A run plans the component versions to transfer and works
+// through them over several reconciliations, so an interrupted run resumes where it stopped.
+type MirrorStatus struct {
+	// Generation is the generation of the subscription the current run was planned for. A new run is planned
+	// if the subscription changes.
+	Generation int64 `json:"generation"`
+
+	// StartedAt is the time the current or last run was planned.
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// CompletedAt is the time the last run transferred its last component version.
+	// +optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+
+	// Queue lists the component versions of the current run that remain to be transferred. It holds up to 500
+	// versions, the following ones are queued once it's empty.
+	// +optional
+	Queue []MirrorQueueEntry `json:"queue,omitempty"`
+
+	// Continue is the component version the queue is refilled after once it's empty, if the current run has more
+	// versions to transfer than the queue holds. Version is empty if no version of the component was planned yet.
+	// +optional
+	Continue *MirrorQueueEntry `json:"continue,omitempty"`
+
+	// Summary counts the components and versions of the current or last run.
+	Summary MirrorSummary `json:"summary"`
+
+	// Failures lists the component versions of the current or last run that couldn't be transferred. They're
+	// retried by the next run.
+	// +optional
+	Failures []MirrorFailure `json:"failures,omitempty"`
+}
+
+// MirrorQueueEntry identifies a component version waiting to be transferred.
+type MirrorQueueEntry struct {
+	// Component is the name of the component.
+	Component string `json:"component"`
+
+	// Version is the version of the component.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Digest is the manifest digest of the component version in the source repository when it was queued. It's
+	// recorded once the version is transferred, so the following runs detect changes in the source.
+	// +optional
+	Digest string `json:"digest,omitempty"`
+}
+
+// MirrorSummary counts the components and versions of a mirror run.
+type MirrorSummary struct {
+	// Components is the number of mirrored components.
+	Components int `json:"components"`
+
+	// Versions is the number of versions in the source repository of the components planned so far.
+	Versions int `json:"versions"`
+
+	// UpToDate is the number of versions that were already mirrored when their component was planned.
+	UpToDate int `json:"upToDate"`
+
+	// Transferred is the number of versions transferred by the run.
+	Transferred int `json:"transferred"`
+
+	// Pending is the number of queued versions that remain to be transferred.
+	Pending int `json:"pending"`
+
+	// Failed is the number of versions or components that couldn't be mirrored.
+	Failed int `json:"failed"`
+}
+
+// MirrorFailure describes a component or component version that couldn't be mirrored.
+type MirrorFailure struct {
+	// Component is the name of the component.
+	Component string `json:"component"`
+
+	// Version is the version of the component. It is empty if the versions of the component couldn't be listed.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Message contains the reason of the failure.
+	Message string `json:"message"`
+}
+
+// ImageSignatureVerification describes the outcome of verifying the signatures of a single image resource.
+type ImageSignatureVerification struct {
+	// Component identifies the component version of the resource in the form `name:version`.
+	Component string `json:"component"`
+
+	// Resource is the name of the image resource.
+	Resource string `json:"resource"`
+
+	// Image is the digest reference of the verified image.
+	Image string `json:"image"`
+
+	// Verified is true if one of the signatures of the image could be verified.
+	Verified bool `json:"verified"`
+
+	// Signer is the distinguished name of the certificate that created the verified signature.
+	// +optional
+	Signer string `json:"signer,omitempty"`
+
+	// Message contains the reason why the image could not be verified.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// MirroredImage describes an image resource pushed as a plain tagged image.
+type MirroredImage struct {
+	// Component identifies the component version of the resource in the form `name:version`.
+	Component string `json:"component"`
+
+	// Resource is the name of the image resource.
+	Resource string `json:"resource"`
+
+	// Source is the reference of the image the resource points to.
+	Source string `json:"source"`
+
+	// Image is the tagged reference of the mirrored image.
+	Image string `json:"image"`
+
+	// Digest is the digest of the mirrored image.
+	Digest string `json:"digest"`
+}
+
+// ReplicationAttestation describes a signed attestation stored in the destination repository.
+type ReplicationAttestation struct {
+	// Reference is the OCI reference of the attestation in the destination repository.
+	Reference string `json:"reference"`
+
+	// KeyID identifies the key the attestation was signed with.
+	KeyID string `json:"keyID"`
+
+	// PublicKey is the base64 encoded PEM public key or certificate chain of the signing key.
+	// +optional
+	PublicKey string `json:"publicKey,omitempty"`
+}
+
+// SignatureVerification describes the outcome of verifying a single named signature.
+type SignatureVerification struct {
+	// Name specifies the name of the signature.
+	Name string `json:"name"`
+
+	// TrustPolicy names the TrustPolicy that requires the signature. It is empty for signatures
+	// configured on the subscription itself.
+	// +optional
+	TrustPolicy string `json:"trustPolicy,omitempty"`
+
+	// Component identifies the referenced component version the signature was verified on in the form
+	// `name:version`. It is empty for the replicated ComponentVersion itself.
+	// +optional
+	Component string `json:"component,omitempty"`
+
+	// Verified is true if the signature could be verified with one of its configured keys.
+	Verified bool `json:"verified"`
+
+	// Message contains the reason why the signature could not be verified.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
 func (in *ComponentSubscription) GetVID() map[string]string {
 	vid := fmt.Sprintf("%s:%s", in.Status.LastAttemptedVersion, in.Status.LastAppliedVersion)
 	metadata := make(map[string]string)
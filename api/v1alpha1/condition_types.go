@@ -18,4 +18,27 @@ const (
 
 	// ComponentSigningFailedReason is used when we can't sign the component that will be transferred.
 	ComponentSigningFailedReason = "ComponentSigningFailed"
+
+	// ProvenanceFailedReason is used when we can't add provenance labels to the replicated component.
+	ProvenanceFailedReason = "ProvenanceFailed"
+
+	// AttestationFailedReason is used when we can't create or store the replication attestation.
+	AttestationFailedReason = "AttestationFailed"
+
+	// MirrorImagesFailedReason is used when we can't mirror the image resources of a component.
+	MirrorImagesFailedReason = "MirrorImagesFailed"
+
+	// PullSecretFailedReason is used when we can't propagate the pull secret of a subscription.
+	PullSecretFailedReason = "PullSecretFailed"
+
+	// ComponentDiscoveryFailedReason is used when we can't list or subscribe to the components matching the
+	// component selector.
+	ComponentDiscoveryFailedReason = "ComponentDiscoveryFailed"
+
+	// MirrorFailedReason is used when component versions of a mirror run couldn't be transferred.
+	MirrorFailedReason = "MirrorFailed"
+
+	// FollowReferencesFailedReason is used when we can't subscribe to the components referenced by the replicated
+	// component.
+	FollowReferencesFailedReason = "FollowReferencesFailed"
 )
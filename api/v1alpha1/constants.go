@@ -10,7 +10,31 @@ const (
 	InternalSignatureName = "replication-controller-signed"
 )
 
+const (
+	// DefaultMaxTransfers is the default number of component versions mirrored per reconciliation.
+	DefaultMaxTransfers = 10
+)
+
 const (
 	// ProductDescriptionType defines the type of the ProductDescription resource in the component version.
 	ProductDescriptionType = "productdescription.mpas.ocm.software"
 )
+
+const (
+	// RegistryAnnotation names the registry host the credentials of an image pull Secret that isn't a docker config
+	// are used for, e.g. `ghcr.io`. The Secret may hold the host under a `registry` key instead.
+	RegistryAnnotation = "delivery.ocm.software/registry"
+)
+
+const (
+	// SourceRepositoryLabel records the URL of the repository a ComponentVersion was replicated from.
+	SourceRepositoryLabel = "replication.ocm.software/source-repository"
+	// SourceDigestLabel records the digest of the source component descriptor.
+	SourceDigestLabel = "replication.ocm.software/source-digest"
+	// ReplicatedAtLabel records the time a ComponentVersion was replicated.
+	ReplicatedAtLabel = "replication.ocm.software/replicated-at"
+	// SubscriptionLabel records the namespace and name of the replicating ComponentSubscription.
+	SubscriptionLabel = "replication.ocm.software/subscription"
+	// ControllerVersionLabel records the version of the replication controller.
+	ControllerVersionLabel = "replication.ocm.software/controller-version"
+)
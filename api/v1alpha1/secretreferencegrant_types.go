@@ -0,0 +1,53 @@
+package v1alpha1
+
+import (
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SecretReferenceGrantSpec defines which namespaces may reference Secrets of the namespace of the grant.
+type SecretReferenceGrantSpec struct {
+	// From specifies the namespaces whose ComponentSubscriptions may reference the Secrets.
+	// +required
+	From []string `json:"from"`
+
+	// SecretNames optionally restricts the grant to the named Secrets. By default, every Secret of the
+	// namespace is granted.
+	// +optional
+	SecretNames []string `json:"secretNames,omitempty"`
+}
+
+// Allows returns true if subscriptions of the given namespace may reference the named Secret.
+func (in *SecretReferenceGrant) Allows(namespace, name string) bool {
+	if !slices.Contains(in.Spec.From, namespace) {
+		return false
+	}
+
+	return len(in.Spec.SecretNames) == 0 || slices.Contains(in.Spec.SecretNames, name)
+}
+
+//+kubebuilder:resource:shortName=srg
+//+kubebuilder:object:root=true
+
+// SecretReferenceGrant is the Schema for the secretreferencegrants API. Created in the namespace of shared
+// Secrets, it allows ComponentSubscriptions of other namespaces to reference them.
+type SecretReferenceGrant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SecretReferenceGrantSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// SecretReferenceGrantList contains a list of SecretReferenceGrant.
+type SecretReferenceGrantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SecretReferenceGrant `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SecretReferenceGrant{}, &SecretReferenceGrantList{})
+}
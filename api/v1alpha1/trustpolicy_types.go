@@ -0,0 +1,75 @@
+package v1alpha1
+
+import (
+	"path"
+
+	"github.com/open-component-model/ocm-controller/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TrustPolicySpec defines the signatures that components matching the policy must carry before
+// any ComponentSubscription is allowed to replicate them.
+// +kubebuilder:validation:XValidation:rule="has(self.secretNamespace) || ((!has(self.verify) || self.verify.all(s, !has(s.publicKey.secretRef))) && (!has(self.verifyCertificates) || size(self.verifyCertificates) == 0))",message="secretNamespace is required if the policy references Secrets"
+type TrustPolicySpec struct {
+	// Components specifies a list of component name patterns the policy applies to, e.g.
+	// `github.com/acme/*`. Patterns use shell file name matching as implemented by path.Match.
+	// A policy without patterns only applies to subscriptions that reference it by name.
+	// +optional
+	Components []string `json:"components,omitempty"`
+
+	// Verify specifies a list of signatures that must be verified. Multiple entries with the same
+	// name are treated as alternative keys for that signature.
+	// +optional
+	Verify []v1alpha1.Signature `json:"verify,omitempty"`
+
+	// VerifyCertificates specifies a list of signatures that must be verified against trusted
+	// root certificate authorities.
+	// +optional
+	VerifyCertificates []CertificateSignature `json:"verifyCertificates,omitempty"`
+
+	// VerificationPolicy defines how many of the configured signatures must be valid. By default,
+	// all of them must be valid.
+	// +optional
+	VerificationPolicy *VerificationPolicy `json:"verificationPolicy,omitempty"`
+
+	// SecretNamespace specifies the namespace of the Secrets referenced by this policy. It's required if
+	// the policy references any Secret.
+	// +optional
+	SecretNamespace string `json:"secretNamespace,omitempty"`
+}
+
+// Matches returns true if the policy applies to the given component name.
+func (in *TrustPolicy) Matches(component string) bool {
+	for _, pattern := range in.Spec.Components {
+		if ok, _ := path.Match(pattern, component); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+//+kubebuilder:resource:scope=Cluster,shortName=tp
+//+kubebuilder:object:root=true
+
+// TrustPolicy is the Schema for the trustpolicies API. It allows enforcing signature verification
+// centrally for every ComponentSubscription that replicates a matching component.
+type TrustPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec TrustPolicySpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// TrustPolicyList contains a list of TrustPolicy.
+type TrustPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TrustPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TrustPolicy{}, &TrustPolicyList{})
+}
@@ -11,6 +11,22 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateSignature) DeepCopyInto(out *CertificateSignature) {
+	*out = *in
+	out.RootCertificatesSecretRef = in.RootCertificatesSecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateSignature.
+func (in *CertificateSignature) DeepCopy() *CertificateSignature {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateSignature)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Component) DeepCopyInto(out *Component) {
 	*out = *in
@@ -27,6 +43,53 @@ func (in *Component) DeepCopy() *Component {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentSelector) DeepCopyInto(out *ComponentSelector) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentSelector.
+func (in *ComponentSelector) DeepCopy() *ComponentSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentSignatures) DeepCopyInto(out *ComponentSignatures) {
+	*out = *in
+	if in.Verify != nil {
+		in, out := &in.Verify, &out.Verify
+		*out = make([]apiv1alpha1.Signature, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VerifyCertificates != nil {
+		in, out := &in.VerifyCertificates, &out.VerifyCertificates
+		*out = make([]CertificateSignature, len(*in))
+		copy(*out, *in)
+	}
+	if in.VerificationPolicy != nil {
+		in, out := &in.VerificationPolicy, &out.VerificationPolicy
+		*out = new(VerificationPolicy)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentSignatures.
+func (in *ComponentSignatures) DeepCopy() *ComponentSignatures {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentSignatures)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComponentSubscription) DeepCopyInto(out *ComponentSubscription) {
 	*out = *in
@@ -89,6 +152,11 @@ func (in *ComponentSubscriptionList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComponentSubscriptionSpec) DeepCopyInto(out *ComponentSubscriptionSpec) {
 	*out = *in
+	if in.ComponentSelector != nil {
+		in, out := &in.ComponentSelector, &out.ComponentSelector
+		*out = new(ComponentSelector)
+		**out = **in
+	}
 	in.Source.DeepCopyInto(&out.Source)
 	if in.Destination != nil {
 		in, out := &in.Destination, &out.Destination
@@ -103,6 +171,56 @@ func (in *ComponentSubscriptionSpec) DeepCopyInto(out *ComponentSubscriptionSpec
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.VerifyCertificates != nil {
+		in, out := &in.VerifyCertificates, &out.VerifyCertificates
+		*out = make([]CertificateSignature, len(*in))
+		copy(*out, *in)
+	}
+	if in.VerificationPolicy != nil {
+		in, out := &in.VerificationPolicy, &out.VerificationPolicy
+		*out = new(VerificationPolicy)
+		**out = **in
+	}
+	if in.TrustPolicies != nil {
+		in, out := &in.TrustPolicies, &out.TrustPolicies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VerifyReferences != nil {
+		in, out := &in.VerifyReferences, &out.VerifyReferences
+		*out = new(ReferenceVerification)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VerifyImages != nil {
+		in, out := &in.VerifyImages, &out.VerifyImages
+		*out = new(ImageVerification)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Transfer != nil {
+		in, out := &in.Transfer, &out.Transfer
+		*out = new(TransferSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MirrorImages != nil {
+		in, out := &in.MirrorImages, &out.MirrorImages
+		*out = new(ImageMirror)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PullSecret != nil {
+		in, out := &in.PullSecret, &out.PullSecret
+		*out = new(PullSecretPropagation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OCMConfigRef != nil {
+		in, out := &in.OCMConfigRef, &out.OCMConfigRef
+		*out = new(OCMConfigReference)
+		**out = **in
+	}
+	if in.Mirror != nil {
+		in, out := &in.Mirror, &out.Mirror
+		*out = new(RepositoryMirror)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentSubscriptionSpec.
@@ -125,6 +243,46 @@ func (in *ComponentSubscriptionStatus) DeepCopyInto(out *ComponentSubscriptionSt
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Verifications != nil {
+		in, out := &in.Verifications, &out.Verifications
+		*out = make([]SignatureVerification, len(*in))
+		copy(*out, *in)
+	}
+	if in.ImageVerifications != nil {
+		in, out := &in.ImageVerifications, &out.ImageVerifications
+		*out = make([]ImageSignatureVerification, len(*in))
+		copy(*out, *in)
+	}
+	if in.MirroredImages != nil {
+		in, out := &in.MirroredImages, &out.MirroredImages
+		*out = make([]MirroredImage, len(*in))
+		copy(*out, *in)
+	}
+	if in.PullSecrets != nil {
+		in, out := &in.PullSecrets, &out.PullSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Attestation != nil {
+		in, out := &in.Attestation, &out.Attestation
+		*out = new(ReplicationAttestation)
+		**out = **in
+	}
+	if in.References != nil {
+		in, out := &in.References, &out.References
+		*out = make([]TransferredReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]SubscribedComponent, len(*in))
+		copy(*out, *in)
+	}
+	if in.Mirror != nil {
+		in, out := &in.Mirror, &out.Mirror
+		*out = new(MirrorStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]metav1.Condition, len(*in))
@@ -144,14 +302,239 @@ func (in *ComponentSubscriptionStatus) DeepCopy() *ComponentSubscriptionStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecCredentials) DeepCopyInto(out *ExecCredentials) {
+	*out = *in
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]ExecEnvVar, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecCredentials.
+func (in *ExecCredentials) DeepCopy() *ExecCredentials {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecCredentials)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecEnvVar) DeepCopyInto(out *ExecEnvVar) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecEnvVar.
+func (in *ExecEnvVar) DeepCopy() *ExecEnvVar {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecEnvVar)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageMirror) DeepCopyInto(out *ImageMirror) {
+	*out = *in
+	in.Destination.DeepCopyInto(&out.Destination)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageMirror.
+func (in *ImageMirror) DeepCopy() *ImageMirror {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageMirror)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageSignatureVerification) DeepCopyInto(out *ImageSignatureVerification) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageSignatureVerification.
+func (in *ImageSignatureVerification) DeepCopy() *ImageSignatureVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageSignatureVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageVerification) DeepCopyInto(out *ImageVerification) {
+	*out = *in
+	if in.TrustStores != nil {
+		in, out := &in.TrustStores, &out.TrustStores
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.TrustedIdentities != nil {
+		in, out := &in.TrustedIdentities, &out.TrustedIdentities
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageVerification.
+func (in *ImageVerification) DeepCopy() *ImageVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MirrorFailure) DeepCopyInto(out *MirrorFailure) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MirrorFailure.
+func (in *MirrorFailure) DeepCopy() *MirrorFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(MirrorFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MirrorQueueEntry) DeepCopyInto(out *MirrorQueueEntry) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MirrorQueueEntry.
+func (in *MirrorQueueEntry) DeepCopy() *MirrorQueueEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(MirrorQueueEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MirrorStatus) DeepCopyInto(out *MirrorStatus) {
+	*out = *in
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletedAt != nil {
+		in, out := &in.CompletedAt, &out.CompletedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Queue != nil {
+		in, out := &in.Queue, &out.Queue
+		*out = make([]MirrorQueueEntry, len(*in))
+		copy(*out, *in)
+	}
+	if in.Continue != nil {
+		in, out := &in.Continue, &out.Continue
+		*out = new(MirrorQueueEntry)
+		**out = **in
+	}
+	out.Summary = in.Summary
+	if in.Failures != nil {
+		in, out := &in.Failures, &out.Failures
+		*out = make([]MirrorFailure, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MirrorStatus.
+func (in *MirrorStatus) DeepCopy() *MirrorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MirrorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MirrorSummary) DeepCopyInto(out *MirrorSummary) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MirrorSummary.
+func (in *MirrorSummary) DeepCopy() *MirrorSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(MirrorSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MirroredImage) DeepCopyInto(out *MirroredImage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MirroredImage.
+func (in *MirroredImage) DeepCopy() *MirroredImage {
+	if in == nil {
+		return nil
+	}
+	out := new(MirroredImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OCMConfigReference) DeepCopyInto(out *OCMConfigReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OCMConfigReference.
+func (in *OCMConfigReference) DeepCopy() *OCMConfigReference {
+	if in == nil {
+		return nil
+	}
+	out := new(OCMConfigReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OCMRepository) DeepCopyInto(out *OCMRepository) {
 	*out = *in
 	if in.SecretRef != nil {
 		in, out := &in.SecretRef, &out.SecretRef
-		*out = new(v1.LocalObjectReference)
+		*out = new(v1.SecretReference)
+		**out = **in
+	}
+	if in.Credentials != nil {
+		in, out := &in.Credentials, &out.Credentials
+		*out = new(RepositoryCredentials)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CertSecretRef != nil {
+		in, out := &in.CertSecretRef, &out.CertSecretRef
+		*out = new(v1.SecretReference)
 		**out = **in
 	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(ProxyConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OCMRepository.
@@ -164,6 +547,118 @@ func (in *OCMRepository) DeepCopy() *OCMRepository {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProxyConfig) DeepCopyInto(out *ProxyConfig) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxyConfig.
+func (in *ProxyConfig) DeepCopy() *ProxyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ProxyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PullSecretPropagation) DeepCopyInto(out *PullSecretPropagation) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullSecretPropagation.
+func (in *PullSecretPropagation) DeepCopy() *PullSecretPropagation {
+	if in == nil {
+		return nil
+	}
+	out := new(PullSecretPropagation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReferenceRule) DeepCopyInto(out *ReferenceRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReferenceRule.
+func (in *ReferenceRule) DeepCopy() *ReferenceRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ReferenceRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReferenceSelection) DeepCopyInto(out *ReferenceSelection) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]ReferenceRule, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]ReferenceRule, len(*in))
+		copy(*out, *in)
+	}
+	if in.Fallback != nil {
+		in, out := &in.Fallback, &out.Fallback
+		*out = new(OCMRepository)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReferenceSelection.
+func (in *ReferenceSelection) DeepCopy() *ReferenceSelection {
+	if in == nil {
+		return nil
+	}
+	out := new(ReferenceSelection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReferenceVerification) DeepCopyInto(out *ReferenceVerification) {
+	*out = *in
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]ComponentSignatures, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReferenceVerification.
+func (in *ReferenceVerification) DeepCopy() *ReferenceVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(ReferenceVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Registry) DeepCopyInto(out *Registry) {
 	*out = *in
@@ -178,3 +673,316 @@ func (in *Registry) DeepCopy() *Registry {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationAttestation) DeepCopyInto(out *ReplicationAttestation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationAttestation.
+func (in *ReplicationAttestation) DeepCopy() *ReplicationAttestation {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicationAttestation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryCredentials) DeepCopyInto(out *RepositoryCredentials) {
+	*out = *in
+	if in.Exec != nil {
+		in, out := &in.Exec, &out.Exec
+		*out = new(ExecCredentials)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryCredentials.
+func (in *RepositoryCredentials) DeepCopy() *RepositoryCredentials {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryCredentials)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryMirror) DeepCopyInto(out *RepositoryMirror) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryMirror.
+func (in *RepositoryMirror) DeepCopy() *RepositoryMirror {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryMirror)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretReferenceGrant) DeepCopyInto(out *SecretReferenceGrant) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretReferenceGrant.
+func (in *SecretReferenceGrant) DeepCopy() *SecretReferenceGrant {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretReferenceGrant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SecretReferenceGrant) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretReferenceGrantList) DeepCopyInto(out *SecretReferenceGrantList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SecretReferenceGrant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretReferenceGrantList.
+func (in *SecretReferenceGrantList) DeepCopy() *SecretReferenceGrantList {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretReferenceGrantList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SecretReferenceGrantList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretReferenceGrantSpec) DeepCopyInto(out *SecretReferenceGrantSpec) {
+	*out = *in
+	if in.From != nil {
+		in, out := &in.From, &out.From
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretNames != nil {
+		in, out := &in.SecretNames, &out.SecretNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretReferenceGrantSpec.
+func (in *SecretReferenceGrantSpec) DeepCopy() *SecretReferenceGrantSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretReferenceGrantSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SignatureVerification) DeepCopyInto(out *SignatureVerification) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SignatureVerification.
+func (in *SignatureVerification) DeepCopy() *SignatureVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(SignatureVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubscribedComponent) DeepCopyInto(out *SubscribedComponent) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubscribedComponent.
+func (in *SubscribedComponent) DeepCopy() *SubscribedComponent {
+	if in == nil {
+		return nil
+	}
+	out := new(SubscribedComponent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransferSpec) DeepCopyInto(out *TransferSpec) {
+	*out = *in
+	if in.Platforms != nil {
+		in, out := &in.Platforms, &out.Platforms
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.References != nil {
+		in, out := &in.References, &out.References
+		*out = new(ReferenceSelection)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransferSpec.
+func (in *TransferSpec) DeepCopy() *TransferSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TransferSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransferredReference) DeepCopyInto(out *TransferredReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransferredReference.
+func (in *TransferredReference) DeepCopy() *TransferredReference {
+	if in == nil {
+		return nil
+	}
+	out := new(TransferredReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrustPolicy) DeepCopyInto(out *TrustPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrustPolicy.
+func (in *TrustPolicy) DeepCopy() *TrustPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(TrustPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TrustPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrustPolicyList) DeepCopyInto(out *TrustPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TrustPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrustPolicyList.
+func (in *TrustPolicyList) DeepCopy() *TrustPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(TrustPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TrustPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrustPolicySpec) DeepCopyInto(out *TrustPolicySpec) {
+	*out = *in
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Verify != nil {
+		in, out := &in.Verify, &out.Verify
+		*out = make([]apiv1alpha1.Signature, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VerifyCertificates != nil {
+		in, out := &in.VerifyCertificates, &out.VerifyCertificates
+		*out = make([]CertificateSignature, len(*in))
+		copy(*out, *in)
+	}
+	if in.VerificationPolicy != nil {
+		in, out := &in.VerificationPolicy, &out.VerificationPolicy
+		*out = new(VerificationPolicy)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrustPolicySpec.
+func (in *TrustPolicySpec) DeepCopy() *TrustPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TrustPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerificationPolicy) DeepCopyInto(out *VerificationPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerificationPolicy.
+func (in *VerificationPolicy) DeepCopy() *VerificationPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(VerificationPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
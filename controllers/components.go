@@ -0,0 +1,257 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/open-component-model/ocm-controller/pkg/status"
+	ocm2 "github.com/open-component-model/ocm/pkg/contexts/ocm"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/open-component-model/replication-controller/api/v1alpha1"
+)
+
+// invalidNameCharacters matches the characters of component names that aren't allowed in object names.
+var invalidNameCharacters = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// childSubscription describes a subscription owned by another one.
+type childSubscription struct {
+	name    string
+	version string
+	spec    v1alpha1.ComponentSubscriptionSpec
+}
+
+// reconcileComponents creates a subscription owned by obj for every component of the source repository matching
+// the component selector, and deletes the subscriptions of components that no longer match. The state of the
+// subscriptions is recorded in the status.
+func (r *ComponentSubscriptionReconciler) reconcileComponents(ctx context.Context, octx ocm2.Context, obj *v1alpha1.ComponentSubscription) error {
+	components, err := r.OCMClient.ListComponents(ctx, octx, obj)
+	if err != nil {
+		return err
+	}
+
+	children := make([]childSubscription, 0, len(components))
+	for _, component := range components {
+		children = append(children, childSubscription{
+			name: componentSubscriptionName(obj.Name, component),
+			spec: componentSubscriptionSpec(obj, component),
+		})
+	}
+
+	return r.reconcileChildren(ctx, obj, children)
+}
+
+// followReferences creates a subscription owned by obj for every component referenced by the last applied version,
+// pinned to the referenced version, and deletes the subscriptions of components no longer referenced. The references
+// are taken from the replicated component version if given, and from the status otherwise. References excluded from
+// the transfer aren't followed.
+func (r *ComponentSubscriptionReconciler) followReferences(
+	ctx context.Context,
+	obj *v1alpha1.ComponentSubscription,
+	cv ocm2.ComponentVersionAccess,
+) error {
+	var children []childSubscription
+	switch {
+	case !obj.Spec.FollowReferences:
+		// subscriptions of references followed before are deleted.
+		if len(obj.Status.Components) == 0 {
+			return nil
+		}
+	case cv != nil:
+		for _, reference := range cv.GetDescriptor().References {
+			if !obj.Spec.Transfer.SelectsReference(reference.Name, reference.ComponentName) {
+				continue
+			}
+
+			children = append(children, childSubscription{
+				name:    componentSubscriptionName(obj.Name, reference.Name),
+				version: reference.Version,
+				spec:    referenceSubscriptionSpec(obj, reference.ComponentName, reference.Version),
+			})
+		}
+	default:
+		for _, component := range obj.Status.Components {
+			children = append(children, childSubscription{
+				name:    component.Subscription,
+				version: component.Version,
+				spec:    referenceSubscriptionSpec(obj, component.Name, component.Version),
+			})
+		}
+	}
+
+	if err := r.reconcileChildren(ctx, obj, children); err != nil {
+		err := fmt.Errorf("failed to follow component references: %w", err)
+		status.MarkNotReady(r.EventRecorder, obj, v1alpha1.FollowReferencesFailedReason, err.Error())
+
+		return err
+	}
+
+	return nil
+}
+
+// reconcileChildren creates or updates the given subscriptions owned by obj and deletes any other subscription owned
+// by it. The state of the subscriptions is recorded in the status.
+func (r *ComponentSubscriptionReconciler) reconcileChildren(
+	ctx context.Context,
+	obj *v1alpha1.ComponentSubscription,
+	children []childSubscription,
+) error {
+	existing, err := r.componentSubscriptions(ctx, obj)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range existing {
+		if slices.ContainsFunc(children, func(c childSubscription) bool { return c.name == child.Name }) {
+			continue
+		}
+
+		if err := r.Delete(ctx, child); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete subscription %s of component %s: %w", child.Name, child.Spec.Component, err)
+		}
+	}
+
+	subscribed := make([]v1alpha1.SubscribedComponent, 0, len(children))
+	for _, desired := range children {
+		child := &v1alpha1.ComponentSubscription{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      desired.name,
+				Namespace: obj.Namespace,
+			},
+		}
+
+		if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, child, func() error {
+			child.Spec = desired.spec
+
+			return controllerutil.SetControllerReference(obj, child, r.Scheme)
+		}); err != nil {
+			return fmt.Errorf("failed to create or update subscription %s of component %s: %w", child.Name, desired.spec.Component, err)
+		}
+
+		state := subscribedComponent(child)
+		state.Name = desired.spec.Component
+		state.Version = desired.version
+
+		subscribed = append(subscribed, state)
+	}
+
+	obj.Status.Components = subscribed
+
+	return nil
+}
+
+// subscribedComponent returns the state of an owned subscription reported in the status of its owner.
+func subscribedComponent(child *v1alpha1.ComponentSubscription) v1alpha1.SubscribedComponent {
+	state := v1alpha1.SubscribedComponent{
+		Subscription:       child.Name,
+		LastAppliedVersion: child.Status.LastAppliedVersion,
+	}
+	if ready := apimeta.FindStatusCondition(child.Status.Conditions, meta.ReadyCondition); ready != nil {
+		state.Ready = ready.Status == metav1.ConditionTrue
+		state.Message = ready.Message
+	}
+
+	return state
+}
+
+// subscribedComponentChanged filters the events of owned subscriptions to those changing their spec or the state
+// reported in the status of their owner. Other status updates, e.g. while the subscription reconciles, don't
+// reconcile the owner, which would list the components of a selector again.
+var subscribedComponentChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		previous, ok := e.ObjectOld.(*v1alpha1.ComponentSubscription)
+		if !ok {
+			return true
+		}
+
+		current, ok := e.ObjectNew.(*v1alpha1.ComponentSubscription)
+		if !ok {
+			return true
+		}
+
+		return previous.Generation != current.Generation || subscribedComponent(previous) != subscribedComponent(current)
+	},
+}
+
+// componentSubscriptions returns the subscriptions owned by obj.
+func (r *ComponentSubscriptionReconciler) componentSubscriptions(
+	ctx context.Context,
+	obj *v1alpha1.ComponentSubscription,
+) ([]*v1alpha1.ComponentSubscription, error) {
+	list := &v1alpha1.ComponentSubscriptionList{}
+	if err := r.List(ctx, list, client.InNamespace(obj.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+
+	var children []*v1alpha1.ComponentSubscription
+	for i := range list.Items {
+		if metav1.IsControlledBy(&list.Items[i], obj) {
+			children = append(children, &list.Items[i])
+		}
+	}
+
+	return children, nil
+}
+
+// componentSubscriptionSpec returns the spec of the subscription of a component matching the selector of obj. Pull
+// secrets are propagated by obj, as the subscriptions of all components would write the same Secrets.
+func componentSubscriptionSpec(obj *v1alpha1.ComponentSubscription, component string) v1alpha1.ComponentSubscriptionSpec {
+	spec := obj.Spec.DeepCopy()
+	spec.Component = component
+	spec.ComponentSelector = nil
+	spec.PullSecret = nil
+
+	return *spec
+}
+
+// referenceSubscriptionSpec returns the spec of the subscription of a component referenced by the version replicated
+// by obj. It's pinned to the referenced version and must carry the signatures obj requires of the referenced component.
+// Images are mirrored by obj, as it mirrors those of all referenced components.
+func referenceSubscriptionSpec(obj *v1alpha1.ComponentSubscription, component, version string) v1alpha1.ComponentSubscriptionSpec {
+	spec := componentSubscriptionSpec(obj, component)
+	spec.Semver = version
+	spec.MirrorImages = nil
+
+	if obj.Spec.VerifyReferences != nil {
+		for _, signatures := range obj.Spec.VerifyReferences.Components {
+			if ok, _ := path.Match(signatures.Name, component); ok {
+				spec.Verify = signatures.Verify
+				spec.VerifyCertificates = signatures.VerifyCertificates
+				spec.VerificationPolicy = signatures.VerificationPolicy
+
+				break
+			}
+		}
+	}
+
+	return spec
+}
+
+// componentSubscriptionName returns the name of the subscription of a component. It's derived from the name of the
+// parent and the last path element of the component, and a hash of the component keeps it unique.
+func componentSubscriptionName(parent, component string) string {
+	hash := sha256.Sum256([]byte(component))
+	suffix := hex.EncodeToString(hash[:])[:8]
+
+	base := strings.Trim(invalidNameCharacters.ReplaceAllString(strings.ToLower(path.Base(component)), "-"), "-")
+
+	// object names are limited to 253 characters.
+	name := strings.TrimSuffix(fmt.Sprintf("%s-%s", parent, base), "-")
+	if maxLength := 253 - len(suffix) - 1; len(name) > maxLength {
+		name = strings.TrimSuffix(name[:maxLength], "-")
+	}
+
+	return fmt.Sprintf("%s-%s", name, suffix)
+}
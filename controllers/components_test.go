@@ -0,0 +1,383 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	ocmv1alpha1 "github.com/open-component-model/ocm-controller/api/v1alpha1"
+	ocmdesc "github.com/open-component-model/ocm/pkg/contexts/ocm/compdesc"
+	v1 "github.com/open-component-model/ocm/pkg/contexts/ocm/compdesc/meta/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/open-component-model/replication-controller/api/v1alpha1"
+	"github.com/open-component-model/replication-controller/pkg/ocm/fakes"
+)
+
+func TestComponentSubscriptionReconcilerComponentSelector(t *testing.T) {
+	parent := DefaultComponentSubscription.DeepCopy()
+	parent.Spec.Component = ""
+	parent.Spec.ComponentSelector = &v1alpha1.ComponentSelector{Pattern: "github.com/acme/*"}
+	parent.Spec.PullSecret = &v1alpha1.PullSecretPropagation{Name: "pull-secret"}
+
+	child := func(component string) *v1alpha1.ComponentSubscription {
+		child := &v1alpha1.ComponentSubscription{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      componentSubscriptionName(parent.Name, component),
+				Namespace: parent.Namespace,
+			},
+			Spec: componentSubscriptionSpec(parent, component),
+		}
+		require.NoError(t, controllerutil.SetControllerReference(parent, child, env.scheme))
+
+		return child
+	}
+
+	ready := child("github.com/acme/api")
+	ready.Status.LastAppliedVersion = "v1.0.0"
+	conditions.MarkTrue(ready, meta.ReadyCondition, meta.SucceededReason, "Reconciliation success")
+	removed := child("github.com/acme/removed")
+	unrelated := DefaultComponentSubscription.DeepCopy()
+	unrelated.Name = "unrelated"
+	credentials := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "destination-secret", Namespace: parent.Namespace},
+		Type:       corev1.SecretTypeBasicAuth,
+		Data: map[string][]byte{
+			corev1.BasicAuthUsernameKey: []byte("user"),
+			corev1.BasicAuthPasswordKey: []byte("pass"),
+		},
+	}
+
+	testCases := []struct {
+		name       string
+		components []string
+		listErr    error
+		expected   []v1alpha1.SubscribedComponent
+		err        string
+	}{
+		{
+			name:       "subscriptions are created for matching components",
+			components: []string{"github.com/acme/api", "github.com/acme/ui"},
+			expected: []v1alpha1.SubscribedComponent{
+				{
+					Name:               "github.com/acme/api",
+					Subscription:       componentSubscriptionName(parent.Name, "github.com/acme/api"),
+					LastAppliedVersion: "v1.0.0",
+					Ready:              true,
+					Message:            "Reconciliation success",
+				},
+				{
+					Name:         "github.com/acme/ui",
+					Subscription: componentSubscriptionName(parent.Name, "github.com/acme/ui"),
+				},
+			},
+		},
+		{
+			name:    "listing the components fails",
+			listErr: errors.New("catalog not supported"),
+			err:     "failed to subscribe to components: catalog not supported",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := parent.DeepCopy()
+			kubeClient := env.FakeKubeClient(WithObjets(obj, ready.DeepCopy(), removed.DeepCopy(), unrelated.DeepCopy(), credentials))
+			fakeOcm := &fakes.MockFetcher{}
+			fakeOcm.ListComponentsReturns(tt.components, tt.listErr)
+
+			r := ComponentSubscriptionReconciler{
+				Scheme:        env.scheme,
+				Client:        kubeClient,
+				OCMClient:     fakeOcm,
+				EventRecorder: record.NewFakeRecorder(32),
+			}
+
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, fakeOcm.GetLatestComponentVersionWasNotCalled(), "the parent must not replicate itself")
+
+			require.NoError(t, kubeClient.Get(context.Background(), client.ObjectKeyFromObject(obj), obj))
+			assert.Equal(t, tt.expected, obj.Status.Components)
+			assert.True(t, conditions.IsTrue(obj, meta.ReadyCondition))
+
+			for _, expected := range tt.expected {
+				subscription := &v1alpha1.ComponentSubscription{}
+				require.NoError(t, kubeClient.Get(context.Background(), client.ObjectKeyFromObject(&v1alpha1.ComponentSubscription{
+					ObjectMeta: metav1.ObjectMeta{Name: expected.Subscription, Namespace: obj.Namespace},
+				}), subscription))
+				assert.Equal(t, expected.Name, subscription.Spec.Component)
+				assert.Nil(t, subscription.Spec.ComponentSelector)
+				assert.Nil(t, subscription.Spec.PullSecret, "pull secrets are propagated by the parent")
+				assert.True(t, metav1.IsControlledBy(subscription, obj))
+			}
+
+			err = kubeClient.Get(context.Background(), client.ObjectKeyFromObject(removed), &v1alpha1.ComponentSubscription{})
+			assert.True(t, apierrors.IsNotFound(err), "subscriptions of components no longer matching must be deleted")
+			require.NoError(t, kubeClient.Get(context.Background(), client.ObjectKeyFromObject(unrelated), &v1alpha1.ComponentSubscription{}))
+		})
+	}
+}
+
+func TestComponentSubscriptionReconcilerRequiresComponent(t *testing.T) {
+	for name, spec := range map[string]func(obj *v1alpha1.ComponentSubscription){
+		"neither component nor selector": func(obj *v1alpha1.ComponentSubscription) {
+			obj.Spec.Component = ""
+		},
+		"component and selector": func(obj *v1alpha1.ComponentSubscription) {
+			obj.Spec.ComponentSelector = &v1alpha1.ComponentSelector{Pattern: "*"}
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			obj := DefaultComponentSubscription.DeepCopy()
+			spec(obj)
+			kubeClient := env.FakeKubeClient(WithObjets(obj))
+			fakeOcm := &fakes.MockFetcher{}
+
+			r := ComponentSubscriptionReconciler{
+				Scheme:        env.scheme,
+				Client:        kubeClient,
+				OCMClient:     fakeOcm,
+				EventRecorder: record.NewFakeRecorder(32),
+			}
+
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+			require.NoError(t, err)
+
+			require.NoError(t, kubeClient.Get(context.Background(), client.ObjectKeyFromObject(obj), obj))
+			assert.True(t, conditions.IsStalled(obj))
+			assert.True(t, fakeOcm.ListComponentsWasNotCalled())
+		})
+	}
+}
+
+func TestComponentSubscriptionName(t *testing.T) {
+	name := componentSubscriptionName("platform", "github.com/acme/Platform_API")
+	assert.Regexp(t, `^platform-platform-api-[0-9a-f]{8}$`, name)
+	assert.NotEqual(t, name, componentSubscriptionName("platform", "github.com/other/platform-api"))
+
+	long := componentSubscriptionName(strings.Repeat("a", 253), "github.com/acme/api")
+	assert.Len(t, long, 253)
+}
+
+func TestComponentSubscriptionReconcilerFollowReferences(t *testing.T) {
+	parent := DefaultComponentSubscription.DeepCopy()
+	parent.Spec.Semver = ""
+	parent.Spec.FollowReferences = true
+	parent.Spec.MirrorImages = &v1alpha1.ImageMirror{Destination: v1alpha1.OCMRepository{URL: "registry.internal/images"}}
+	parent.Spec.VerifyReferences = &v1alpha1.ReferenceVerification{
+		Components: []v1alpha1.ComponentSignatures{
+			{
+				Name: "github.com/acme/db",
+				Verify: []ocmv1alpha1.Signature{
+					{Name: "db-team", PublicKey: ocmv1alpha1.PublicKey{Value: "key"}},
+				},
+			},
+		},
+	}
+
+	root := &mockComponent{
+		t: t,
+		descriptor: &ocmdesc.ComponentDescriptor{
+			ComponentSpec: ocmdesc.ComponentSpec{
+				ObjectMeta: v1.ObjectMeta{
+					Name:    parent.Spec.Component,
+					Version: "v0.0.2",
+				},
+				References: ocmdesc.References{
+					{
+						ElementMeta:   ocmdesc.ElementMeta{Name: "backend", Version: "v1.2.0"},
+						ComponentName: "github.com/acme/backend",
+					},
+					{
+						ElementMeta:   ocmdesc.ElementMeta{Name: "db", Version: "v2.0.0"},
+						ComponentName: "github.com/acme/db",
+					},
+				},
+			},
+		},
+	}
+
+	child := func(reference, component, version string) *v1alpha1.ComponentSubscription {
+		child := &v1alpha1.ComponentSubscription{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      componentSubscriptionName(parent.Name, reference),
+				Namespace: parent.Namespace,
+			},
+			Spec: referenceSubscriptionSpec(parent, component, version),
+		}
+		require.NoError(t, controllerutil.SetControllerReference(parent, child, env.scheme))
+
+		return child
+	}
+
+	backend := child("backend", "github.com/acme/backend", "v1.1.0")
+	backend.Status.LastAppliedVersion = "v1.1.0"
+	conditions.MarkTrue(backend, meta.ReadyCondition, meta.SucceededReason, "Reconciliation success")
+	removed := child("cache", "github.com/acme/cache", "v1.0.0")
+
+	testCases := []struct {
+		name        string
+		lastApplied string
+		components  []v1alpha1.SubscribedComponent
+		references  *v1alpha1.ReferenceSelection
+		expected    []v1alpha1.SubscribedComponent
+	}{
+		{
+			name:        "the references of a replicated version are followed",
+			lastApplied: "v0.0.1",
+			expected: []v1alpha1.SubscribedComponent{
+				{
+					Name:               "github.com/acme/backend",
+					Version:            "v1.2.0",
+					Subscription:       componentSubscriptionName(parent.Name, "backend"),
+					LastAppliedVersion: "v1.1.0",
+					Ready:              true,
+					Message:            "Reconciliation success",
+				},
+				{
+					Name:         "github.com/acme/db",
+					Version:      "v2.0.0",
+					Subscription: componentSubscriptionName(parent.Name, "db"),
+				},
+			},
+		},
+		{
+			name:        "the references of the last applied version are kept in sync",
+			lastApplied: "v0.0.2",
+			components: []v1alpha1.SubscribedComponent{
+				{Name: "github.com/acme/db", Version: "v2.0.0", Subscription: componentSubscriptionName(parent.Name, "db")},
+			},
+			expected: []v1alpha1.SubscribedComponent{
+				{Name: "github.com/acme/db", Version: "v2.0.0", Subscription: componentSubscriptionName(parent.Name, "db")},
+			},
+		},
+		{
+			name:        "excluded references aren't followed",
+			lastApplied: "v0.0.1",
+			references: &v1alpha1.ReferenceSelection{
+				Exclude: []v1alpha1.ReferenceRule{{Name: "backend"}},
+			},
+			expected: []v1alpha1.SubscribedComponent{
+				{Name: "github.com/acme/db", Version: "v2.0.0", Subscription: componentSubscriptionName(parent.Name, "db")},
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := parent.DeepCopy()
+			obj.Status.LastAppliedVersion = tt.lastApplied
+			obj.Status.Components = tt.components
+			if tt.references != nil {
+				obj.Spec.Transfer = &v1alpha1.TransferSpec{References: tt.references}
+			}
+			kubeClient := env.FakeKubeClient(WithObjets(obj, backend.DeepCopy(), removed.DeepCopy()))
+			fakeOcm := &fakes.MockFetcher{}
+			fakeOcm.GetLatestComponentVersionReturns("v0.0.2", nil)
+			fakeOcm.GetComponentVersionReturnsForName(root.descriptor.ComponentSpec.Name, root, nil)
+
+			r := ComponentSubscriptionReconciler{
+				Scheme:        env.scheme,
+				Client:        kubeClient,
+				OCMClient:     fakeOcm,
+				EventRecorder: record.NewFakeRecorder(32),
+			}
+
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+			require.NoError(t, err)
+
+			require.NoError(t, kubeClient.Get(context.Background(), client.ObjectKeyFromObject(obj), obj))
+			assert.Equal(t, tt.expected, obj.Status.Components)
+			assert.Equal(t, "v0.0.2", obj.Status.LastAppliedVersion)
+			assert.True(t, conditions.IsTrue(obj, meta.ReadyCondition))
+
+			for _, expected := range tt.expected {
+				subscription := &v1alpha1.ComponentSubscription{}
+				require.NoError(t, kubeClient.Get(context.Background(), client.ObjectKeyFromObject(&v1alpha1.ComponentSubscription{
+					ObjectMeta: metav1.ObjectMeta{Name: expected.Subscription, Namespace: obj.Namespace},
+				}), subscription))
+				assert.Equal(t, expected.Name, subscription.Spec.Component)
+				assert.Equal(t, expected.Version, subscription.Spec.Semver, "subscriptions are pinned to the referenced version")
+				assert.True(t, subscription.Spec.FollowReferences)
+				assert.Nil(t, subscription.Spec.MirrorImages, "images are mirrored by the parent")
+				assert.True(t, metav1.IsControlledBy(subscription, obj))
+
+				if expected.Name == "github.com/acme/db" {
+					assert.Equal(t, obj.Spec.VerifyReferences.Components[0].Verify, subscription.Spec.Verify)
+				} else {
+					assert.Equal(t, obj.Spec.Verify, subscription.Spec.Verify)
+				}
+			}
+
+			err = kubeClient.Get(context.Background(), client.ObjectKeyFromObject(removed), &v1alpha1.ComponentSubscription{})
+			assert.True(t, apierrors.IsNotFound(err), "subscriptions of components no longer referenced must be deleted")
+		})
+	}
+}
+
+func TestSubscribedComponentChanged(t *testing.T) {
+	child := DefaultComponentSubscription.DeepCopy()
+	child.Generation = 1
+	conditions.MarkTrue(child, meta.ReadyCondition, meta.SucceededReason, "Reconciliation success")
+
+	testCases := []struct {
+		name     string
+		update   func(*v1alpha1.ComponentSubscription)
+		expected bool
+	}{
+		{
+			name: "reconciling",
+			update: func(obj *v1alpha1.ComponentSubscription) {
+				conditions.MarkReconciling(obj, meta.ProgressingReason, "reconciliation in progress")
+			},
+		},
+		{
+			name: "not ready",
+			update: func(obj *v1alpha1.ComponentSubscription) {
+				conditions.MarkFalse(obj, meta.ReadyCondition, meta.FailedReason, "failed")
+			},
+			expected: true,
+		},
+		{
+			name: "new version",
+			update: func(obj *v1alpha1.ComponentSubscription) {
+				obj.Status.LastAppliedVersion = "v0.0.2"
+			},
+			expected: true,
+		},
+		{
+			name: "spec",
+			update: func(obj *v1alpha1.ComponentSubscription) {
+				obj.Generation++
+			},
+			expected: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := child.DeepCopy()
+			tt.update(updated)
+
+			assert.Equal(t, tt.expected, subscribedComponentChanged.Update(event.UpdateEvent{ObjectOld: child, ObjectNew: updated}))
+		})
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
@@ -45,86 +47,295 @@ type ComponentSubscriptionReconciler struct {
 	MpasEnabled   bool
 }
 
-// SetupWithManager sets up the controller with the Manager.
-func (r *ComponentSubscriptionReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	const (
-		sourceKey      = ".metadata.source.secretRef"
-		destinationKey = ".metadata.destination.secretRef"
-	)
+// Field indexes of the secrets and service accounts referenced by subscriptions in the form `namespace/name`.
+const (
+	sourceKey         = ".metadata.source.secretRef"
+	destinationKey    = ".metadata.destination.secretRef"
+	fallbackKey       = ".metadata.fallback.secretRef"
+	pullSecretKey     = ".metadata.pullSecret.secretRef"
+	verifyKey         = ".metadata.verify.secretRef"
+	serviceAccountKey = ".metadata.serviceAccountName"
+	ocmConfigKey      = ".metadata.ocmConfigRef.secret"
+	ocmConfigMapKey   = ".metadata.ocmConfigRef.configMap"
+	certKey           = ".metadata.certSecretRef"
+	proxyKey          = ".metadata.proxy.secretRef"
+)
 
-	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &v1alpha1.ComponentSubscription{}, sourceKey, func(rawObj client.Object) []string {
-		obj, ok := rawObj.(*v1alpha1.ComponentSubscription)
-		if !ok {
+// indexes maps the field indexes to the functions extracting their values.
+var indexes = map[string]func(obj *v1alpha1.ComponentSubscription) []string{
+	sourceKey: func(obj *v1alpha1.ComponentSubscription) []string {
+		if obj.Spec.Source.SecretRef == nil {
 			return []string{}
 		}
-		if obj.Spec.Source.SecretRef == nil {
+
+		return []string{secretReferenceName(obj.Spec.Source.SecretRef)}
+	},
+	destinationKey: func(obj *v1alpha1.ComponentSubscription) []string {
+		if obj.Spec.Destination == nil || obj.Spec.Destination.SecretRef == nil {
 			return []string{}
 		}
 
-		ns := obj.GetNamespace()
+		return []string{secretReferenceName(obj.Spec.Destination.SecretRef)}
+	},
+	fallbackKey: func(obj *v1alpha1.ComponentSubscription) []string {
+		fallback := obj.Spec.Transfer.GetFallback()
+		if fallback == nil || fallback.SecretRef == nil {
+			return []string{}
+		}
 
-		return []string{fmt.Sprintf("%s/%s", ns, obj.Spec.Source.SecretRef.Name)}
-	}); err != nil {
-		return fmt.Errorf("failed setting index fields: %w", err)
+		return []string{secretReferenceName(fallback.SecretRef)}
+	},
+	pullSecretKey: func(obj *v1alpha1.ComponentSubscription) []string {
+		if obj.Spec.PullSecret == nil || obj.Spec.PullSecret.SecretRef == nil {
+			return []string{}
+		}
+
+		return []string{obj.Spec.PullSecret.SecretRef.Name}
+	},
+	verifyKey: verifySecretNames,
+	certKey: func(obj *v1alpha1.ComponentSubscription) []string {
+		var names []string
+		for _, repository := range ocm.RegistryRepositories(obj) {
+			if repository.CertSecretRef == nil {
+				continue
+			}
+
+			if name := secretReferenceName(repository.CertSecretRef); !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+
+		return names
+	},
+	proxyKey: func(obj *v1alpha1.ComponentSubscription) []string {
+		var names []string
+		for _, repository := range ocm.RegistryRepositories(obj) {
+			if repository.Proxy != nil && repository.Proxy.SecretRef != nil && !slices.Contains(names, repository.Proxy.SecretRef.Name) {
+				names = append(names, repository.Proxy.SecretRef.Name)
+			}
+		}
+
+		return names
+	},
+	ocmConfigKey: func(obj *v1alpha1.ComponentSubscription) []string {
+		if obj.Spec.OCMConfigRef == nil || obj.Spec.OCMConfigRef.Kind == ocm.ConfigMapKind {
+			return []string{}
+		}
+
+		return []string{obj.Spec.OCMConfigRef.Name}
+	},
+	ocmConfigMapKey: func(obj *v1alpha1.ComponentSubscription) []string {
+		if obj.Spec.OCMConfigRef == nil || obj.Spec.OCMConfigRef.Kind != ocm.ConfigMapKind {
+			return []string{}
+		}
+
+		return []string{obj.Spec.OCMConfigRef.Name}
+	},
+	serviceAccountKey: func(obj *v1alpha1.ComponentSubscription) []string {
+		if obj.Spec.ServiceAccountName == "" {
+			return []string{}
+		}
+
+		return []string{obj.Spec.ServiceAccountName}
+	},
+}
+
+// verifySecretNames returns the names of all Secrets holding public keys or root certificates used to verify
+// the component, its references and its images.
+func verifySecretNames(obj *v1alpha1.ComponentSubscription) []string {
+	var names []string
+	add := func(signatures []ocmv1alpha1.Signature, certificates []v1alpha1.CertificateSignature) {
+		for _, signature := range signatures {
+			if signature.PublicKey.SecretRef != nil {
+				names = append(names, signature.PublicKey.SecretRef.Name)
+			}
+		}
+
+		for _, certificate := range certificates {
+			names = append(names, certificate.RootCertificatesSecretRef.Name)
+		}
+	}
+
+	add(obj.Spec.Verify, obj.Spec.VerifyCertificates)
+	if obj.Spec.VerifyReferences != nil {
+		for _, component := range obj.Spec.VerifyReferences.Components {
+			add(component.Verify, component.VerifyCertificates)
+		}
+	}
+
+	if obj.Spec.VerifySecretNamespace != "" {
+		for i, name := range names {
+			names[i] = fmt.Sprintf("%s/%s", obj.Spec.VerifySecretNamespace, name)
+		}
 	}
 
-	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &v1alpha1.ComponentSubscription{}, destinationKey, func(rawObj client.Object) []string {
+	// trust stores are always read from the namespace of the subscription
+	if obj.Spec.VerifyImages != nil {
+		for _, store := range obj.Spec.VerifyImages.TrustStores {
+			names = append(names, store.Name)
+		}
+	}
+
+	slices.Sort(names)
+
+	return slices.Compact(names)
+}
+
+// secretReferenceName returns the name of a referenced Secret, qualified with its namespace if it has one.
+func secretReferenceName(ref *corev1.SecretReference) string {
+	if ref.Namespace == "" {
+		return ref.Name
+	}
+
+	return fmt.Sprintf("%s/%s", ref.Namespace, ref.Name)
+}
+
+// indexField returns an indexer function for the given field index. Values are prefixed with the namespace of the
+// subscription, unless they reference another namespace already.
+func indexField(key string) client.IndexerFunc {
+	return func(rawObj client.Object) []string {
 		obj, ok := rawObj.(*v1alpha1.ComponentSubscription)
 		if !ok {
 			return []string{}
 		}
-		if obj.Spec.Destination == nil || obj.Spec.Destination.SecretRef == nil {
-			return []string{}
+
+		names := indexes[key](obj)
+		values := make([]string, 0, len(names))
+		for _, name := range names {
+			if !strings.Contains(name, "/") {
+				name = fmt.Sprintf("%s/%s", obj.GetNamespace(), name)
+			}
+
+			values = append(values, name)
+		}
+
+		return values
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ComponentSubscriptionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	for key := range indexes {
+		if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &v1alpha1.ComponentSubscription{}, key, indexField(key)); err != nil {
+			return fmt.Errorf("failed setting index fields: %w", err)
 		}
+	}
 
-		ns := obj.GetNamespace()
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &v1alpha1.ComponentSubscription{}, trustPolicyKey, indexTrustPolicies); err != nil {
+		return fmt.Errorf("failed setting index fields: %w", err)
+	}
 
-		return []string{fmt.Sprintf("%s/%s", ns, obj.Spec.Destination.SecretRef.Name)}
-	}); err != nil {
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &v1alpha1.TrustPolicy{}, trustPolicySecretKey, indexTrustPolicySecrets); err != nil {
 		return fmt.Errorf("failed setting index fields: %w", err)
 	}
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.ComponentSubscription{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&v1alpha1.ComponentSubscription{}, builder.WithPredicates(subscribedComponentChanged)).
 		Watches(
 			&source.Kind{Type: &corev1.Secret{}},
-			handler.EnqueueRequestsFromMapFunc(r.findObjects(sourceKey, destinationKey))).
+			handler.EnqueueRequestsFromMapFunc(r.findSecretObjects)).
+		Watches(
+			&source.Kind{Type: &corev1.ServiceAccount{}},
+			handler.EnqueueRequestsFromMapFunc(r.findObjects(serviceAccountKey))).
+		Watches(
+			&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(r.findObjects(ocmConfigMapKey))).
+		Watches(
+			&source.Kind{Type: &v1alpha1.SecretReferenceGrant{}},
+			handler.EnqueueRequestsFromMapFunc(r.findGrantObjects)).
+		Watches(
+			&source.Kind{Type: &v1alpha1.TrustPolicy{}},
+			handler.EnqueueRequestsFromMapFunc(r.findTrustPolicyObjects)).
 		Complete(r)
 }
 
-// findObjects finds component versions that have a key for the secret that triggered this watch event.
-func (r *ComponentSubscriptionReconciler) findObjects(sourceKey string, destinationKey string) handler.MapFunc {
-	return func(obj client.Object) []reconcile.Request {
-		sourceList := &v1alpha1.ComponentSubscriptionList{}
-		if err := r.List(context.Background(), sourceList, &client.ListOptions{
-			FieldSelector: fields.OneTermEqualSelector(sourceKey, client.ObjectKeyFromObject(obj).String()),
-		}); err != nil {
-			return []reconcile.Request{}
+// findGrantObjects finds component versions of other namespaces that reference secrets of, or propagate pull
+// secrets to, the namespace of the grant that triggered this watch event, so granted or revoked access takes effect.
+func (r *ComponentSubscriptionReconciler) findGrantObjects(obj client.Object) []reconcile.Request {
+	list := &v1alpha1.ComponentSubscriptionList{}
+	if err := r.List(context.Background(), list); err != nil {
+		return []reconcile.Request{}
+	}
+
+	requests := []reconcile.Request{}
+	for i := range list.Items {
+		item := &list.Items[i]
+		if item.Namespace == obj.GetNamespace() {
+			continue
+		}
+
+		if item.Spec.PullSecret != nil && slices.Contains(item.Spec.PullSecret.Namespaces, obj.GetNamespace()) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(item)})
+
+			continue
 		}
 
-		destinationList := &v1alpha1.ComponentSubscriptionList{}
-		if err := r.List(context.Background(), destinationList, &client.ListOptions{
-			FieldSelector: fields.OneTermEqualSelector(destinationKey, client.ObjectKeyFromObject(obj).String()),
-		}); err != nil {
-			return []reconcile.Request{}
+		for _, key := range []string{sourceKey, destinationKey, fallbackKey, verifyKey, certKey} {
+			if slices.ContainsFunc(indexField(key)(item), func(value string) bool {
+				return strings.HasPrefix(value, obj.GetNamespace()+"/")
+			}) {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(item)})
+
+				break
+			}
+		}
+	}
+
+	return requests
+}
+
+// findSecretObjects finds component versions that reference the secret that triggered this watch event, either
+// directly, as image pull secret of their service account or through a TrustPolicy applying to them.
+func (r *ComponentSubscriptionReconciler) findSecretObjects(obj client.Object) []reconcile.Request {
+	requests := r.findObjects(sourceKey, destinationKey, fallbackKey, pullSecretKey, verifyKey, ocmConfigKey, certKey, proxyKey)(obj)
+	requests = append(requests, r.findTrustPolicySecretObjects(obj)...)
+
+	accounts := &corev1.ServiceAccountList{}
+	if err := r.List(context.Background(), accounts, client.InNamespace(obj.GetNamespace())); err != nil {
+		return requests
+	}
+
+	for i := range accounts.Items {
+		account := &accounts.Items[i]
+		if slices.Contains(account.ImagePullSecrets, corev1.LocalObjectReference{Name: obj.GetName()}) {
+			requests = append(requests, r.findObjects(serviceAccountKey)(account)...)
 		}
+	}
 
-		// deduplicate the two secret lists
+	return requests
+}
+
+// findObjects finds component versions that have a key for the secret that triggered this watch event. Propagated
+// pull secrets map to the subscription managing them, so they are restored if modified.
+func (r *ComponentSubscriptionReconciler) findObjects(keys ...string) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		// deduplicate the secret lists
 		requestMap := make(map[reconcile.Request]struct{})
-		for _, item := range sourceList.Items {
-			requestMap[reconcile.Request{
-				NamespacedName: types.NamespacedName{
-					Name:      item.GetName(),
-					Namespace: item.GetNamespace(),
-				},
-			}] = struct{}{}
+		for _, key := range keys {
+			list := &v1alpha1.ComponentSubscriptionList{}
+			if err := r.List(context.Background(), list, &client.ListOptions{
+				FieldSelector: fields.OneTermEqualSelector(key, client.ObjectKeyFromObject(obj).String()),
+			}); err != nil {
+				return []reconcile.Request{}
+			}
+
+			for _, item := range list.Items {
+				requestMap[reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name:      item.GetName(),
+						Namespace: item.GetNamespace(),
+					},
+				}] = struct{}{}
+			}
 		}
 
-		for _, item := range destinationList.Items {
+		if owner, ok := obj.GetAnnotations()[pullSecretAnnotation]; ok {
+			namespace, name, _ := strings.Cut(owner, "/")
 			requestMap[reconcile.Request{
 				NamespacedName: types.NamespacedName{
-					Name:      item.GetName(),
-					Namespace: item.GetNamespace(),
+					Name:      name,
+					Namespace: namespace,
 				},
 			}] = struct{}{}
 		}
@@ -141,8 +352,11 @@ func (r *ComponentSubscriptionReconciler) findObjects(sourceKey string, destinat
 //+kubebuilder:rbac:groups=delivery.ocm.software,resources=componentsubscriptions,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=delivery.ocm.software,resources=componentsubscriptions/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=delivery.ocm.software,resources=componentsubscriptions/finalizers,verbs=update
+//+kubebuilder:rbac:groups=delivery.ocm.software,resources=trustpolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups=delivery.ocm.software,resources=secretreferencegrants,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -158,7 +372,7 @@ func (r *ComponentSubscriptionReconciler) Reconcile(ctx context.Context, req ctr
 	}
 
 	if obj.DeletionTimestamp != nil {
-		return ctrl.Result{}, nil
+		return ctrl.Result{}, r.reconcileDelete(ctx, obj)
 	}
 
 	// The replication controller doesn't need a shouldReconcile, because it should always reconcile,
@@ -197,6 +411,14 @@ func (r *ComponentSubscriptionReconciler) reconcile(ctx context.Context, obj *v1
 		)
 	}
 
+	// Mirrors may omit both to mirror every component of the source repository.
+	if obj.Spec.Component != "" && obj.Spec.ComponentSelector != nil ||
+		obj.Spec.Component == "" && obj.Spec.ComponentSelector == nil && obj.Spec.Mirror == nil {
+		status.MarkAsStalled(r.EventRecorder, obj, v1alpha1.ComponentDiscoveryFailedReason, "either component or componentSelector must be set")
+
+		return ctrl.Result{}, nil
+	}
+
 	octx, err := r.OCMClient.CreateAuthenticatedOCMContext(ctx, obj)
 	if err != nil {
 		err := fmt.Errorf("failed to authenticate OCM context: %w", err)
@@ -205,6 +427,33 @@ func (r *ComponentSubscriptionReconciler) reconcile(ctx context.Context, obj *v1
 		return ctrl.Result{}, nil
 	}
 
+	// Pull secrets are kept in sync independent of new component versions, e.g. if the credentials are rotated.
+	if err := r.reconcilePullSecrets(ctx, obj); err != nil {
+		err := fmt.Errorf("failed to propagate pull secrets: %w", err)
+		status.MarkNotReady(r.EventRecorder, obj, v1alpha1.PullSecretFailedReason, err.Error())
+
+		return ctrl.Result{}, err
+	}
+
+	// Mirrors replicate every version of the subscribed components themselves.
+	if obj.Spec.Mirror != nil {
+		return r.reconcileMirror(ctx, octx, obj)
+	}
+
+	// Components matching the selector are replicated by subscriptions of their own.
+	if obj.Spec.ComponentSelector != nil {
+		if err := r.reconcileComponents(ctx, octx, obj); err != nil {
+			err := fmt.Errorf("failed to subscribe to components: %w", err)
+			status.MarkNotReady(r.EventRecorder, obj, v1alpha1.ComponentDiscoveryFailedReason, err.Error())
+
+			return ctrl.Result{}, err
+		}
+
+		status.MarkReady(r.EventRecorder, obj, "Subscribed to %d components", len(obj.Status.Components))
+
+		return ctrl.Result{RequeueAfter: obj.GetRequeueAfter()}, nil
+	}
+
 	version, err := r.OCMClient.GetLatestSourceComponentVersion(ctx, octx, obj)
 	if err != nil {
 		err := fmt.Errorf("failed to get latest component version: %w", err)
@@ -216,6 +465,10 @@ func (r *ComponentSubscriptionReconciler) reconcile(ctx context.Context, obj *v1
 
 	// Because of the predicate, this subscription will be reconciled again once there is an update to its status field.
 	if version == obj.Status.LastAppliedVersion {
+		if err := r.followReferences(ctx, obj, nil); err != nil {
+			return ctrl.Result{}, err
+		}
+
 		status.MarkReady(r.EventRecorder, obj, "Reconciliation success")
 
 		return ctrl.Result{RequeueAfter: obj.GetRequeueAfter()}, nil
@@ -243,6 +496,10 @@ func (r *ComponentSubscriptionReconciler) reconcile(ctx context.Context, obj *v1
 	}
 
 	if latestSourceComponentVersion.LessThan(lastAppliedVersion) || latestSourceComponentVersion.Equal(lastAppliedVersion) {
+		if err := r.followReferences(ctx, obj, nil); err != nil {
+			return ctrl.Result{}, err
+		}
+
 		status.MarkReady(r.EventRecorder, obj, "Reconciliation success")
 
 		return ctrl.Result{RequeueAfter: obj.GetRequeueAfter()}, nil
@@ -266,7 +523,7 @@ func (r *ComponentSubscriptionReconciler) reconcile(ctx context.Context, obj *v1
 	}()
 
 	if r.MpasEnabled {
-		if err := r.signMpasComponent(ctx, obj, sourceComponentVersion); err != nil {
+		if err := r.checkMpasComponent(obj, sourceComponentVersion); err != nil {
 			status.MarkNotReady(r.EventRecorder, obj, v1alpha1.ComponentSigningFailedReason, err.Error())
 
 			return ctrl.Result{}, fmt.Errorf("failed to sign mpas component: %w", err)
@@ -276,6 +533,7 @@ func (r *ComponentSubscriptionReconciler) reconcile(ctx context.Context, obj *v1
 	if obj.Spec.Destination != nil {
 		rreconcile.ProgressiveStatus(false, obj, meta.ProgressingReason, "transferring component to target repository: %s", obj.Spec.Destination.URL)
 
+		startedOn := time.Now()
 		if err := r.OCMClient.TransferComponent(ctx, octx, obj, sourceComponentVersion); err != nil {
 			err := fmt.Errorf("failed to transfer components: %w", err)
 			status.MarkNotReady(r.EventRecorder, obj, v1alpha1.TransferFailedReason, err.Error())
@@ -283,11 +541,35 @@ func (r *ComponentSubscriptionReconciler) reconcile(ctx context.Context, obj *v1
 			return ctrl.Result{}, err
 		}
 
+		// Provenance labels and the replication signature are added to the destination copy only, so the source is
+		// never modified.
+		if r.MpasEnabled || obj.Spec.Provenance || obj.Spec.Attestation {
+			if err := r.updateDestinationComponent(ctx, octx, obj, obj, sourceComponentVersion, latestSourceComponentVersion.Original(), startedOn); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
 		obj.Status.ReplicatedRepositoryURL = obj.Spec.Destination.URL
 	} else {
 		obj.Status.ReplicatedRepositoryURL = obj.Spec.Source.URL
 	}
 
+	if obj.Spec.MirrorImages != nil {
+		rreconcile.ProgressiveStatus(false, obj, meta.ProgressingReason, "mirroring images to: %s", obj.Spec.MirrorImages.Destination.URL)
+
+		if err := r.OCMClient.MirrorImages(ctx, octx, obj, sourceComponentVersion); err != nil {
+			err := fmt.Errorf("failed to mirror images: %w", err)
+			status.MarkNotReady(r.EventRecorder, obj, v1alpha1.MirrorImagesFailedReason, err.Error())
+
+			return ctrl.Result{}, err
+		}
+	}
+
+	// The referenced components are replicated by subscriptions of their own if the references are followed.
+	if err := r.followReferences(ctx, obj, sourceComponentVersion); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Update the replicated version to the latest version
 	obj.Status.LastAppliedVersion = latestSourceComponentVersion.Original()
 
@@ -297,8 +579,7 @@ func (r *ComponentSubscriptionReconciler) reconcile(ctx context.Context, obj *v1
 	return ctrl.Result{RequeueAfter: obj.GetRequeueAfter()}, nil
 }
 
-func (r *ComponentSubscriptionReconciler) signMpasComponent(
-	ctx context.Context,
+func (r *ComponentSubscriptionReconciler) checkMpasComponent(
 	obj *v1alpha1.ComponentSubscription,
 	sourceComponentVersion ocm2.ComponentVersionAccess,
 ) error {
@@ -310,7 +591,103 @@ func (r *ComponentSubscriptionReconciler) signMpasComponent(
 		return fmt.Errorf("failed to verify component validity: %w", err)
 	}
 
-	pub, err := r.OCMClient.SignDestinationComponent(ctx, sourceComponentVersion)
+	return nil
+}
+
+// updateDestinationComponent adds the provenance labels to and signs the replicated component in the destination
+// repository. Closing the component version persists both in the destination repository. The attestation is only
+// pushed once that succeeded, so it covers the final digest of the persisted destination component. The component
+// version was replicated with subscription, which differs from obj for the components of a mirror. Results are
+// recorded in the status of obj.
+func (r *ComponentSubscriptionReconciler) updateDestinationComponent(
+	ctx context.Context,
+	octx ocm2.Context,
+	obj, subscription *v1alpha1.ComponentSubscription,
+	sourceComponentVersion ocm2.ComponentVersionAccess,
+	version string,
+	startedOn time.Time,
+) error {
+	destinationComponentVersion, err := r.OCMClient.GetDestinationComponentVersion(ctx, octx, subscription, version)
+	if err != nil {
+		err := fmt.Errorf("failed to get destination component version: %w", err)
+		status.MarkNotReady(r.EventRecorder, obj, v1alpha1.GetComponentDescriptorFailedReason, err.Error())
+
+		return err
+	}
+
+	if subscription.Spec.Provenance {
+		if err := r.OCMClient.AddProvenance(ctx, subscription, sourceComponentVersion, destinationComponentVersion); err != nil {
+			_ = destinationComponentVersion.Close()
+
+			err := fmt.Errorf("failed to add provenance labels: %w", err)
+			status.MarkNotReady(r.EventRecorder, obj, v1alpha1.ProvenanceFailedReason, err.Error())
+
+			return err
+		}
+	}
+
+	if r.MpasEnabled {
+		if err := r.signMpasComponent(ctx, obj, subscription, destinationComponentVersion); err != nil {
+			_ = destinationComponentVersion.Close()
+
+			status.MarkNotReady(r.EventRecorder, obj, v1alpha1.ComponentSigningFailedReason, err.Error())
+
+			return fmt.Errorf("failed to sign mpas component: %w", err)
+		}
+	}
+
+	if err := destinationComponentVersion.Close(); err != nil {
+		err := fmt.Errorf("failed to update component in destination repository: %w", err)
+		status.MarkNotReady(r.EventRecorder, obj, v1alpha1.TransferFailedReason, err.Error())
+
+		return err
+	}
+
+	// the provenance labels aren't covered by any signature, the attestation is the signed record of the provenance.
+	if subscription.Spec.Attestation || subscription.Spec.Provenance {
+		if err := r.attestReplication(ctx, octx, obj, subscription, sourceComponentVersion, version, startedOn); err != nil {
+			err := fmt.Errorf("failed to attest replication: %w", err)
+			status.MarkNotReady(r.EventRecorder, obj, v1alpha1.AttestationFailedReason, err.Error())
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// attestReplication attests the replication of the component version persisted in the destination repository. The
+// component version is looked up again, so the attestation covers what was actually stored.
+func (r *ComponentSubscriptionReconciler) attestReplication(
+	ctx context.Context,
+	octx ocm2.Context,
+	obj, subscription *v1alpha1.ComponentSubscription,
+	sourceComponentVersion ocm2.ComponentVersionAccess,
+	version string,
+	startedOn time.Time,
+) error {
+	destinationComponentVersion, err := r.OCMClient.GetDestinationComponentVersion(ctx, octx, subscription, version)
+	if err != nil {
+		return fmt.Errorf("failed to get destination component version: %w", err)
+	}
+	defer destinationComponentVersion.Close()
+
+	attestation, err := r.OCMClient.AttestReplication(ctx, subscription, sourceComponentVersion, destinationComponentVersion, startedOn)
+	if err != nil {
+		return err
+	}
+
+	obj.Status.Attestation = attestation
+
+	return nil
+}
+
+func (r *ComponentSubscriptionReconciler) signMpasComponent(
+	ctx context.Context,
+	obj, subscription *v1alpha1.ComponentSubscription,
+	destinationComponentVersion ocm2.ComponentVersionAccess,
+) error {
+	pub, err := r.OCMClient.SignDestinationComponent(ctx, subscription, destinationComponentVersion)
 	if err != nil {
 		return fmt.Errorf("failed to sign destination component: %w", err)
 	}
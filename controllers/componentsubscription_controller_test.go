@@ -7,15 +7,21 @@ import (
 
 	"github.com/fluxcd/pkg/apis/meta"
 	"github.com/fluxcd/pkg/runtime/conditions"
+	ocmv1alpha1 "github.com/open-component-model/ocm-controller/api/v1alpha1"
 	"github.com/open-component-model/ocm/pkg/contexts/ocm"
 	ocmdesc "github.com/open-component-model/ocm/pkg/contexts/ocm/compdesc"
 	v1 "github.com/open-component-model/ocm/pkg/contexts/ocm/compdesc/meta/v1"
 	"github.com/open-component-model/replication-controller/pkg/sign"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/open-component-model/replication-controller/api/v1alpha1"
 	"github.com/open-component-model/replication-controller/pkg/ocm/fakes"
@@ -106,10 +112,157 @@ func TestComponentSubscriptionReconciler(t *testing.T) {
 			verifyMock: func(fetcher *fakes.MockFetcher) bool {
 				args := fetcher.SignDestinationComponentCallingArgumentsOnCall(0)
 				name := args[0]
-				return name == "github.com/open-component-model/component"
+				destination := fetcher.GetDestinationComponentVersionCallingArgumentsOnCall(0)
+				return name == "github.com/open-component-model/component" && destination[1] == "v0.0.1"
 			},
 			mpasEnabled: true,
 		},
+		{
+			name: "provenance labels are added to the destination component",
+			subscription: func() *v1alpha1.ComponentSubscription {
+				cv := DefaultComponentSubscription.DeepCopy()
+				cv.Spec.Provenance = true
+				return cv
+			},
+			setupMock: func(fakeOcm *fakes.MockFetcher) {
+				root := &mockComponent{
+					t: t,
+					descriptor: &ocmdesc.ComponentDescriptor{
+						ComponentSpec: ocmdesc.ComponentSpec{
+							ObjectMeta: v1.ObjectMeta{
+								Name:    "github.com/open-component-model/component",
+								Version: "v0.0.1",
+							},
+						},
+					},
+				}
+				fakeOcm.GetComponentVersionReturnsForName(root.descriptor.ComponentSpec.Name, root, nil)
+				fakeOcm.GetLatestComponentVersionReturns("v0.0.1", nil)
+			},
+			verifyMock: func(fetcher *fakes.MockFetcher) bool {
+				args := fetcher.AddProvenanceCallingArgumentsOnCall(0)
+				cv := args[0].(*v1alpha1.ComponentSubscription)
+				return cv.Spec.Provenance && fetcher.SignDestinationComponentNotCalled() && !fetcher.AttestReplicationWasNotCalled()
+			},
+		},
+		{
+			name: "provenance failure marks the subscription as not ready",
+			subscription: func() *v1alpha1.ComponentSubscription {
+				cv := DefaultComponentSubscription.DeepCopy()
+				cv.Spec.Provenance = true
+				return cv
+			},
+			err: "failed to add provenance labels: nope",
+			setupMock: func(fakeOcm *fakes.MockFetcher) {
+				root := &mockComponent{
+					t: t,
+					descriptor: &ocmdesc.ComponentDescriptor{
+						ComponentSpec: ocmdesc.ComponentSpec{
+							ObjectMeta: v1.ObjectMeta{
+								Name:    "github.com/open-component-model/component",
+								Version: "v0.0.1",
+							},
+						},
+					},
+				}
+				fakeOcm.GetComponentVersionReturnsForName(root.descriptor.ComponentSpec.Name, root, nil)
+				fakeOcm.GetLatestComponentVersionReturns("v0.0.1", nil)
+				fakeOcm.AddProvenanceReturns(errors.New("nope"))
+			},
+			verifyMock: func(fetcher *fakes.MockFetcher) bool {
+				return !fetcher.AddProvenanceWasNotCalled()
+			},
+		},
+		{
+			name: "replication attestation is created for the destination component",
+			subscription: func() *v1alpha1.ComponentSubscription {
+				cv := DefaultComponentSubscription.DeepCopy()
+				cv.Spec.Attestation = true
+				return cv
+			},
+			setupMock: func(fakeOcm *fakes.MockFetcher) {
+				root := &mockComponent{
+					t: t,
+					descriptor: &ocmdesc.ComponentDescriptor{
+						ComponentSpec: ocmdesc.ComponentSpec{
+							ObjectMeta: v1.ObjectMeta{
+								Name:    "github.com/open-component-model/component",
+								Version: "v0.0.1",
+							},
+						},
+					},
+				}
+				fakeOcm.GetComponentVersionReturnsForName(root.descriptor.ComponentSpec.Name, root, nil)
+				fakeOcm.GetLatestComponentVersionReturns("v0.0.1", nil)
+				fakeOcm.AttestReplicationReturns(&v1alpha1.ReplicationAttestation{
+					Reference: "ghcr.io/acme/attestations/github.com/open-component-model/component:v0.0.1",
+					KeyID:     "key",
+				}, nil)
+			},
+			verifyMock: func(fetcher *fakes.MockFetcher) bool {
+				args := fetcher.AttestReplicationCallingArgumentsOnCall(0)
+				cv := args[0].(*v1alpha1.ComponentSubscription)
+				return cv.Status.Attestation != nil && cv.Status.Attestation.KeyID == "key" &&
+					fetcher.AddProvenanceWasNotCalled() && fetcher.SignDestinationComponentNotCalled()
+			},
+		},
+		{
+			name: "attestation failure marks the subscription as not ready",
+			subscription: func() *v1alpha1.ComponentSubscription {
+				cv := DefaultComponentSubscription.DeepCopy()
+				cv.Spec.Attestation = true
+				return cv
+			},
+			err: "failed to attest replication: nope",
+			setupMock: func(fakeOcm *fakes.MockFetcher) {
+				root := &mockComponent{
+					t: t,
+					descriptor: &ocmdesc.ComponentDescriptor{
+						ComponentSpec: ocmdesc.ComponentSpec{
+							ObjectMeta: v1.ObjectMeta{
+								Name:    "github.com/open-component-model/component",
+								Version: "v0.0.1",
+							},
+						},
+					},
+				}
+				fakeOcm.GetComponentVersionReturnsForName(root.descriptor.ComponentSpec.Name, root, nil)
+				fakeOcm.GetLatestComponentVersionReturns("v0.0.1", nil)
+				fakeOcm.AttestReplicationReturns(nil, errors.New("nope"))
+			},
+			verifyMock: func(fetcher *fakes.MockFetcher) bool {
+				return !fetcher.AttestReplicationWasNotCalled()
+			},
+		},
+		{
+			name: "no attestation is pushed if the destination component can't be persisted",
+			subscription: func() *v1alpha1.ComponentSubscription {
+				cv := DefaultComponentSubscription.DeepCopy()
+				cv.Spec.Attestation = true
+				return cv
+			},
+			// the mock is both the source and the destination component, so closing the source fails as well.
+			err: "failed to update component in destination repository: nope\nnope",
+			setupMock: func(fakeOcm *fakes.MockFetcher) {
+				root := &mockComponent{
+					t: t,
+					descriptor: &ocmdesc.ComponentDescriptor{
+						ComponentSpec: ocmdesc.ComponentSpec{
+							ObjectMeta: v1.ObjectMeta{
+								Name:    "github.com/open-component-model/component",
+								Version: "v0.0.1",
+							},
+						},
+					},
+					closeErr: errors.New("nope"),
+				}
+				fakeOcm.GetComponentVersionReturnsForName(root.descriptor.ComponentSpec.Name, root, nil)
+				fakeOcm.GetLatestComponentVersionReturns("v0.0.1", nil)
+			},
+			verifyMock: func(fetcher *fakes.MockFetcher) bool {
+				return fetcher.AttestReplicationWasNotCalled()
+			},
+		},
 		{
 			name: "no transfer is called if destination is left empty",
 			subscription: func() *v1alpha1.ComponentSubscription {
@@ -145,6 +298,65 @@ func TestComponentSubscriptionReconciler(t *testing.T) {
 				return fetcher.TransferComponentWasNotCalled() && fetcher.SignDestinationComponentNotCalled()
 			},
 		},
+		{
+			name: "images are mirrored without a destination",
+			subscription: func() *v1alpha1.ComponentSubscription {
+				cv := DefaultComponentSubscription.DeepCopy()
+				cv.Spec.Destination = nil
+				cv.Spec.MirrorImages = &v1alpha1.ImageMirror{
+					Destination: v1alpha1.OCMRepository{URL: "ghcr.io/acme/mirror"},
+				}
+				return cv
+			},
+			setupMock: func(fakeOcm *fakes.MockFetcher) {
+				root := &mockComponent{
+					t: t,
+					descriptor: &ocmdesc.ComponentDescriptor{
+						ComponentSpec: ocmdesc.ComponentSpec{
+							ObjectMeta: v1.ObjectMeta{
+								Name:    "github.com/open-component-model/component",
+								Version: "v0.0.1",
+							},
+						},
+					},
+				}
+				fakeOcm.GetComponentVersionReturnsForName(root.descriptor.ComponentSpec.Name, root, nil)
+				fakeOcm.GetLatestComponentVersionReturns("v0.0.1", nil)
+			},
+			verifyMock: func(fetcher *fakes.MockFetcher) bool {
+				return fetcher.TransferComponentWasNotCalled() && !fetcher.MirrorImagesWasNotCalled()
+			},
+		},
+		{
+			name: "image mirror failure marks the subscription as not ready",
+			subscription: func() *v1alpha1.ComponentSubscription {
+				cv := DefaultComponentSubscription.DeepCopy()
+				cv.Spec.MirrorImages = &v1alpha1.ImageMirror{
+					Destination: v1alpha1.OCMRepository{URL: "ghcr.io/acme/mirror"},
+				}
+				return cv
+			},
+			err: "failed to mirror images: nope",
+			setupMock: func(fakeOcm *fakes.MockFetcher) {
+				root := &mockComponent{
+					t: t,
+					descriptor: &ocmdesc.ComponentDescriptor{
+						ComponentSpec: ocmdesc.ComponentSpec{
+							ObjectMeta: v1.ObjectMeta{
+								Name:    "github.com/open-component-model/component",
+								Version: "v0.0.1",
+							},
+						},
+					},
+				}
+				fakeOcm.GetComponentVersionReturnsForName(root.descriptor.ComponentSpec.Name, root, nil)
+				fakeOcm.GetLatestComponentVersionReturns("v0.0.1", nil)
+				fakeOcm.MirrorImagesReturns(errors.New("nope"))
+			},
+			verifyMock: func(fetcher *fakes.MockFetcher) bool {
+				return !fetcher.TransferComponentWasNotCalled() && !fetcher.MirrorImagesWasNotCalled()
+			},
+		},
 		{
 			name: "reconciling doesn't happen if version was already reconciled",
 			subscription: func() *v1alpha1.ComponentSubscription {
@@ -283,6 +495,7 @@ type mockComponent struct {
 	descriptor *ocmdesc.ComponentDescriptor
 	ocm.ComponentVersionAccess
 	resourceAccess []ocm.ResourceAccess
+	closeErr       error
 	t              *testing.T
 }
 
@@ -299,5 +512,190 @@ func (m *mockComponent) GetResourcesByResourceSelectors(selectors ...ocmdesc.Res
 }
 
 func (m *mockComponent) Close() error {
-	return nil
+	return m.closeErr
+}
+
+func TestComponentSubscriptionReconciler_findObjects(t *testing.T) {
+	subscription := DefaultComponentSubscription.DeepCopy()
+	subscription.Spec.ServiceAccountName = "replication"
+	subscription.Spec.OCMConfigRef = &v1alpha1.OCMConfigReference{Name: "ocm-config"}
+	subscription.Spec.Source.CertSecretRef = &corev1.SecretReference{Name: "registry-ca"}
+	subscription.Spec.MirrorImages = &v1alpha1.ImageMirror{
+		Destination: v1alpha1.OCMRepository{
+			URL: "mirror.com/images",
+			Proxy: &v1alpha1.ProxyConfig{
+				URL:       "http://proxy.local:3128",
+				SecretRef: &corev1.LocalObjectReference{Name: "proxy-credentials"},
+			},
+		},
+	}
+	subscription.Spec.Verify = []ocmv1alpha1.Signature{
+		{
+			Name: "publisher",
+			PublicKey: ocmv1alpha1.PublicKey{
+				SecretRef: &corev1.LocalObjectReference{Name: "publisher-key"},
+			},
+		},
+	}
+	subscription.Spec.VerifyReferences = &v1alpha1.ReferenceVerification{
+		Components: []v1alpha1.ComponentSignatures{
+			{
+				Name: "github.com/acme/*",
+				VerifyCertificates: []v1alpha1.CertificateSignature{
+					{
+						Name:                      "acme",
+						RootCertificatesSecretRef: corev1.LocalObjectReference{Name: "acme-ca"},
+					},
+				},
+			},
+		},
+	}
+
+	subscription.Spec.VerifyImages = &v1alpha1.ImageVerification{
+		TrustStores: []corev1.LocalObjectReference{{Name: "notary-roots"}},
+	}
+
+	tenant := DefaultComponentSubscription.DeepCopy()
+	tenant.Namespace = "tenant"
+	tenant.Spec.Source.SecretRef = &corev1.SecretReference{Name: "registry-credentials", Namespace: "shared"}
+	tenant.Spec.Component = "github.com/tenant/component"
+	tenant.Spec.TrustPolicies = []string{"release"}
+
+	release := &v1alpha1.TrustPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "release"},
+		Spec: v1alpha1.TrustPolicySpec{
+			SecretNamespace: "ocm-system",
+			Verify: []ocmv1alpha1.Signature{
+				{Name: "release", PublicKey: ocmv1alpha1.PublicKey{SecretRef: &corev1.LocalObjectReference{Name: "release-key"}}},
+			},
+		},
+	}
+
+	account := &corev1.ServiceAccount{
+		ObjectMeta:       metav1.ObjectMeta{Name: "replication", Namespace: "default"},
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry-credentials"}},
+	}
+
+	builder := fake.NewClientBuilder().WithScheme(env.scheme).WithObjects(subscription, tenant, account, release).
+		WithIndex(&v1alpha1.ComponentSubscription{}, trustPolicyKey, indexTrustPolicies).
+		WithIndex(&v1alpha1.TrustPolicy{}, trustPolicySecretKey, indexTrustPolicySecrets)
+	for key := range indexes {
+		builder = builder.WithIndex(&v1alpha1.ComponentSubscription{}, key, indexField(key))
+	}
+
+	r := ComponentSubscriptionReconciler{
+		Scheme: env.scheme,
+		Client: builder.Build(),
+	}
+
+	expected := []reconcile.Request{{NamespacedName: client.ObjectKeyFromObject(subscription)}}
+
+	testCases := []struct {
+		name     string
+		obj      client.Object
+		expected []reconcile.Request
+	}{
+		{
+			name:     "destination secret",
+			obj:      &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "destination-secret", Namespace: "default"}},
+			expected: expected,
+		},
+		{
+			name:     "public key secret",
+			obj:      &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "publisher-key", Namespace: "default"}},
+			expected: expected,
+		},
+		{
+			name:     "root certificates secret of a reference",
+			obj:      &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "acme-ca", Namespace: "default"}},
+			expected: expected,
+		},
+		{
+			name:     "trust store of the images",
+			obj:      &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "notary-roots", Namespace: "default"}},
+			expected: expected,
+		},
+		{
+			name:     "image pull secret of the service account",
+			obj:      &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "registry-credentials", Namespace: "default"}},
+			expected: expected,
+		},
+		{
+			name:     "ocm config secret",
+			obj:      &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "ocm-config", Namespace: "default"}},
+			expected: expected,
+		},
+		{
+			name:     "certificate secret",
+			obj:      &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "registry-ca", Namespace: "default"}},
+			expected: expected,
+		},
+		{
+			name:     "proxy secret",
+			obj:      &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "proxy-credentials", Namespace: "default"}},
+			expected: expected,
+		},
+		{
+			name:     "granted secret of another namespace",
+			obj:      &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "registry-credentials", Namespace: "shared"}},
+			expected: []reconcile.Request{{NamespacedName: client.ObjectKeyFromObject(tenant)}},
+		},
+		{
+			name:     "public key secret of a referenced trust policy",
+			obj:      &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "release-key", Namespace: "ocm-system"}},
+			expected: []reconcile.Request{{NamespacedName: client.ObjectKeyFromObject(tenant)}},
+		},
+		{
+			name:     "unrelated secret",
+			obj:      &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "publisher-key", Namespace: "other"}},
+			expected: []reconcile.Request{},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ElementsMatch(t, tt.expected, r.findSecretObjects(tt.obj))
+		})
+	}
+
+	t.Run("service account", func(t *testing.T) {
+		assert.ElementsMatch(t, expected, r.findObjects(serviceAccountKey)(account))
+	})
+
+	t.Run("secret reference grant", func(t *testing.T) {
+		grant := &v1alpha1.SecretReferenceGrant{ObjectMeta: metav1.ObjectMeta{Name: "tenants", Namespace: "shared"}}
+		assert.ElementsMatch(t, []reconcile.Request{{NamespacedName: client.ObjectKeyFromObject(tenant)}}, r.findGrantObjects(grant))
+
+		grant.Namespace = "default"
+		assert.Empty(t, r.findGrantObjects(grant))
+	})
+
+	t.Run("trust policy", func(t *testing.T) {
+		assert.ElementsMatch(t, []reconcile.Request{{NamespacedName: client.ObjectKeyFromObject(tenant)}}, r.findTrustPolicyObjects(release))
+
+		matching := &v1alpha1.TrustPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "acme"},
+			Spec:       v1alpha1.TrustPolicySpec{Components: []string{"github.com/open-component-model/*"}},
+		}
+		assert.ElementsMatch(t, expected, r.findTrustPolicyObjects(matching))
+
+		matching.Spec.Components = []string{"github.com/acme/*"}
+		assert.Empty(t, r.findTrustPolicyObjects(matching))
+
+		subscription.Status.Components = []v1alpha1.SubscribedComponent{{Name: "github.com/acme/backend"}}
+		assert.True(t, appliesTo(matching, subscription), "the policy applies to a verified reference")
+
+		subscription.Spec.VerifyReferences = nil
+		assert.False(t, appliesTo(matching, subscription), "references of other subscriptions aren't verified")
+
+		subscription.Status.References = []v1alpha1.TransferredReference{
+			{Parent: "github.com/open-component-model/component:v0.0.1", Name: "backend", Component: "github.com/acme/backend:v0.0.1", Transferred: true},
+		}
+		assert.True(t, appliesTo(matching, subscription), "the policy applies to a transferred reference")
+	})
+
+	t.Run("config map of the same name", func(t *testing.T) {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "ocm-config", Namespace: "default"}}
+		assert.Empty(t, r.findObjects(ocmConfigMapKey)(cm))
+	})
 }
@@ -0,0 +1,331 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	"github.com/open-component-model/ocm-controller/pkg/status"
+	ocm2 "github.com/open-component-model/ocm/pkg/contexts/ocm"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/open-component-model/replication-controller/api/v1alpha1"
+)
+
+const (
+	// maxMirrorFailures limits the number of failures listed in the status. All of them are counted in the summary.
+	maxMirrorFailures = 20
+
+	// maxMirrorQueue limits the number of versions queued in the status. The following versions are queued once
+	// the queue is empty.
+	maxMirrorQueue = 500
+
+	// mirrorDigestsKey is the key of the mirror ConfigMap holding the source digests of the mirrored versions.
+	mirrorDigestsKey = "digests.json"
+)
+
+// mirrorDigests maps the mirrored components to the source digests of their versions.
+type mirrorDigests map[string]map[string]string
+
+// reconcileMirror mirrors every version of the subscribed components in runs. A run is planned once the previous run
+// completed or the subscription changed, and queues the versions missing in the destination or changed in the source.
+// Every reconciliation transfers up to MaxTransfers versions of the queue, and records their source digests in the
+// mirror ConfigMap the following runs compare with. The queue is kept in the status, so a run resumes where it
+// stopped, e.g. after the controller restarted.
+func (r *ComponentSubscriptionReconciler) reconcileMirror(
+	ctx context.Context,
+	octx ocm2.Context,
+	obj *v1alpha1.ComponentSubscription,
+) (ctrl.Result, error) {
+	if obj.Spec.Destination == nil {
+		status.MarkAsStalled(r.EventRecorder, obj, v1alpha1.MirrorFailedReason, "mirror requires a destination")
+
+		return ctrl.Result{}, nil
+	}
+
+	digests, err := r.loadMirrorDigests(ctx, obj)
+	if err != nil {
+		status.MarkNotReady(r.EventRecorder, obj, v1alpha1.MirrorFailedReason, err.Error())
+
+		return ctrl.Result{}, err
+	}
+
+	mirror := obj.Status.Mirror
+	if mirror == nil || mirror.Generation != obj.Generation || (len(mirror.Queue) == 0 && mirror.Continue == nil) {
+		mirror = &v1alpha1.MirrorStatus{
+			Generation: obj.Generation,
+			StartedAt:  &metav1.Time{Time: time.Now()},
+		}
+		if obj.Status.Mirror != nil {
+			mirror.CompletedAt = obj.Status.Mirror.CompletedAt
+		}
+	}
+
+	if len(mirror.Queue) == 0 {
+		if err := r.planMirror(ctx, octx, obj, mirror, digests); err != nil {
+			err := fmt.Errorf("failed to plan mirror: %w", err)
+			status.MarkNotReady(r.EventRecorder, obj, v1alpha1.ComponentDiscoveryFailedReason, err.Error())
+
+			return ctrl.Result{}, err
+		}
+	}
+
+	obj.Status.Mirror = mirror
+
+	batch := min(len(mirror.Queue), obj.Spec.Mirror.GetMaxTransfers())
+	transferred := 0
+	for _, entry := range mirror.Queue[:batch] {
+		if err := r.mirrorVersion(ctx, octx, obj, entry); err != nil {
+			log.FromContext(ctx).Error(err, "failed to mirror component version", "component", entry.Component, "version", entry.Version)
+			addMirrorFailure(mirror, entry.Component, entry.Version, err)
+
+			continue
+		}
+
+		if digests[entry.Component] == nil {
+			digests[entry.Component] = make(map[string]string)
+		}
+		digests[entry.Component][entry.Version] = entry.Digest
+		transferred++
+	}
+
+	// the batch stays queued if its digests can't be recorded, as the next run wouldn't detect changes otherwise.
+	if transferred > 0 {
+		if err := r.storeMirrorDigests(ctx, obj, digests); err != nil {
+			status.MarkNotReady(r.EventRecorder, obj, v1alpha1.MirrorFailedReason, err.Error())
+
+			return ctrl.Result{}, err
+		}
+	}
+
+	mirror.Queue = mirror.Queue[batch:]
+	mirror.Summary.Transferred += transferred
+	mirror.Summary.Pending = len(mirror.Queue)
+
+	// The run continues with the next reconciliation. It's neither ready nor failed yet, and isn't reported as a
+	// retried reconciliation either.
+	if len(mirror.Queue) > 0 || mirror.Continue != nil {
+		conditions.Delete(obj, meta.ReconcilingCondition)
+		conditions.MarkUnknown(obj, meta.ReadyCondition, meta.ProgressingReason,
+			"Mirroring: transferred %d versions, %d pending", mirror.Summary.Transferred, mirror.Summary.Pending)
+
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	mirror.Queue = nil
+	mirror.CompletedAt = &metav1.Time{Time: time.Now()}
+
+	if mirror.Summary.Failed > 0 {
+		status.MarkNotReady(r.EventRecorder, obj, v1alpha1.MirrorFailedReason,
+			fmt.Sprintf("failed to mirror %d versions or components, they're retried in %s", mirror.Summary.Failed, obj.GetRequeueAfter()))
+
+		return ctrl.Result{RequeueAfter: obj.GetRequeueAfter()}, nil
+	}
+
+	status.MarkReady(r.EventRecorder, obj, "Mirrored %d versions of %d components, transferred %d",
+		mirror.Summary.Versions, mirror.Summary.Components, mirror.Summary.Transferred)
+
+	return ctrl.Result{RequeueAfter: obj.GetRequeueAfter()}, nil
+}
+
+// planMirror queues the versions of the subscribed components that must be mirrored, until the queue holds
+// maxMirrorQueue versions. It continues after the position recorded by the previous call of the run, and records
+// the position to continue after if the queue is full. Components are counted in the summary once they're planned.
+// Components whose versions can't be listed are recorded as failures, so they don't block the others.
+func (r *ComponentSubscriptionReconciler) planMirror(
+	ctx context.Context,
+	octx ocm2.Context,
+	obj *v1alpha1.ComponentSubscription,
+	mirror *v1alpha1.MirrorStatus,
+	digests mirrorDigests,
+) error {
+	components := []string{obj.Spec.Component}
+	if obj.Spec.Component == "" {
+		var err error
+		if components, err = r.OCMClient.ListComponents(ctx, octx, obj); err != nil {
+			return err
+		}
+	}
+
+	next := mirror.Continue
+	mirror.Continue = nil
+
+	// components are listed in ascending order, so the run continues even if the component it stopped at was
+	// removed since.
+	start := 0
+	if next == nil {
+		mirror.Summary.Components = len(components)
+	} else {
+		start = sort.SearchStrings(components, next.Component)
+	}
+
+	for _, component := range components[start:] {
+		if len(mirror.Queue) == maxMirrorQueue {
+			mirror.Continue = &v1alpha1.MirrorQueueEntry{Component: component}
+
+			return nil
+		}
+
+		versions, pending, err := r.OCMClient.ListPendingVersions(ctx, octx, mirrorSubscription(obj, component), digests[component])
+		if err != nil {
+			addMirrorFailure(mirror, component, "", fmt.Errorf("failed to list versions: %w", err))
+
+			continue
+		}
+
+		if next != nil && next.Component == component && next.Version != "" {
+			// the versions up to the recorded one were counted and queued before.
+			position := slices.Index(versions, next.Version)
+			queued := make(map[string]bool, position+1)
+			for _, version := range versions[:position+1] {
+				queued[version] = true
+			}
+
+			pending = slices.DeleteFunc(pending, func(entry v1alpha1.MirrorQueueEntry) bool {
+				return queued[entry.Version]
+			})
+		} else {
+			mirror.Summary.Versions += len(versions)
+			mirror.Summary.UpToDate += len(versions) - len(pending)
+		}
+
+		for _, entry := range pending {
+			if len(mirror.Queue) == maxMirrorQueue {
+				last := mirror.Queue[len(mirror.Queue)-1]
+				mirror.Continue = &v1alpha1.MirrorQueueEntry{Component: last.Component, Version: last.Version}
+
+				return nil
+			}
+
+			mirror.Queue = append(mirror.Queue, entry)
+		}
+	}
+
+	return nil
+}
+
+// mirrorVersion transfers a single component version of the queue. Signatures and attestations of the transferred
+// version are recorded in the status of the subscription, as for a single component.
+func (r *ComponentSubscriptionReconciler) mirrorVersion(
+	ctx context.Context,
+	octx ocm2.Context,
+	obj *v1alpha1.ComponentSubscription,
+	entry v1alpha1.MirrorQueueEntry,
+) (err error) {
+	subscription := mirrorSubscription(obj, entry.Component)
+
+	cv, err := r.OCMClient.GetComponentVersion(ctx, octx, subscription, entry.Version)
+	if err != nil {
+		return fmt.Errorf("failed to get component version: %w", err)
+	}
+
+	defer func() {
+		if cerr := cv.Close(); cerr != nil {
+			err = errors.Join(err, cerr)
+		}
+	}()
+
+	if r.MpasEnabled {
+		if err := r.checkMpasComponent(subscription, cv); err != nil {
+			return fmt.Errorf("failed to check mpas component: %w", err)
+		}
+	}
+
+	startedOn := time.Now()
+	if err := r.OCMClient.TransferComponent(ctx, octx, subscription, cv); err != nil {
+		return fmt.Errorf("failed to transfer component: %w", err)
+	}
+
+	return r.updateDestinationComponent(ctx, octx, obj, subscription, cv, entry.Version, startedOn)
+}
+
+// mirrorSubscription returns the subscription a single component of a mirror is replicated with.
+func mirrorSubscription(obj *v1alpha1.ComponentSubscription, component string) *v1alpha1.ComponentSubscription {
+	subscription := obj.DeepCopy()
+	subscription.Spec.Component = component
+	subscription.Spec.ComponentSelector = nil
+	subscription.Spec.Mirror = nil
+	subscription.Spec.MirrorImages = nil
+	subscription.Spec.PullSecret = nil
+
+	return subscription
+}
+
+// addMirrorFailure records a failure of the run. Only the first failures are listed.
+func addMirrorFailure(mirror *v1alpha1.MirrorStatus, component, version string, err error) {
+	mirror.Summary.Failed++
+	if len(mirror.Failures) < maxMirrorFailures {
+		mirror.Failures = append(mirror.Failures, v1alpha1.MirrorFailure{
+			Component: component,
+			Version:   version,
+			Message:   err.Error(),
+		})
+	}
+}
+
+// loadMirrorDigests reads the source digests of the mirrored versions from the mirror ConfigMap. It's created with
+// the first transferred version.
+func (r *ComponentSubscriptionReconciler) loadMirrorDigests(ctx context.Context, obj *v1alpha1.ComponentSubscription) (mirrorDigests, error) {
+	cm := &corev1.ConfigMap{}
+	key := types.NamespacedName{Name: mirrorConfigMapName(obj), Namespace: obj.Namespace}
+
+	digests := mirrorDigests{}
+	if err := r.Get(ctx, key, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return digests, nil
+		}
+
+		return nil, fmt.Errorf("failed to get mirror config map %s: %w", key.Name, err)
+	}
+
+	if data, ok := cm.Data[mirrorDigestsKey]; ok {
+		if err := json.Unmarshal([]byte(data), &digests); err != nil {
+			return nil, fmt.Errorf("failed to parse mirror config map %s: %w", key.Name, err)
+		}
+	}
+
+	return digests, nil
+}
+
+// storeMirrorDigests writes the source digests of the mirrored versions to the mirror ConfigMap owned by the
+// subscription.
+func (r *ComponentSubscriptionReconciler) storeMirrorDigests(ctx context.Context, obj *v1alpha1.ComponentSubscription, digests mirrorDigests) error {
+	data, err := json.Marshal(digests)
+	if err != nil {
+		return fmt.Errorf("failed to marshal mirror digests: %w", err)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mirrorConfigMapName(obj),
+			Namespace: obj.Namespace,
+		},
+	}
+
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, cm, func() error {
+		cm.Data = map[string]string{mirrorDigestsKey: string(data)}
+
+		return controllerutil.SetOwnerReference(obj, cm, r.Scheme)
+	}); err != nil {
+		return fmt.Errorf("failed to write mirror config map %s: %w", cm.Name, err)
+	}
+
+	return nil
+}
+
+// mirrorConfigMapName returns the name of the ConfigMap recording the mirrored versions of a subscription.
+func mirrorConfigMapName(obj *v1alpha1.ComponentSubscription) string {
+	return obj.Name + "-mirror"
+}
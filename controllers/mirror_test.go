@@ -0,0 +1,269 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	ocmdesc "github.com/open-component-model/ocm/pkg/contexts/ocm/compdesc"
+	v1 "github.com/open-component-model/ocm/pkg/contexts/ocm/compdesc/meta/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/open-component-model/replication-controller/api/v1alpha1"
+	"github.com/open-component-model/replication-controller/pkg/ocm/fakes"
+)
+
+func TestComponentSubscriptionReconcilerMirror(t *testing.T) {
+	testCases := []struct {
+		name        string
+		status      *v1alpha1.MirrorStatus
+		setupMock   func(*fakes.MockFetcher)
+		reconciles  int
+		transferred int
+		recorded    string
+		attestation *v1alpha1.ReplicationAttestation
+		expected    v1alpha1.MirrorSummary
+		queue       []v1alpha1.MirrorQueueEntry
+		failures    []v1alpha1.MirrorFailure
+		digests     string
+		ready       metav1.ConditionStatus
+	}{
+		{
+			name: "pending versions are transferred in batches",
+			setupMock: func(fakeOcm *fakes.MockFetcher) {
+				fakeOcm.ListPendingVersionsReturnsForName("github.com/acme/api", []string{"v1.0.0", "v1.1.0", "v1.2.0"}, []string{"v1.1.0", "v1.2.0"}, nil)
+				fakeOcm.ListPendingVersionsReturnsForName("github.com/acme/ui", []string{"v2.0.0"}, []string{"v2.0.0"}, nil)
+			},
+			reconciles:  1,
+			transferred: 2,
+			expected:    v1alpha1.MirrorSummary{Components: 2, Versions: 4, UpToDate: 1, Transferred: 2, Pending: 1},
+			queue:       []v1alpha1.MirrorQueueEntry{{Component: "github.com/acme/ui", Version: "v2.0.0", Digest: "digest-v2.0.0"}},
+			digests:     `{"github.com/acme/api":{"v1.1.0":"digest-v1.1.0","v1.2.0":"digest-v1.2.0"}}`,
+			ready:       metav1.ConditionUnknown,
+		},
+		{
+			name: "the run completes across reconciliations",
+			setupMock: func(fakeOcm *fakes.MockFetcher) {
+				fakeOcm.ListPendingVersionsReturnsForName("github.com/acme/api", []string{"v1.0.0", "v1.1.0", "v1.2.0"}, []string{"v1.1.0", "v1.2.0"}, nil)
+				fakeOcm.ListPendingVersionsReturnsForName("github.com/acme/ui", []string{"v2.0.0"}, []string{"v2.0.0"}, nil)
+			},
+			reconciles:  2,
+			transferred: 3,
+			expected:    v1alpha1.MirrorSummary{Components: 2, Versions: 4, UpToDate: 1, Transferred: 3},
+			digests:     `{"github.com/acme/api":{"v1.1.0":"digest-v1.1.0","v1.2.0":"digest-v1.2.0"},"github.com/acme/ui":{"v2.0.0":"digest-v2.0.0"}}`,
+			ready:       metav1.ConditionTrue,
+		},
+		{
+			name: "an interrupted run is resumed",
+			status: &v1alpha1.MirrorStatus{
+				Generation: 1,
+				Queue:      []v1alpha1.MirrorQueueEntry{{Component: "github.com/acme/ui", Version: "v2.0.0", Digest: "digest-v2.0.0"}},
+				Summary:    v1alpha1.MirrorSummary{Components: 2, Versions: 4, UpToDate: 1, Transferred: 2, Pending: 1},
+			},
+			recorded:    `{"github.com/acme/api":{"v1.1.0":"digest-v1.1.0","v1.2.0":"digest-v1.2.0"}}`,
+			reconciles:  1,
+			transferred: 1,
+			expected:    v1alpha1.MirrorSummary{Components: 2, Versions: 4, UpToDate: 1, Transferred: 3},
+			digests:     `{"github.com/acme/api":{"v1.1.0":"digest-v1.1.0","v1.2.0":"digest-v1.2.0"},"github.com/acme/ui":{"v2.0.0":"digest-v2.0.0"}}`,
+			ready:       metav1.ConditionTrue,
+		},
+		{
+			name: "a changed subscription plans a new run",
+			status: &v1alpha1.MirrorStatus{
+				Queue:   []v1alpha1.MirrorQueueEntry{{Component: "github.com/acme/removed", Version: "v1.0.0"}},
+				Summary: v1alpha1.MirrorSummary{Components: 1, Versions: 1, Pending: 1},
+			},
+			setupMock: func(fakeOcm *fakes.MockFetcher) {
+				fakeOcm.ListPendingVersionsReturnsForName("github.com/acme/api", []string{"v1.0.0"}, nil, nil)
+				fakeOcm.ListPendingVersionsReturnsForName("github.com/acme/ui", []string{"v2.0.0"}, nil, nil)
+			},
+			recorded:   `{"github.com/acme/api":{"v1.0.0":"digest-v1.0.0"}}`,
+			reconciles: 1,
+			expected:   v1alpha1.MirrorSummary{Components: 2, Versions: 2, UpToDate: 2},
+			digests:    `{"github.com/acme/api":{"v1.0.0":"digest-v1.0.0"}}`,
+			ready:      metav1.ConditionTrue,
+		},
+		{
+			name: "attestations are recorded in the status of the subscription",
+			setupMock: func(fakeOcm *fakes.MockFetcher) {
+				fakeOcm.ListPendingVersionsReturnsForName("github.com/acme/api", []string{"v1.0.0"}, []string{"v1.0.0"}, nil)
+				fakeOcm.ListPendingVersionsReturnsForName("github.com/acme/ui", nil, nil, nil)
+			},
+			attestation: &v1alpha1.ReplicationAttestation{Reference: "ghcr.io/acme/attestations:v1.0.0", KeyID: "replication"},
+			reconciles:  1,
+			transferred: 1,
+			expected:    v1alpha1.MirrorSummary{Components: 2, Versions: 1, Transferred: 1},
+			digests:     `{"github.com/acme/api":{"v1.0.0":"digest-v1.0.0"}}`,
+			ready:       metav1.ConditionTrue,
+		},
+		{
+			name: "failures don't block the other versions",
+			setupMock: func(fakeOcm *fakes.MockFetcher) {
+				fakeOcm.ListPendingVersionsReturnsForName("github.com/acme/api", nil, nil, errors.New("unauthorized"))
+				fakeOcm.ListPendingVersionsReturnsForName("github.com/acme/ui", []string{"v2.0.0"}, []string{"v2.0.0"}, nil)
+				fakeOcm.TransferComponentReturns(errors.New("blob not found"))
+			},
+			reconciles: 1,
+			expected:   v1alpha1.MirrorSummary{Components: 2, Versions: 1, Failed: 2},
+			failures: []v1alpha1.MirrorFailure{
+				{Component: "github.com/acme/api", Message: "failed to list versions: unauthorized"},
+				{Component: "github.com/acme/ui", Version: "v2.0.0", Message: "failed to transfer component: blob not found"},
+			},
+			ready: metav1.ConditionFalse,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := DefaultComponentSubscription.DeepCopy()
+			obj.Generation = 1
+			obj.Spec.Component = ""
+			obj.Spec.Semver = ""
+			obj.Spec.Mirror = &v1alpha1.RepositoryMirror{MaxTransfers: 2}
+			obj.Spec.Attestation = tt.attestation != nil
+			obj.Status.Mirror = tt.status
+
+			objects := []client.Object{obj}
+			if tt.recorded != "" {
+				objects = append(objects, &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: obj.Name + "-mirror", Namespace: obj.Namespace},
+					Data:       map[string]string{mirrorDigestsKey: tt.recorded},
+				})
+			}
+
+			kubeClient := env.FakeKubeClient(WithObjets(objects...))
+			fakeOcm := &fakes.MockFetcher{}
+			fakeOcm.ListComponentsReturns([]string{"github.com/acme/api", "github.com/acme/ui"}, nil)
+			fakeOcm.GetComponentVersionReturnsForName("github.com/acme/api", mirrorComponent(t, "github.com/acme/api"), nil)
+			fakeOcm.GetComponentVersionReturnsForName("github.com/acme/ui", mirrorComponent(t, "github.com/acme/ui"), nil)
+			fakeOcm.AttestReplicationReturns(tt.attestation, nil)
+			if tt.setupMock != nil {
+				tt.setupMock(fakeOcm)
+			}
+
+			r := ComponentSubscriptionReconciler{
+				Scheme:        env.scheme,
+				Client:        kubeClient,
+				OCMClient:     fakeOcm,
+				EventRecorder: record.NewFakeRecorder(32),
+			}
+
+			for i := 0; i < tt.reconciles; i++ {
+				_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+				require.NoError(t, err)
+			}
+
+			require.NoError(t, kubeClient.Get(context.Background(), client.ObjectKeyFromObject(obj), obj))
+			require.NotNil(t, obj.Status.Mirror)
+			assert.Equal(t, tt.expected, obj.Status.Mirror.Summary)
+			assert.Equal(t, tt.queue, obj.Status.Mirror.Queue)
+			assert.Equal(t, tt.failures, obj.Status.Mirror.Failures)
+			assert.Equal(t, int64(1), obj.Status.Mirror.Generation)
+			assert.Equal(t, tt.ready, conditions.Get(obj, meta.ReadyCondition).Status)
+			assert.Equal(t, tt.ready == metav1.ConditionUnknown, obj.Status.Mirror.CompletedAt == nil)
+			assert.Equal(t, tt.attestation, obj.Status.Attestation)
+
+			cm := &corev1.ConfigMap{}
+			err := kubeClient.Get(context.Background(), client.ObjectKey{Name: obj.Name + "-mirror", Namespace: obj.Namespace}, cm)
+			if tt.digests == "" {
+				assert.True(t, apierrors.IsNotFound(err))
+			} else {
+				require.NoError(t, err)
+				assert.JSONEq(t, tt.digests, cm.Data[mirrorDigestsKey])
+			}
+
+			if tt.recorded != "" && !fakeOcm.ListPendingVersionsWasNotCalled() {
+				mirrored := fakeOcm.ListPendingVersionsCallingArgumentsOnCall(0)[1].(map[string]string)
+				assert.Equal(t, map[string]string{"v1.0.0": "digest-v1.0.0"}, mirrored)
+			}
+
+			for i := 0; i < tt.transferred; i++ {
+				subscription := fakeOcm.TransferComponentCallingArgumentsOnCall(i)[0].(*v1alpha1.ComponentSubscription)
+				assert.Nil(t, subscription.Spec.Mirror)
+				assert.False(t, subscription.Spec.Provenance, "mirrors must not change the signed content")
+			}
+			if tt.transferred == 0 && tt.failures == nil {
+				assert.True(t, fakeOcm.TransferComponentWasNotCalled())
+			}
+		})
+	}
+}
+
+func TestComponentSubscriptionReconcilerMirrorQueue(t *testing.T) {
+	var versions []string
+	for i := 0; i < maxMirrorQueue+100; i++ {
+		versions = append(versions, fmt.Sprintf("v1.0.%d", i))
+	}
+
+	obj := DefaultComponentSubscription.DeepCopy()
+	obj.Generation = 1
+	obj.Spec.Component = ""
+	obj.Spec.Semver = ""
+	obj.Spec.Mirror = &v1alpha1.RepositoryMirror{MaxTransfers: maxMirrorQueue}
+
+	kubeClient := env.FakeKubeClient(WithObjets(obj))
+	fakeOcm := &fakes.MockFetcher{}
+	fakeOcm.ListComponentsReturns([]string{"github.com/acme/api", "github.com/acme/ui"}, nil)
+	fakeOcm.GetComponentVersionReturnsForName("github.com/acme/api", mirrorComponent(t, "github.com/acme/api"), nil)
+	fakeOcm.GetComponentVersionReturnsForName("github.com/acme/ui", mirrorComponent(t, "github.com/acme/ui"), nil)
+	fakeOcm.ListPendingVersionsReturnsForName("github.com/acme/api", versions, versions, nil)
+	fakeOcm.ListPendingVersionsReturnsForName("github.com/acme/ui", []string{"v2.0.0"}, []string{"v2.0.0"}, nil)
+
+	r := ComponentSubscriptionReconciler{
+		Scheme:        env.scheme,
+		Client:        kubeClient,
+		OCMClient:     fakeOcm,
+		EventRecorder: record.NewFakeRecorder(32),
+	}
+
+	reconcile := func() *v1alpha1.MirrorStatus {
+		_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+		require.NoError(t, err)
+		require.NoError(t, kubeClient.Get(context.Background(), client.ObjectKeyFromObject(obj), obj))
+
+		return obj.Status.Mirror
+	}
+
+	mirror := reconcile()
+	assert.Empty(t, mirror.Queue)
+	assert.Equal(t, &v1alpha1.MirrorQueueEntry{Component: "github.com/acme/api", Version: versions[maxMirrorQueue-1]}, mirror.Continue)
+	assert.Equal(t, v1alpha1.MirrorSummary{Components: 2, Versions: len(versions), Transferred: maxMirrorQueue}, mirror.Summary)
+	assert.Equal(t, metav1.ConditionUnknown, conditions.Get(obj, meta.ReadyCondition).Status)
+
+	// the queue is refilled with the versions following the recorded one, without counting them again.
+	mirror = reconcile()
+	assert.Empty(t, mirror.Queue)
+	assert.Nil(t, mirror.Continue)
+	assert.Equal(t, v1alpha1.MirrorSummary{Components: 2, Versions: len(versions) + 1, Transferred: len(versions) + 1}, mirror.Summary)
+	assert.Equal(t, metav1.ConditionTrue, conditions.Get(obj, meta.ReadyCondition).Status)
+
+	// every version was transferred once.
+	cm := &corev1.ConfigMap{}
+	require.NoError(t, kubeClient.Get(context.Background(), client.ObjectKey{Name: obj.Name + "-mirror", Namespace: obj.Namespace}, cm))
+	digests := mirrorDigests{}
+	require.NoError(t, json.Unmarshal([]byte(cm.Data[mirrorDigestsKey]), &digests))
+	assert.Len(t, digests["github.com/acme/api"], len(versions))
+	assert.Len(t, digests["github.com/acme/ui"], 1)
+}
+
+func mirrorComponent(t *testing.T, name string) *mockComponent {
+	return &mockComponent{
+		t: t,
+		descriptor: &ocmdesc.ComponentDescriptor{
+			ComponentSpec: ocmdesc.ComponentSpec{
+				ObjectMeta: v1.ObjectMeta{Name: name, Version: "v1.0.0"},
+			},
+		},
+	}
+}
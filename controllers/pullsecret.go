@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/open-component-model/replication-controller/api/v1alpha1"
+	"github.com/open-component-model/replication-controller/pkg/ocm"
+)
+
+const (
+	// pullSecretAnnotation records the subscription that manages a propagated pull secret in the form
+	// `namespace/name`. Owner references can't be used, as the Secrets are created in other namespaces.
+	pullSecretAnnotation = "delivery.ocm.software/subscription"
+
+	// pullSecretFinalizer keeps a subscription until its propagated pull secrets are deleted.
+	pullSecretFinalizer = "delivery.ocm.software/pull-secrets"
+)
+
+// reconcilePullSecrets creates or updates the pull secret of the subscription in all configured namespaces and
+// deletes the pull secrets that are no longer configured. Namespaces other than the one of the subscription must
+// grant the pull secret with a SecretReferenceGrant. The propagated pull secrets are recorded in the status, and a
+// finalizer deletes them with the subscription.
+func (r *ComponentSubscriptionReconciler) reconcilePullSecrets(ctx context.Context, obj *v1alpha1.ComponentSubscription) error {
+	var propagated []string
+
+	if obj.Spec.PullSecret != nil {
+		data, err := r.pullSecretData(ctx, obj)
+		if err != nil {
+			return err
+		}
+
+		controllerutil.AddFinalizer(obj, pullSecretFinalizer)
+
+		for _, namespace := range obj.Spec.PullSecret.Namespaces {
+			key := types.NamespacedName{Namespace: namespace, Name: obj.Spec.PullSecret.Name}
+			if err := r.OCMClient.AuthorizeSecretReference(ctx, obj.Namespace, key); err != nil {
+				return err
+			}
+
+			if err := r.writePullSecret(ctx, obj, key, data); err != nil {
+				return err
+			}
+
+			propagated = append(propagated, key.String())
+		}
+	}
+
+	for _, previous := range obj.Status.PullSecrets {
+		if slices.Contains(propagated, previous) {
+			continue
+		}
+
+		namespace, name, _ := strings.Cut(previous, "/")
+		if err := r.deletePullSecret(ctx, obj, types.NamespacedName{Namespace: namespace, Name: name}); err != nil {
+			return err
+		}
+	}
+
+	obj.Status.PullSecrets = propagated
+	if len(propagated) == 0 {
+		controllerutil.RemoveFinalizer(obj, pullSecretFinalizer)
+	}
+
+	return nil
+}
+
+// reconcileDelete deletes the propagated pull secrets of a deleted subscription and removes its finalizer.
+func (r *ComponentSubscriptionReconciler) reconcileDelete(ctx context.Context, obj *v1alpha1.ComponentSubscription) error {
+	if !controllerutil.ContainsFinalizer(obj, pullSecretFinalizer) {
+		return nil
+	}
+
+	for _, previous := range obj.Status.PullSecrets {
+		namespace, name, _ := strings.Cut(previous, "/")
+		if err := r.deletePullSecret(ctx, obj, types.NamespacedName{Namespace: namespace, Name: name}); err != nil {
+			return err
+		}
+	}
+
+	controllerutil.RemoveFinalizer(obj, pullSecretFinalizer)
+	if err := r.Update(ctx, obj); err != nil {
+		return fmt.Errorf("failed to remove finalizer: %w", err)
+	}
+
+	return nil
+}
+
+// pullSecretData returns the dockerconfigjson for the destination registry. Without a destination, the credentials
+// of the image mirror are used.
+func (r *ComponentSubscriptionReconciler) pullSecretData(ctx context.Context, obj *v1alpha1.ComponentSubscription) ([]byte, error) {
+	var repository v1alpha1.OCMRepository
+	switch {
+	case obj.Spec.Destination != nil:
+		repository = *obj.Spec.Destination
+	case obj.Spec.MirrorImages != nil:
+		repository = obj.Spec.MirrorImages.Destination
+	default:
+		return nil, fmt.Errorf("pull secrets require a destination or an image mirror")
+	}
+
+	// granted credentials of other namespaces have been authorized when configuring the OCM context.
+	var key types.NamespacedName
+	switch {
+	case obj.Spec.PullSecret.SecretRef != nil:
+		key = types.NamespacedName{Namespace: obj.Namespace, Name: obj.Spec.PullSecret.SecretRef.Name}
+	case repository.SecretRef != nil:
+		key = ocm.SecretReferenceKey(obj.Namespace, repository.SecretRef)
+	default:
+		return nil, fmt.Errorf("no credentials configured for %s", repository.URL)
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, key, secret); err != nil {
+		return nil, fmt.Errorf("failed to get credentials secret %s: %w", key.Name, err)
+	}
+
+	return ocm.DockerConfig(secret, repository)
+}
+
+// writePullSecret creates or updates the pull secret with the given key. Secrets that exist but aren't managed by
+// the subscription are never modified.
+func (r *ComponentSubscriptionReconciler) writePullSecret(
+	ctx context.Context,
+	obj *v1alpha1.ComponentSubscription,
+	key types.NamespacedName,
+	data []byte,
+) error {
+	owner := client.ObjectKeyFromObject(obj).String()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+		},
+	}
+
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		if secret.ResourceVersion != "" && secret.Annotations[pullSecretAnnotation] != owner {
+			return fmt.Errorf("secret is not managed by subscription %s", owner)
+		}
+
+		if secret.Annotations == nil {
+			secret.Annotations = map[string]string{}
+		}
+
+		secret.Annotations[pullSecretAnnotation] = owner
+		secret.Type = corev1.SecretTypeDockerConfigJson
+		secret.Data = map[string][]byte{corev1.DockerConfigJsonKey: data}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to write pull secret %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// deletePullSecret deletes the pull secret with the given key if it is managed by the subscription.
+func (r *ComponentSubscriptionReconciler) deletePullSecret(ctx context.Context, obj *v1alpha1.ComponentSubscription, key types.NamespacedName) error {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, key, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to get pull secret %s: %w", key, err)
+	}
+
+	if secret.Annotations[pullSecretAnnotation] != client.ObjectKeyFromObject(obj).String() {
+		return nil
+	}
+
+	if err := r.Delete(ctx, secret); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete pull secret %s: %w", key, err)
+	}
+
+	return nil
+}
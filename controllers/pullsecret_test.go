@@ -0,0 +1,302 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/open-component-model/replication-controller/api/v1alpha1"
+	"github.com/open-component-model/replication-controller/pkg/ocm"
+)
+
+func TestReconcilePullSecrets(t *testing.T) {
+	owner := "default/test-component-subscription"
+
+	grant := func(namespace string) *v1alpha1.SecretReferenceGrant {
+		return &v1alpha1.SecretReferenceGrant{
+			ObjectMeta: metav1.ObjectMeta{Name: "pull-secrets", Namespace: namespace},
+			Spec: v1alpha1.SecretReferenceGrantSpec{
+				From:        []string{"default"},
+				SecretNames: []string{"pull-secret"},
+			},
+		}
+	}
+
+	destinationSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "destination-secret", Namespace: "default"},
+		Data: map[string][]byte{
+			"username": []byte("user"),
+			"password": []byte("pass"),
+		},
+	}
+
+	testCases := []struct {
+		name         string
+		subscription func() *v1alpha1.ComponentSubscription
+		objects      []client.Object
+		options      []ocm.ClientOption
+		expected     map[string]string
+		deleted      []types.NamespacedName
+		err          string
+	}{
+		{
+			name: "pull secret is created from the destination credentials",
+			subscription: func() *v1alpha1.ComponentSubscription {
+				cv := DefaultComponentSubscription.DeepCopy()
+				cv.Spec.Destination.URL = "https://destination.com/ocm"
+				cv.Spec.PullSecret = &v1alpha1.PullSecretPropagation{
+					Name:       "pull-secret",
+					Namespaces: []string{"app-1", "app-2"},
+				}
+				return cv
+			},
+			objects: []client.Object{destinationSecret, grant("app-1"), grant("app-2")},
+			expected: map[string]string{
+				"app-1/pull-secret": `{"auths":{"destination.com":{"username":"user","password":"pass","auth":"dXNlcjpwYXNz"}}}`,
+				"app-2/pull-secret": `{"auths":{"destination.com":{"username":"user","password":"pass","auth":"dXNlcjpwYXNz"}}}`,
+			},
+		},
+		{
+			name: "plain HTTP registries are keyed by host",
+			subscription: func() *v1alpha1.ComponentSubscription {
+				cv := DefaultComponentSubscription.DeepCopy()
+				cv.Spec.Destination.URL = "registry.local:5000/ocm"
+				cv.Spec.Destination.PlainHTTP = true
+				cv.Spec.PullSecret = &v1alpha1.PullSecretPropagation{
+					Name:       "pull-secret",
+					Namespaces: []string{"default"},
+				}
+				return cv
+			},
+			objects: []client.Object{destinationSecret},
+			expected: map[string]string{
+				"default/pull-secret": `{"auths":{"registry.local:5000":{"username":"user","password":"pass","auth":"dXNlcjpwYXNz"}}}`,
+			},
+		},
+		{
+			name: "namespaces must grant the pull secret",
+			subscription: func() *v1alpha1.ComponentSubscription {
+				cv := DefaultComponentSubscription.DeepCopy()
+				cv.Spec.PullSecret = &v1alpha1.PullSecretPropagation{
+					Name:       "pull-secret",
+					Namespaces: []string{"app-1", "kube-system"},
+				}
+				return cv
+			},
+			objects: []client.Object{destinationSecret, grant("app-1")},
+			err:     "secret kube-system/pull-secret is not granted to namespace default",
+		},
+		{
+			name: "cross-namespace pull secrets can be disabled",
+			subscription: func() *v1alpha1.ComponentSubscription {
+				cv := DefaultComponentSubscription.DeepCopy()
+				cv.Spec.PullSecret = &v1alpha1.PullSecretPropagation{
+					Name:       "pull-secret",
+					Namespaces: []string{"app-1"},
+				}
+				return cv
+			},
+			objects: []client.Object{destinationSecret, grant("app-1")},
+			options: []ocm.ClientOption{ocm.WithoutCrossNamespaceReferences()},
+			err:     "cross-namespace reference to secret app-1/pull-secret is not allowed",
+		},
+		{
+			name: "dockerconfigjson of a dedicated secret is copied",
+			subscription: func() *v1alpha1.ComponentSubscription {
+				cv := DefaultComponentSubscription.DeepCopy()
+				cv.Spec.PullSecret = &v1alpha1.PullSecretPropagation{
+					Name:       "pull-secret",
+					Namespaces: []string{"app-1"},
+					SecretRef:  &corev1.LocalObjectReference{Name: "pull-credentials"},
+				}
+				return cv
+			},
+			objects: []client.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "pull-credentials", Namespace: "default"},
+					Type:       corev1.SecretTypeDockerConfigJson,
+					Data: map[string][]byte{
+						corev1.DockerConfigJsonKey: []byte(`{"auths":{"destination.com":{"auth":"cmVhZDpvbmx5"}}}`),
+					},
+				},
+				grant("app-1"),
+			},
+			expected: map[string]string{
+				"app-1/pull-secret": `{"auths":{"destination.com":{"auth":"cmVhZDpvbmx5"}}}`,
+			},
+		},
+		{
+			name: "pull secrets of removed namespaces are deleted",
+			subscription: func() *v1alpha1.ComponentSubscription {
+				cv := DefaultComponentSubscription.DeepCopy()
+				cv.Spec.PullSecret = &v1alpha1.PullSecretPropagation{
+					Name:       "pull-secret",
+					Namespaces: []string{"app-1"},
+				}
+				cv.Status.PullSecrets = []string{"app-1/pull-secret", "app-2/pull-secret"}
+				return cv
+			},
+			objects: []client.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "destination-secret", Namespace: "default"},
+					Data: map[string][]byte{
+						"identityToken": []byte("token"),
+					},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "pull-secret",
+						Namespace:   "app-2",
+						Annotations: map[string]string{pullSecretAnnotation: owner},
+					},
+				},
+				grant("app-1"),
+			},
+			expected: map[string]string{
+				"app-1/pull-secret": `{"auths":{"destination.com":{"identitytoken":"token"}}}`,
+			},
+			deleted: []types.NamespacedName{{Namespace: "app-2", Name: "pull-secret"}},
+		},
+		{
+			name: "the finalizer is removed with the last pull secret",
+			subscription: func() *v1alpha1.ComponentSubscription {
+				cv := DefaultComponentSubscription.DeepCopy()
+				cv.Finalizers = []string{pullSecretFinalizer}
+				cv.Status.PullSecrets = []string{"app-1/pull-secret"}
+				return cv
+			},
+			objects: []client.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "pull-secret",
+						Namespace:   "app-1",
+						Annotations: map[string]string{pullSecretAnnotation: owner},
+					},
+				},
+			},
+			deleted: []types.NamespacedName{{Namespace: "app-1", Name: "pull-secret"}},
+		},
+		{
+			name: "unmanaged secrets are not overwritten",
+			subscription: func() *v1alpha1.ComponentSubscription {
+				cv := DefaultComponentSubscription.DeepCopy()
+				cv.Spec.PullSecret = &v1alpha1.PullSecretPropagation{
+					Name:       "pull-secret",
+					Namespaces: []string{"app-1"},
+				}
+				return cv
+			},
+			objects: []client.Object{
+				destinationSecret,
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "pull-secret", Namespace: "app-1"},
+				},
+				grant("app-1"),
+			},
+			err: "failed to write pull secret app-1/pull-secret: secret is not managed by subscription " + owner,
+		},
+		{
+			name: "pull secrets require credentials",
+			subscription: func() *v1alpha1.ComponentSubscription {
+				cv := DefaultComponentSubscription.DeepCopy()
+				cv.Spec.Destination.SecretRef = nil
+				cv.Spec.PullSecret = &v1alpha1.PullSecretPropagation{
+					Name:       "pull-secret",
+					Namespaces: []string{"app-1"},
+				}
+				return cv
+			},
+			err: "no credentials configured for https://destination.com",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			cv := tt.subscription()
+			client := env.FakeKubeClient(WithObjets(append(tt.objects, cv)...))
+
+			r := ComponentSubscriptionReconciler{
+				Scheme:    env.scheme,
+				Client:    client,
+				OCMClient: ocm.NewClient(client, tt.options...),
+			}
+
+			err := r.reconcilePullSecrets(context.Background(), cv)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+
+				return
+			}
+			require.NoError(t, err)
+
+			var propagated []string
+			for key, data := range tt.expected {
+				propagated = append(propagated, key)
+
+				secret := &corev1.Secret{}
+				namespace, name, _ := strings.Cut(key, "/")
+				require.NoError(t, client.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: name}, secret))
+				assert.Equal(t, corev1.SecretTypeDockerConfigJson, secret.Type)
+				assert.Equal(t, owner, secret.Annotations[pullSecretAnnotation])
+				assert.JSONEq(t, data, string(secret.Data[corev1.DockerConfigJsonKey]))
+			}
+			assert.ElementsMatch(t, propagated, cv.Status.PullSecrets)
+			assert.Equal(t, cv.Spec.PullSecret != nil, controllerutil.ContainsFinalizer(cv, pullSecretFinalizer))
+
+			for _, key := range tt.deleted {
+				err := client.Get(context.Background(), key, &corev1.Secret{})
+				assert.True(t, apierrors.IsNotFound(err))
+			}
+		})
+	}
+}
+
+func TestReconcileDeletePullSecrets(t *testing.T) {
+	owner := "default/test-component-subscription"
+
+	cv := DefaultComponentSubscription.DeepCopy()
+	cv.Finalizers = []string{pullSecretFinalizer}
+	cv.Status.PullSecrets = []string{"app-1/pull-secret", "app-2/pull-secret"}
+
+	client := env.FakeKubeClient(WithObjets(
+		cv,
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "pull-secret",
+				Namespace:   "app-1",
+				Annotations: map[string]string{pullSecretAnnotation: owner},
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "pull-secret", Namespace: "app-2"},
+		},
+	))
+	require.NoError(t, client.Delete(context.Background(), cv))
+
+	r := ComponentSubscriptionReconciler{
+		Scheme:    env.scheme,
+		Client:    client,
+		OCMClient: ocm.NewClient(client),
+	}
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: cv.Namespace, Name: cv.Name}})
+	require.NoError(t, err)
+
+	err = client.Get(context.Background(), types.NamespacedName{Namespace: "app-1", Name: "pull-secret"}, &corev1.Secret{})
+	assert.True(t, apierrors.IsNotFound(err), "managed pull secrets must be deleted with the subscription")
+	assert.NoError(t, client.Get(context.Background(), types.NamespacedName{Namespace: "app-2", Name: "pull-secret"}, &corev1.Secret{}),
+		"unmanaged secrets must be kept")
+
+	err = client.Get(context.Background(), types.NamespacedName{Namespace: cv.Namespace, Name: cv.Name}, cv)
+	assert.True(t, apierrors.IsNotFound(err), "the subscription must be released")
+}
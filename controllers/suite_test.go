@@ -57,13 +57,13 @@ var (
 			Interval: metav1.Duration{Duration: 10 * time.Second},
 			Source: v1alpha1.OCMRepository{
 				URL: "https://source.com",
-				SecretRef: &corev1.LocalObjectReference{
+				SecretRef: &corev1.SecretReference{
 					Name: "source-secret",
 				},
 			},
 			Destination: &v1alpha1.OCMRepository{
 				URL: "https://destination.com",
-				SecretRef: &corev1.LocalObjectReference{
+				SecretRef: &corev1.SecretReference{
 					Name: "destination-secret",
 				},
 			},
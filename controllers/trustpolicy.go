@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/open-component-model/replication-controller/api/v1alpha1"
+)
+
+const (
+	// trustPolicyKey indexes subscriptions by the names of the TrustPolicies they reference. TrustPolicies are
+	// cluster scoped, so the names aren't qualified with a namespace.
+	trustPolicyKey = ".spec.trustPolicies"
+	// trustPolicySecretKey indexes TrustPolicies by the Secrets they reference in the form `namespace/name`.
+	trustPolicySecretKey = ".spec.secretRef"
+)
+
+// indexTrustPolicies returns the names of the TrustPolicies referenced by a subscription.
+func indexTrustPolicies(rawObj client.Object) []string {
+	obj, ok := rawObj.(*v1alpha1.ComponentSubscription)
+	if !ok {
+		return []string{}
+	}
+
+	return obj.Spec.TrustPolicies
+}
+
+// indexTrustPolicySecrets returns the Secrets holding the public keys and root certificates of a TrustPolicy.
+func indexTrustPolicySecrets(rawObj client.Object) []string {
+	policy, ok := rawObj.(*v1alpha1.TrustPolicy)
+	if !ok {
+		return []string{}
+	}
+
+	var names []string
+	for _, signature := range policy.Spec.Verify {
+		if signature.PublicKey.SecretRef != nil {
+			names = append(names, fmt.Sprintf("%s/%s", policy.Spec.SecretNamespace, signature.PublicKey.SecretRef.Name))
+		}
+	}
+
+	for _, signature := range policy.Spec.VerifyCertificates {
+		names = append(names, fmt.Sprintf("%s/%s", policy.Spec.SecretNamespace, signature.RootCertificatesSecretRef.Name))
+	}
+
+	return names
+}
+
+// findTrustPolicyObjects finds the subscriptions a TrustPolicy applies to, i.e. those referencing it by name and those
+// replicating a matching component. Subscriptions verifying their references are included if one of the components
+// they last transferred or followed matches.
+func (r *ComponentSubscriptionReconciler) findTrustPolicyObjects(obj client.Object) []reconcile.Request {
+	policy, ok := obj.(*v1alpha1.TrustPolicy)
+	if !ok {
+		return []reconcile.Request{}
+	}
+
+	requestMap := make(map[reconcile.Request]struct{})
+
+	referencing := &v1alpha1.ComponentSubscriptionList{}
+	if err := r.List(context.Background(), referencing, client.MatchingFields{trustPolicyKey: policy.Name}); err != nil {
+		return []reconcile.Request{}
+	}
+
+	for i := range referencing.Items {
+		requestMap[reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&referencing.Items[i])}] = struct{}{}
+	}
+
+	list := &v1alpha1.ComponentSubscriptionList{}
+	if err := r.List(context.Background(), list); err != nil {
+		return []reconcile.Request{}
+	}
+
+	for i := range list.Items {
+		if item := &list.Items[i]; appliesTo(policy, item) {
+			requestMap[reconcile.Request{NamespacedName: client.ObjectKeyFromObject(item)}] = struct{}{}
+		}
+	}
+
+	requests := make([]reconcile.Request, 0, len(requestMap))
+	for request := range requestMap {
+		requests = append(requests, request)
+	}
+
+	return requests
+}
+
+// findTrustPolicySecretObjects finds the subscriptions affected by a Secret referenced by a TrustPolicy.
+func (r *ComponentSubscriptionReconciler) findTrustPolicySecretObjects(obj client.Object) []reconcile.Request {
+	policies := &v1alpha1.TrustPolicyList{}
+	if err := r.List(context.Background(), policies, client.MatchingFields{
+		trustPolicySecretKey: client.ObjectKeyFromObject(obj).String(),
+	}); err != nil {
+		return []reconcile.Request{}
+	}
+
+	var requests []reconcile.Request
+	for i := range policies.Items {
+		requests = append(requests, r.findTrustPolicyObjects(&policies.Items[i])...)
+	}
+
+	return requests
+}
+
+// appliesTo returns whether a TrustPolicy matches the component of a subscription, one of the referenced
+// components transferred with it, or one of the referenced components it verifies.
+func appliesTo(policy *v1alpha1.TrustPolicy, obj *v1alpha1.ComponentSubscription) bool {
+	if policy.Matches(obj.Spec.Component) {
+		return true
+	}
+
+	for _, reference := range obj.Status.References {
+		name, _, _ := strings.Cut(reference.Component, ":")
+		if policy.Matches(name) {
+			return true
+		}
+	}
+
+	if obj.Spec.VerifyReferences == nil {
+		return false
+	}
+
+	for _, component := range obj.Status.Components {
+		if policy.Matches(component.Name) {
+			return true
+		}
+	}
+
+	return false
+}
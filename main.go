@@ -1,8 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"fmt"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/open-component-model/replication-controller/pkg/ocm"
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
@@ -10,6 +16,7 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -18,6 +25,8 @@ import (
 
 	"github.com/open-component-model/replication-controller/api/v1alpha1"
 	"github.com/open-component-model/replication-controller/controllers"
+	"github.com/open-component-model/replication-controller/pkg/keys"
+	"github.com/open-component-model/replication-controller/pkg/sign"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -33,15 +42,68 @@ func init() {
 }
 
 func main() {
+	os.Exit(run())
+}
+
+// run sets up and starts the manager and returns the exit code. Deferred cleanups, e.g. closing the session of the
+// PKCS#11 signer, run before the process exits.
+func run() int {
 	var (
 		metricsAddr          string
 		enableLeaderElection bool
 		probeAddr            string
 		mpasEnabled          bool
+		signingCertSecret    string
+		keyRotationInterval  time.Duration
+		publicKeysAddr       string
+		publicKeysConfigMap  string
+		publicKeysRetained   int
+		signer               string
+		signerExecCommand    string
+		signerExecArgs       string
+		pkcs11Config         sign.PKCS11Config
+		pkcs11PinFile        string
+		ocmConfig            string
+		ocmConfigKind        string
+		noCrossNamespaceRefs bool
+		credentialHelpers    string
+		credentialHelperEnv  string
 	)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&mpasEnabled, "mpas-enabled", false, "If set to true every subscription must be an MPAS enabled component.")
+	flag.StringVar(&signingCertSecret, "signing-certificate-secret", "",
+		"A kubernetes.io/tls Secret in the form <namespace>/<name> used to sign replicated components with a "+
+			"certificate chain instead of a generated key pair.")
+	flag.DurationVar(&keyRotationInterval, "signing-key-rotation-interval", 30*24*time.Hour,
+		"The interval the generated signing key is replaced after. Never replaced if zero.")
+	flag.StringVar(&signer, "signer", "",
+		"The signer used for replicated components, either pkcs11 or exec. If empty, a generated key pair or the "+
+			"signing certificate secret is used.")
+	flag.StringVar(&signerExecCommand, "signer-exec-command", "", "The binary called by the exec signer.")
+	flag.StringVar(&signerExecArgs, "signer-exec-args", "",
+		"A comma separated list of arguments passed to the exec signer binary.")
+	flag.StringVar(&pkcs11Config.Module, "pkcs11-module", "", "The path to the PKCS#11 library used by the pkcs11 signer.")
+	flag.StringVar(&pkcs11Config.TokenLabel, "pkcs11-token-label", "", "The label of the PKCS#11 token holding the signing key.")
+	flag.StringVar(&pkcs11Config.KeyLabel, "pkcs11-key-label", "", "The label of the PKCS#11 key pair used for signing.")
+	flag.StringVar(&pkcs11PinFile, "pkcs11-pin-file", "", "A file containing the user PIN of the PKCS#11 token.")
+	flag.StringVar(&publicKeysAddr, "public-keys-bind-address", "",
+		"The address the endpoint publishing the public signing keys binds to. Disabled if empty.")
+	flag.StringVar(&publicKeysConfigMap, "public-keys-configmap", "",
+		"A ConfigMap in the form <namespace>/<name> the public signing keys are exported to.")
+	flag.IntVar(&publicKeysRetained, "public-keys-retained", keys.DefaultMaxKeys,
+		"The number of recently used public signing keys that are published.")
+	flag.StringVar(&ocmConfig, "default-ocm-config", "",
+		"A Secret or ConfigMap in the form <namespace>/<name> holding an OCM configuration under the .ocmconfig key, "+
+			"which is applied to every subscription.")
+	flag.StringVar(&ocmConfigKind, "default-ocm-config-kind", ocm.SecretKind,
+		"The kind of the default OCM configuration object, either Secret or ConfigMap.")
+	flag.BoolVar(&noCrossNamespaceRefs, "no-cross-namespace-refs", false,
+		"If set to true subscriptions can only reference Secrets of their own namespace, even if they are granted.")
+	flag.StringVar(&credentialHelpers, "credential-helpers", "",
+		"A comma-separated list of credential helper binaries subscriptions may run to obtain registry credentials.")
+	flag.StringVar(&credentialHelperEnv, "credential-helper-env", "",
+		"A comma-separated list of environment variables subscriptions may set for credential helpers.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -64,10 +126,114 @@ func main() {
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
-		os.Exit(1)
+		return 1
+	}
+
+	ocmOpts := []ocm.ClientOption{ocm.WithKeyRotation(keyRotationInterval)}
+	if signingCertSecret != "" {
+		key, err := parseNamespacedName(signingCertSecret)
+		if err != nil {
+			setupLog.Error(err, "invalid signing certificate secret")
+			return 1
+		}
+
+		ocmOpts = append(ocmOpts, ocm.WithSigningCertificateSecret(key))
+	}
+
+	switch signer {
+	case "":
+	case "pkcs11":
+		if pkcs11PinFile != "" {
+			pin, err := os.ReadFile(pkcs11PinFile)
+			if err != nil {
+				setupLog.Error(err, "unable to read PKCS#11 pin")
+				return 1
+			}
+
+			pkcs11Config.Pin = strings.TrimSpace(string(pin))
+		}
+
+		pkcs11Signer, err := sign.NewPKCS11Signer(pkcs11Config)
+		if err != nil {
+			setupLog.Error(err, "unable to create PKCS#11 signer")
+			return 1
+		}
+		defer pkcs11Signer.Close()
+
+		ocmOpts = append(ocmOpts, ocm.WithSigner(pkcs11Signer))
+	case "exec":
+		var execOpts []sign.ExecSignerOption
+		if signerExecArgs != "" {
+			execOpts = append(execOpts, sign.WithExecArgs(strings.Split(signerExecArgs, ",")...))
+		}
+
+		execSigner, err := sign.NewExecSigner(context.Background(), signerExecCommand, execOpts...)
+		if err != nil {
+			setupLog.Error(err, "unable to create exec signer")
+			return 1
+		}
+
+		ocmOpts = append(ocmOpts, ocm.WithSigner(execSigner))
+	default:
+		setupLog.Error(nil, "unknown signer", "signer", signer)
+		return 1
+	}
+
+	if publicKeysAddr != "" || publicKeysConfigMap != "" {
+		store := keys.NewStore(keys.WithMaxKeys(publicKeysRetained))
+		ocmOpts = append(ocmOpts, ocm.WithPublicKeyStore(store))
+
+		if publicKeysConfigMap != "" {
+			key, err := parseNamespacedName(publicKeysConfigMap)
+			if err != nil {
+				setupLog.Error(err, "invalid public keys config map")
+				return 1
+			}
+
+			if err := store.LoadConfigMap(context.Background(), mgr.GetAPIReader(), key); err != nil {
+				setupLog.Error(err, "unable to load public keys")
+				return 1
+			}
+
+			ocmOpts = append(ocmOpts, ocm.WithPublicKeyConfigMap(key))
+		}
+
+		if publicKeysAddr != "" {
+			if err := mgr.Add(&publicKeysServer{addr: publicKeysAddr, store: store}); err != nil {
+				setupLog.Error(err, "unable to set up public keys endpoint")
+				return 1
+			}
+		}
+	}
+
+	if ocmConfig != "" {
+		key, err := parseNamespacedName(ocmConfig)
+		if err != nil {
+			setupLog.Error(err, "invalid default ocm config")
+			return 1
+		}
+
+		if ocmConfigKind != ocm.SecretKind && ocmConfigKind != ocm.ConfigMapKind {
+			setupLog.Error(fmt.Errorf("unsupported kind %q", ocmConfigKind), "invalid default ocm config kind")
+			return 1
+		}
+
+		ocmOpts = append(ocmOpts, ocm.WithDefaultConfig(ocm.ConfigReference{Kind: ocmConfigKind, NamespacedName: key}))
+	}
+
+	if noCrossNamespaceRefs {
+		ocmOpts = append(ocmOpts, ocm.WithoutCrossNamespaceReferences())
 	}
 
-	ocmClient := ocm.NewClient(mgr.GetClient())
+	if credentialHelpers != "" {
+		ocmOpts = append(ocmOpts, ocm.WithCredentialHelpers(strings.Split(credentialHelpers, ",")...))
+	}
+
+	if credentialHelperEnv != "" {
+		ocmOpts = append(ocmOpts, ocm.WithCredentialHelperEnv(strings.Split(credentialHelperEnv, ",")...))
+	}
+
+	ocmClient := ocm.NewClient(mgr.GetClient(), ocmOpts...)
 	if err = (&controllers.ComponentSubscriptionReconciler{
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
@@ -76,22 +242,77 @@ func main() {
 		MpasEnabled:   mpasEnabled,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ComponentSubscription")
-		os.Exit(1)
+		return 1
 	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
-		os.Exit(1)
+		return 1
 	}
 	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up ready check")
-		os.Exit(1)
+		return 1
 	}
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
-		os.Exit(1)
+		return 1
+	}
+
+	return 0
+}
+
+// parseNamespacedName parses a reference in the form <namespace>/<name>.
+func parseNamespacedName(value string) (types.NamespacedName, error) {
+	namespace, name, ok := strings.Cut(value, "/")
+	if !ok || namespace == "" || name == "" {
+		return types.NamespacedName{}, fmt.Errorf("%q must be in the form <namespace>/<name>", value)
 	}
+
+	return types.NamespacedName{
+		Namespace: namespace,
+		Name:      name,
+	}, nil
+}
+
+// servePublicKeys serves the public signing keys until the context is cancelled.
+// publicKeysServer serves the public signing keys. It runs on every replica, not only the leader, so the keys
+// stay available behind a Service selecting all replicas.
+type publicKeysServer struct {
+	addr  string
+	store *keys.Store
+}
+
+// Start implements manager.Runnable.
+func (s *publicKeysServer) Start(ctx context.Context) error {
+	return servePublicKeys(ctx, s.addr, s.store)
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (s *publicKeysServer) NeedLeaderElection() bool {
+	return false
+}
+
+func servePublicKeys(ctx context.Context, addr string, store *keys.Store) error {
+	const readHeaderTimeout = 10 * time.Second
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           store.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = srv.Shutdown(context.Background())
+	}()
+
+	setupLog.Info("serving public keys", "address", addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve public keys: %w", err)
+	}
+
+	return nil
 }
@@ -0,0 +1,123 @@
+package attestation
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+
+	"github.com/open-component-model/replication-controller/api/v1alpha1"
+)
+
+const (
+	// StatementType is the in-toto statement type of replication attestations.
+	StatementType = "https://in-toto.io/Statement/v1"
+	// PredicateType identifies the replication predicate.
+	PredicateType = "https://ocm.software/attestations/replication/v1"
+	// PayloadType is the DSSE payload type of in-toto statements.
+	PayloadType = "application/vnd.in-toto+json"
+	// MediaType is the media type of the DSSE envelope stored in the destination repository.
+	MediaType = "application/vnd.dsse.envelope.v1+json"
+)
+
+// Statement is an in-toto statement attesting the replication of a component version.
+type Statement struct {
+	Type          string    `json:"_type"`
+	Subject       []Subject `json:"subject"`
+	PredicateType string    `json:"predicateType"`
+	Predicate     Predicate `json:"predicate"`
+}
+
+// Subject identifies the replicated component version.
+type Subject struct {
+	// Name is the component name and version in the form `name:version`.
+	Name string `json:"name"`
+	// Digest maps a hash algorithm to the digest of the normalised component descriptor.
+	Digest map[string]string `json:"digest"`
+}
+
+// Predicate describes how a component version was replicated.
+type Predicate struct {
+	Source        Repository                       `json:"source"`
+	Destination   Repository                       `json:"destination"`
+	Subscription  string                           `json:"subscription"`
+	Verifications []v1alpha1.SignatureVerification `json:"verifications,omitempty"`
+	Transfer      TransferOptions                  `json:"transfer"`
+	StartedOn     time.Time                        `json:"startedOn"`
+	FinishedOn    time.Time                        `json:"finishedOn"`
+	Controller    string                           `json:"controller"`
+}
+
+// Repository describes an OCM repository taking part in the replication.
+type Repository struct {
+	URL string `json:"url"`
+	// Digest is the digest of the normalised component descriptor in this repository.
+	Digest string `json:"digest,omitempty"`
+}
+
+// TransferOptions records the options the component version was transferred with.
+type TransferOptions struct {
+	Recursive        bool     `json:"recursive"`
+	ResourcesByValue bool     `json:"resourcesByValue"`
+	Overwrite        bool     `json:"overwrite"`
+	Platforms        []string `json:"platforms,omitempty"`
+}
+
+// NewStatement creates a replication statement for the given component version.
+func NewStatement(component, version, digest string, predicate Predicate) Statement {
+	return Statement{
+		Type: StatementType,
+		Subject: []Subject{
+			{
+				Name: component + ":" + version,
+				Digest: map[string]string{
+					"sha256": digest,
+				},
+			},
+		},
+		PredicateType: PredicateType,
+		Predicate:     predicate,
+	}
+}
+
+// Sign wraps the statement into a DSSE envelope signed by the signer. Signatures are RSASSA-PKCS1-V1_5 or ECDSA
+// signatures over the SHA-256 digest of the pre-authentication encoding of the payload.
+func Sign(ctx context.Context, statement Statement, signer crypto.Signer, keyID string) (*dsse.Envelope, error) {
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal statement: %w", err)
+	}
+
+	envelopeSigner, err := dsse.NewEnvelopeSigner(&dsseSigner{signer: signer, keyID: keyID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create envelope signer: %w", err)
+	}
+
+	envelope, err := envelopeSigner.SignPayload(ctx, PayloadType, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign statement: %w", err)
+	}
+
+	return envelope, nil
+}
+
+// dsseSigner adapts a crypto.Signer to the DSSE signer interface.
+type dsseSigner struct {
+	signer crypto.Signer
+	keyID  string
+}
+
+func (s *dsseSigner) Sign(_ context.Context, data []byte) ([]byte, error) {
+	digest := sha256.Sum256(data)
+
+	return s.signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
+
+func (s *dsseSigner) KeyID() (string, error) {
+	return s.keyID, nil
+}
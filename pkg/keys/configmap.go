@@ -0,0 +1,126 @@
+package keys
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// JWKSKey is the ConfigMap key holding all keys as JWKS.
+	JWKSKey = "jwks.json"
+	// PEMKey is the ConfigMap key holding all keys as concatenated PEM blocks.
+	PEMKey = "keys.pem"
+	// AddedKey is the ConfigMap key holding the times the keys became the current key, by key ID.
+	AddedKey = "added.json"
+)
+
+// ExportConfigMap writes the keys of the Store to the given ConfigMap, creating it if needed. Next to the
+// JWKS and the concatenated PEM blocks of all keys, every key is stored as PEM under `<id>.pem` and the times
+// the keys were added under AddedKey.
+func (s *Store) ExportConfigMap(ctx context.Context, c client.Client, key types.NamespacedName) error {
+	keys := s.Keys()
+
+	set, err := NewJWKS(keys...)
+	if err != nil {
+		return fmt.Errorf("failed to create JWKS: %w", err)
+	}
+
+	jwks, err := json.Marshal(set)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JWKS: %w", err)
+	}
+
+	times := make(map[string]time.Time, len(keys))
+	for _, k := range keys {
+		times[k.ID] = k.Added
+	}
+
+	added, err := json.Marshal(times)
+	if err != nil {
+		return fmt.Errorf("failed to marshal added times: %w", err)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+		},
+	}
+
+	if _, err := controllerutil.CreateOrUpdate(ctx, c, cm, func() error {
+		cm.Data = map[string]string{
+			JWKSKey:  string(jwks),
+			PEMKey:   string(concatPEM(keys...)),
+			AddedKey: string(added),
+		}
+
+		for _, k := range keys {
+			cm.Data[k.ID+".pem"] = string(k.PEM)
+		}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to export keys to config map %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// LoadConfigMap restores the keys previously exported to the given ConfigMap, so keys used before a restart
+// of the controller keep being published. Restored keys keep the time they were added, keys exported without
+// one are added now. A missing ConfigMap is not an error.
+func (s *Store) LoadConfigMap(ctx context.Context, c client.Reader, key types.NamespacedName) error {
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(ctx, key, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to get config map %s: %w", key, err)
+	}
+
+	set := JWKS{}
+	if data, ok := cm.Data[JWKSKey]; ok {
+		if err := json.Unmarshal([]byte(data), &set); err != nil {
+			return fmt.Errorf("failed to unmarshal JWKS in config map %s: %w", key, err)
+		}
+	}
+
+	times := map[string]time.Time{}
+	if data, ok := cm.Data[AddedKey]; ok {
+		if err := json.Unmarshal([]byte(data), &times); err != nil {
+			return fmt.Errorf("failed to unmarshal added times in config map %s: %w", key, err)
+		}
+	}
+
+	// keys are exported with the current one first, add the oldest first to restore the order.
+	for i := len(set.Keys) - 1; i >= 0; i-- {
+		data, ok := cm.Data[set.Keys[i].KeyID+".pem"]
+		if !ok {
+			continue
+		}
+
+		k, err := ParseKey([]byte(data))
+		if err != nil {
+			return fmt.Errorf("failed to load key %s from config map %s: %w", set.Keys[i].KeyID, key, err)
+		}
+
+		k.Added = times[k.ID]
+		if k.Added.IsZero() {
+			k.Added = s.now()
+		}
+
+		s.add(k)
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package keys
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+const (
+	pemContentType  = "application/x-pem-file"
+	jwksContentType = "application/jwk-set+json"
+)
+
+// Handler serves the keys of the Store. The following paths are supported:
+//
+//	/keys            all keys as JWKS
+//	/keys.pem        all keys as concatenated PEM blocks
+//	/keys/<id>       a single key as JWKS
+//	/keys/<id>.pem   a single key as PEM
+func (s *Store) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		writeJWKS(w, s.Keys()...)
+	})
+	mux.HandleFunc("/keys.pem", func(w http.ResponseWriter, r *http.Request) {
+		writePEM(w, s.Keys()...)
+	})
+	mux.HandleFunc("/keys/", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/keys/")
+		id, isPEM := strings.CutSuffix(id, ".pem")
+
+		key, ok := s.Get(id)
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		if isPEM {
+			writePEM(w, key)
+
+			return
+		}
+
+		writeJWKS(w, key)
+	})
+
+	return onlyGet(mux)
+}
+
+func onlyGet(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJWKS(w http.ResponseWriter, keys ...Key) {
+	set, err := NewJWKS(keys...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", jwksContentType)
+	_ = json.NewEncoder(w).Encode(set)
+}
+
+func writePEM(w http.ResponseWriter, keys ...Key) {
+	w.Header().Set("Content-Type", pemContentType)
+	_, _ = w.Write(concatPEM(keys...))
+}
+
+func concatPEM(keys ...Key) []byte {
+	var buf bytes.Buffer
+	for _, k := range keys {
+		buf.Write(k.PEM)
+		if !bytes.HasSuffix(k.PEM, []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+	}
+
+	return buf.Bytes()
+}
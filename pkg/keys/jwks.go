@@ -0,0 +1,80 @@
+package keys
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+)
+
+// JWK is the JSON Web Key representation of a public key as defined by RFC 7517.
+type JWK struct {
+	KeyType   string   `json:"kty"`
+	KeyID     string   `json:"kid"`
+	Use       string   `json:"use"`
+	Algorithm string   `json:"alg,omitempty"`
+	N         string   `json:"n,omitempty"`
+	E         string   `json:"e,omitempty"`
+	Curve     string   `json:"crv,omitempty"`
+	X         string   `json:"x,omitempty"`
+	Y         string   `json:"y,omitempty"`
+	X5C       []string `json:"x5c,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set as defined by RFC 7517.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWKS creates a key set from the given keys.
+func NewJWKS(keys ...Key) (JWKS, error) {
+	set := JWKS{
+		Keys: make([]JWK, 0, len(keys)),
+	}
+
+	for _, k := range keys {
+		jwk, err := k.JWK()
+		if err != nil {
+			return JWKS{}, err
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set, nil
+}
+
+// JWK returns the JSON Web Key representation of the key.
+func (k Key) JWK() (JWK, error) {
+	jwk := JWK{
+		KeyID: k.ID,
+		Use:   "sig",
+	}
+
+	switch pub := k.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.Algorithm = "RS256"
+		jwk.N = encode(pub.N)
+		jwk.E = encode(big.NewInt(int64(pub.E)))
+	case *ecdsa.PublicKey:
+		jwk.KeyType = "EC"
+		jwk.Curve = pub.Curve.Params().Name
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, size)))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, size)))
+	default:
+		return JWK{}, fmt.Errorf("unsupported public key type %T", k.PublicKey)
+	}
+
+	for _, cert := range k.Certificates {
+		jwk.X5C = append(jwk.X5C, base64.StdEncoding.EncodeToString(cert.Raw))
+	}
+
+	return jwk, nil
+}
+
+func encode(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
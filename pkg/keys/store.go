@@ -0,0 +1,202 @@
+package keys
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultMaxKeys is the number of public keys retained by a Store unless configured otherwise.
+const DefaultMaxKeys = 10
+
+// Key is a public key used by the controller to sign components.
+type Key struct {
+	// ID identifies the key. It is the hex encoded SHA-256 digest of the DER encoded public key.
+	ID string
+	// PublicKey is the parsed public key.
+	PublicKey crypto.PublicKey
+	// PEM contains the key as it was handed out to consumers, either a public key or a certificate chain.
+	PEM []byte
+	// Certificates contains the certificate chain if the key was issued by a certificate authority.
+	Certificates []*x509.Certificate
+	// Added is the time the key became the current key.
+	Added time.Time
+}
+
+// Store keeps the public keys recently used for signing. The newest key is the current one, older keys are
+// retained until the configured maximum is exceeded, so consumers can still verify components signed before a
+// key has been rotated.
+type Store struct {
+	mu      sync.RWMutex
+	keys    []Key
+	maxKeys int
+	now     func() time.Time
+}
+
+// StoreOption configures optional behaviour of the Store.
+type StoreOption func(s *Store)
+
+// WithMaxKeys configures the number of public keys the Store retains.
+func WithMaxKeys(n int) StoreOption {
+	return func(s *Store) {
+		s.maxKeys = n
+	}
+}
+
+// NewStore creates an empty Store.
+func NewStore(opts ...StoreOption) *Store {
+	s := &Store{
+		maxKeys: DefaultMaxKeys,
+		now:     time.Now,
+	}
+
+	for _, o := range opts {
+		o(s)
+	}
+
+	return s
+}
+
+// Add parses a PEM encoded public key or certificate chain and records it as the current key. Adding a key
+// that is already known marks it as current again. It returns the ID of the key.
+func (s *Store) Add(data []byte) (string, error) {
+	key, err := ParseKey(data)
+	if err != nil {
+		return "", err
+	}
+
+	key.Added = s.now()
+	s.add(key)
+
+	return key.ID, nil
+}
+
+// add records the key as the current key.
+func (s *Store) add(key Key) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys = append([]Key{key}, s.remove(key.ID)...)
+	if s.maxKeys > 0 && len(s.keys) > s.maxKeys {
+		s.keys = s.keys[:s.maxKeys]
+	}
+}
+
+// Current returns the current key, if any.
+func (s *Store) Current() (Key, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.keys) == 0 {
+		return Key{}, false
+	}
+
+	return s.keys[0], true
+}
+
+// Keys returns the retained keys, the current one first.
+func (s *Store) Keys() []Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]Key(nil), s.keys...)
+}
+
+// Get returns the key with the given ID.
+func (s *Store) Get(id string) (Key, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, k := range s.keys {
+		if k.ID == id {
+			return k, true
+		}
+	}
+
+	return Key{}, false
+}
+
+// remove returns the keys without the key with the given ID. The caller must hold the lock.
+func (s *Store) remove(id string) []Key {
+	result := make([]Key, 0, len(s.keys))
+	for _, k := range s.keys {
+		if k.ID != id {
+			result = append(result, k)
+		}
+	}
+
+	return result
+}
+
+// ParseKey parses a PEM encoded public key or certificate chain. For certificate chains the public key of
+// the first certificate is used.
+func ParseKey(data []byte) (Key, error) {
+	block, rest := pem.Decode(data)
+	if block == nil {
+		return Key{}, fmt.Errorf("failed to decode PEM data")
+	}
+
+	key := Key{
+		PEM: data,
+	}
+
+	var err error
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		key.PublicKey, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		key.PublicKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "CERTIFICATE":
+		key.Certificates, err = parseCertificates(block, rest)
+		if err == nil {
+			key.PublicKey = key.Certificates[0].PublicKey
+		}
+	default:
+		return Key{}, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to parse %s: %w", block.Type, err)
+	}
+
+	switch key.PublicKey.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+	default:
+		return Key{}, fmt.Errorf("unsupported public key type %T", key.PublicKey)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(key.PublicKey)
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to marshal public key: %w", err)
+	}
+
+	digest := sha256.Sum256(der)
+	key.ID = hex.EncodeToString(digest[:])
+
+	return key, nil
+}
+
+func parseCertificates(block *pem.Block, rest []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for block != nil {
+		if block.Type == "CERTIFICATE" {
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+
+			certs = append(certs, cert)
+		}
+
+		block, rest = pem.Decode(rest)
+	}
+
+	return certs, nil
+}
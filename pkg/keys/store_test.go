@@ -0,0 +1,190 @@
+package keys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func generatePublicKey(t *testing.T) []byte {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PUBLIC KEY",
+		Bytes: x509.MarshalPKCS1PublicKey(&key.PublicKey),
+	})
+}
+
+func TestStore_Add(t *testing.T) {
+	store := NewStore(WithMaxKeys(2))
+
+	first, err := store.Add(generatePublicKey(t))
+	require.NoError(t, err)
+	second, err := store.Add(generatePublicKey(t))
+	require.NoError(t, err)
+	third, err := store.Add(generatePublicKey(t))
+	require.NoError(t, err)
+
+	ids := func() []string {
+		var result []string
+		for _, k := range store.Keys() {
+			result = append(result, k.ID)
+		}
+
+		return result
+	}
+	assert.Equal(t, []string{third, second}, ids(), "the oldest key should have been rotated out")
+
+	current, ok := store.Current()
+	require.True(t, ok)
+	assert.Equal(t, third, current.ID)
+
+	_, ok = store.Get(first)
+	assert.False(t, ok)
+
+	_, err = store.Add([]byte(second))
+	assert.Error(t, err)
+
+	pub, ok := store.Get(second)
+	require.True(t, ok)
+	_, err = store.Add(pub.PEM)
+	require.NoError(t, err)
+	assert.Equal(t, []string{second, third}, ids(), "re-adding a key should make it the current one")
+}
+
+func TestStore_Handler(t *testing.T) {
+	store := NewStore()
+	pub := generatePublicKey(t)
+	id, err := store.Add(pub)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(store.Handler())
+	defer srv.Close()
+
+	testCases := []struct {
+		name        string
+		path        string
+		status      int
+		contentType string
+		verify      func(t *testing.T, body []byte)
+	}{
+		{
+			name:        "all keys as jwks",
+			path:        "/keys",
+			status:      http.StatusOK,
+			contentType: jwksContentType,
+			verify: func(t *testing.T, body []byte) {
+				set := JWKS{}
+				require.NoError(t, json.Unmarshal(body, &set))
+				require.Len(t, set.Keys, 1)
+				assert.Equal(t, id, set.Keys[0].KeyID)
+				assert.Equal(t, "RSA", set.Keys[0].KeyType)
+				assert.Equal(t, "AQAB", set.Keys[0].E)
+			},
+		},
+		{
+			name:        "all keys as pem",
+			path:        "/keys.pem",
+			status:      http.StatusOK,
+			contentType: pemContentType,
+			verify: func(t *testing.T, body []byte) {
+				assert.Equal(t, pub, body)
+			},
+		},
+		{
+			name:        "single key as pem",
+			path:        "/keys/" + id + ".pem",
+			status:      http.StatusOK,
+			contentType: pemContentType,
+			verify: func(t *testing.T, body []byte) {
+				assert.Equal(t, pub, body)
+			},
+		},
+		{
+			name:        "single key as jwks",
+			path:        "/keys/" + id,
+			status:      http.StatusOK,
+			contentType: jwksContentType,
+		},
+		{
+			name:   "unknown key",
+			path:   "/keys/unknown.pem",
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(srv.URL + tt.path)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			if tt.contentType != "" {
+				assert.Equal(t, tt.contentType, resp.Header.Get("Content-Type"))
+			}
+
+			if tt.verify != nil {
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				tt.verify(t, body)
+			}
+		})
+	}
+}
+
+func TestStore_ConfigMap(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	key := types.NamespacedName{Namespace: "ocm-system", Name: "replication-public-keys"}
+
+	added := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewStore()
+	store.now = func() time.Time { return added }
+	older, err := store.Add(generatePublicKey(t))
+	require.NoError(t, err)
+	store.now = func() time.Time { return added.Add(time.Hour) }
+	current, err := store.Add(generatePublicKey(t))
+	require.NoError(t, err)
+
+	require.NoError(t, store.ExportConfigMap(context.Background(), fakeClient, key))
+
+	cm := &corev1.ConfigMap{}
+	require.NoError(t, fakeClient.Get(context.Background(), key, cm))
+	assert.Contains(t, cm.Data, JWKSKey)
+	assert.Contains(t, cm.Data, PEMKey)
+	assert.Contains(t, cm.Data, AddedKey)
+	assert.Contains(t, cm.Data, older+".pem")
+	assert.Contains(t, cm.Data, current+".pem")
+
+	restored := NewStore()
+	require.NoError(t, restored.LoadConfigMap(context.Background(), fakeClient, key))
+	require.Len(t, restored.Keys(), 2)
+	assert.Equal(t, current, restored.Keys()[0].ID)
+	assert.Equal(t, older, restored.Keys()[1].ID)
+	assert.True(t, added.Add(time.Hour).Equal(restored.Keys()[0].Added))
+	assert.True(t, added.Equal(restored.Keys()[1].Added))
+
+	missing := NewStore()
+	require.NoError(t, missing.LoadConfigMap(context.Background(), fakeClient, types.NamespacedName{Namespace: "ocm-system", Name: "missing"}))
+	assert.Empty(t, missing.Keys())
+}
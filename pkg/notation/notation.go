@@ -0,0 +1,246 @@
+package notation
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/notaryproject/notation-core-go/signature/cose"
+	"github.com/notaryproject/notation-core-go/signature/jws"
+	notary "github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/verifier"
+	"github.com/notaryproject/notation-go/verifier/trustpolicy"
+	"github.com/notaryproject/notation-go/verifier/truststore"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// ArtifactType is the artifact type of Notary Project signature manifests.
+	ArtifactType = "application/vnd.cncf.notary.signature"
+	// MediaTypeJWS is the media type of JWS signature envelopes.
+	MediaTypeJWS = jws.MediaTypeEnvelope
+	// MediaTypeCOSE is the media type of COSE signature envelopes.
+	MediaTypeCOSE = cose.MediaTypeEnvelope
+	// MediaTypePayload is the content type of the signed payload.
+	MediaTypePayload = "application/vnd.cncf.notary.payload.v1+json"
+
+	// trustPolicyName is the name of the single trust policy statement of a Verifier.
+	trustPolicyName = "replication"
+)
+
+// ErrNoSignature is returned if an image does not carry any Notary Project signature.
+var ErrNoSignature = errors.New("no notation signature found")
+
+// Signature describes a verified signature of an image.
+type Signature struct {
+	// Digest is the digest of the signature manifest.
+	Digest string
+	// Subject is the distinguished name of the signing certificate.
+	Subject string
+	// SigningTime is the time the signature claims to have been created at.
+	SigningTime time.Time
+}
+
+// TrustStores maps the names of trust stores to the root certificates they contain. It implements the trust store of
+// Notary Project CA trust stores referenced by the trust policy of a Verifier.
+type TrustStores map[string][]*x509.Certificate
+
+// GetCertificates returns the certificates of the named CA trust store.
+func (s TrustStores) GetCertificates(_ context.Context, storeType truststore.Type, namedStore string) ([]*x509.Certificate, error) {
+	if storeType != truststore.TypeCA {
+		return nil, fmt.Errorf("unsupported trust store type %s", storeType)
+	}
+
+	certs, ok := s[namedStore]
+	if !ok {
+		return nil, fmt.Errorf("trust store %s not found", namedStore)
+	}
+
+	return certs, nil
+}
+
+// Verifier verifies Notary Project signatures stored as OCI referrers of an image. Signature envelopes are verified
+// by the notation library against a strict trust policy that applies to all registries.
+type Verifier struct {
+	verifier notary.Verifier
+}
+
+// NewVerifier creates a Verifier accepting signatures whose certificate chain is rooted in one of the trust stores.
+// The trusted identities restrict the signing certificates using the syntax of Notary Project trust policies, e.g.
+// `x509.subject: C=DE, ST=BW, O=acme`. Any signing certificate is accepted if no identities are given.
+func NewVerifier(stores TrustStores, trustedIdentities []string) (*Verifier, error) {
+	names := make([]string, 0, len(stores))
+	for store := range stores {
+		names = append(names, string(truststore.TypeCA)+":"+store)
+	}
+	sort.Strings(names)
+
+	if len(trustedIdentities) == 0 {
+		trustedIdentities = []string{"*"}
+	}
+
+	policy := &trustpolicy.Document{
+		Version: "1.0",
+		TrustPolicies: []trustpolicy.TrustPolicy{
+			{
+				Name:           trustPolicyName,
+				RegistryScopes: []string{"*"},
+				SignatureVerification: trustpolicy.SignatureVerification{
+					VerificationLevel: trustpolicy.LevelStrict.Name,
+				},
+				TrustStores:       names,
+				TrustedIdentities: trustedIdentities,
+			},
+		},
+	}
+
+	v, err := verifier.New(policy, stores, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trust policy: %w", err)
+	}
+
+	return &Verifier{verifier: v}, nil
+}
+
+// Verify looks up the signatures of the image and returns the first one that can be verified. An error is returned
+// if none of them can be verified.
+func (v *Verifier) Verify(ctx context.Context, image name.Digest, opts ...remote.Option) (Signature, error) {
+	opts = append(opts, remote.WithContext(ctx))
+
+	target, err := remote.Head(image, opts...)
+	if err != nil {
+		return Signature{}, fmt.Errorf("failed to get image %s: %w", image, err)
+	}
+
+	signatures, err := referrers(image, opts...)
+	if err != nil {
+		return Signature{}, err
+	}
+
+	if len(signatures) == 0 {
+		return Signature{}, fmt.Errorf("%w for %s", ErrNoSignature, image)
+	}
+
+	var errs error
+	for _, desc := range signatures {
+		signature, err := v.verifyManifest(ctx, image, *target, desc, opts...)
+		if err == nil {
+			return signature, nil
+		}
+
+		errs = errors.Join(errs, fmt.Errorf("signature %s: %w", desc.Digest, err))
+	}
+
+	return Signature{}, fmt.Errorf("failed to verify any signature of %s: %w", image, errs)
+}
+
+func (v *Verifier) verifyManifest(ctx context.Context, image name.Digest, target, desc v1.Descriptor, opts ...remote.Option) (Signature, error) {
+	img, err := remote.Image(image.Context().Digest(desc.Digest.String()), opts...)
+	if err != nil {
+		return Signature{}, fmt.Errorf("failed to get signature manifest: %w", err)
+	}
+
+	layers, err := img.Layers()
+	if err != nil {
+		return Signature{}, fmt.Errorf("failed to get signature envelope: %w", err)
+	}
+
+	if len(layers) != 1 {
+		return Signature{}, fmt.Errorf("expected a single signature envelope, found %d", len(layers))
+	}
+
+	mediaType, err := layers[0].MediaType()
+	if err != nil {
+		return Signature{}, fmt.Errorf("failed to get envelope media type: %w", err)
+	}
+
+	reader, err := layers[0].Compressed()
+	if err != nil {
+		return Signature{}, fmt.Errorf("failed to fetch signature envelope: %w", err)
+	}
+	defer reader.Close()
+
+	envelope, err := io.ReadAll(reader)
+	if err != nil {
+		return Signature{}, fmt.Errorf("failed to read signature envelope: %w", err)
+	}
+
+	outcome, err := v.verifier.Verify(ctx, ocispec.Descriptor{
+		MediaType: string(target.MediaType),
+		Digest:    digest.Digest(target.Digest.String()),
+		Size:      target.Size,
+	}, envelope, notary.VerifierVerifyOptions{
+		ArtifactReference:  image.String(),
+		SignatureMediaType: string(mediaType),
+	})
+	if err != nil {
+		return Signature{}, err
+	}
+
+	signer := outcome.EnvelopeContent.SignerInfo
+
+	return Signature{
+		Digest:      desc.Digest.String(),
+		Subject:     signer.CertificateChain[0].Subject.String(),
+		SigningTime: signer.SignedAttributes.SigningTime,
+	}, nil
+}
+
+// referrers returns the descriptors of the signature manifests referring to the image.
+func referrers(image name.Digest, opts ...remote.Option) ([]v1.Descriptor, error) {
+	index, err := remote.Referrers(image, append(opts, remote.WithFilter("artifactType", ArtifactType))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list referrers of %s: %w", image, err)
+	}
+
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get referrers of %s: %w", image, err)
+	}
+
+	// registries ignoring the filter return all referrers.
+	result := make([]v1.Descriptor, 0, len(manifest.Manifests))
+	for _, desc := range manifest.Manifests {
+		if desc.ArtifactType == ArtifactType {
+			result = append(result, desc)
+		}
+	}
+
+	return result, nil
+}
+
+// CopySignatures copies the signatures of the source image to the destination image, which must be a copy of the
+// source, and returns the number of copied signatures.
+func CopySignatures(ctx context.Context, source, destination name.Digest, opts ...remote.Option) (int, error) {
+	if source.DigestStr() != destination.DigestStr() {
+		return 0, fmt.Errorf("destination %s is not a copy of %s", destination, source)
+	}
+
+	opts = append(opts, remote.WithContext(ctx))
+
+	signatures, err := referrers(source, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, desc := range signatures {
+		img, err := remote.Image(source.Context().Digest(desc.Digest.String()), opts...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get signature %s: %w", desc.Digest, err)
+		}
+
+		if err := remote.Write(destination.Context().Digest(desc.Digest.String()), img, opts...); err != nil {
+			return 0, fmt.Errorf("failed to copy signature %s: %w", desc.Digest, err)
+		}
+	}
+
+	return len(signatures), nil
+}
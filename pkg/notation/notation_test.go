@@ -0,0 +1,278 @@
+package notation
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/distribution/distribution/v3/configuration"
+	"github.com/distribution/distribution/v3/registry/handlers"
+	_ "github.com/distribution/distribution/v3/registry/storage/driver/inmemory"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/notaryproject/notation-core-go/signature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCA issues code signing certificates.
+type testCA struct {
+	cert *x509.Certificate
+	key  *rsa.PrivateKey
+}
+
+func newTestCA(t *testing.T, cn string) *testCA {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) stores() TrustStores {
+	return TrustStores{"roots": {ca.cert}}
+}
+
+// sign creates a signature envelope of the given media type for the target descriptor with a certificate issued
+// for subject.
+func (ca *testCA) sign(t *testing.T, mediaType string, subject pkix.Name, target v1.Descriptor) []byte {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	signer, err := signature.NewLocalSigner([]*x509.Certificate{cert, ca.cert}, key)
+	require.NoError(t, err)
+
+	payload, err := json.Marshal(map[string]any{"targetArtifact": target})
+	require.NoError(t, err)
+
+	env, err := signature.NewEnvelope(mediaType)
+	require.NoError(t, err)
+	envelope, err := env.Sign(&signature.SignRequest{
+		Payload:       signature.Payload{ContentType: MediaTypePayload, Content: payload},
+		Signer:        signer,
+		SigningTime:   time.Now(),
+		SigningScheme: signature.SigningSchemeX509,
+		SigningAgent:  "replication-controller-test",
+	})
+	require.NoError(t, err)
+
+	return envelope
+}
+
+// pushSignature attaches the signature envelope of the given media type to the image as a referrer.
+func pushSignature(t *testing.T, image name.Digest, target v1.Descriptor, mediaType string, envelope []byte) {
+	t.Helper()
+
+	img, err := mutate.Append(empty.Image, mutate.Addendum{Layer: static.NewLayer(envelope, types.MediaType(mediaType))})
+	require.NoError(t, err)
+	img = mutate.MediaType(img, types.OCIManifestSchema1)
+	img = mutate.ConfigMediaType(img, ArtifactType)
+	img, ok := mutate.Subject(img, target).(v1.Image)
+	require.True(t, ok)
+
+	digest, err := img.Digest()
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(image.Context().Digest(digest.String()), img))
+}
+
+func pushImage(t *testing.T, repository string) (name.Digest, v1.Descriptor) {
+	t.Helper()
+
+	img, err := random.Image(64, 1)
+	require.NoError(t, err)
+	img = mutate.MediaType(img, types.OCIManifestSchema1)
+
+	ref, err := name.ParseReference(repository + ":v1")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(ref, img))
+
+	desc, err := remote.Head(ref)
+	require.NoError(t, err)
+
+	return ref.Context().Digest(desc.Digest.String()), *desc
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	registry := httptest.NewServer(handlers.NewApp(context.Background(), &configuration.Configuration{
+		Storage: configuration.Storage{"inmemory": configuration.Parameters{}},
+	}))
+	defer registry.Close()
+	host := strings.TrimPrefix(registry.URL, "http://")
+
+	ca := newTestCA(t, "acme root")
+	other := newTestCA(t, "other root")
+
+	release := pkix.Name{
+		CommonName:   "release",
+		Organization: []string{"acme"},
+		Province:     []string{"BW"},
+		Country:      []string{"DE"},
+	}
+
+	signed, signedDesc := pushImage(t, host+"/acme/signed")
+	pushSignature(t, signed, signedDesc, MediaTypeJWS, ca.sign(t, MediaTypeJWS, release, signedDesc))
+
+	cose, coseDesc := pushImage(t, host+"/acme/cose")
+	pushSignature(t, cose, coseDesc, MediaTypeCOSE, ca.sign(t, MediaTypeCOSE, release, coseDesc))
+
+	unsigned, _ := pushImage(t, host+"/acme/unsigned")
+
+	wrongTarget, wrongTargetDesc := pushImage(t, host+"/acme/wrong-target")
+	pushSignature(t, wrongTarget, wrongTargetDesc, MediaTypeJWS, ca.sign(t, MediaTypeJWS, release, signedDesc))
+
+	testCases := []struct {
+		name       string
+		image      name.Digest
+		stores     TrustStores
+		identities []string
+		subject    string
+		err        string
+	}{
+		{
+			name:    "signature rooted in the trust store is verified",
+			image:   signed,
+			stores:  ca.stores(),
+			subject: "CN=release,O=acme,ST=BW,C=DE",
+		},
+		{
+			name:    "COSE signature is verified",
+			image:   cose,
+			stores:  ca.stores(),
+			subject: "CN=release,O=acme,ST=BW,C=DE",
+		},
+		{
+			name:       "signature matching a trusted identity is verified",
+			image:      signed,
+			stores:     ca.stores(),
+			identities: []string{"x509.subject: C=DE, ST=BW, O=acme"},
+			subject:    "CN=release,O=acme,ST=BW,C=DE",
+		},
+		{
+			name:       "signature not matching the trusted identities is rejected",
+			image:      signed,
+			stores:     ca.stores(),
+			identities: []string{"x509.subject: C=DE, ST=BW, O=evil"},
+			err:        "does not match the X.509 trusted identities",
+		},
+		{
+			name:   "signature rooted in another certificate is rejected",
+			image:  signed,
+			stores: other.stores(),
+			err:    "does not contain any trusted certificate",
+		},
+		{
+			name:   "image without signature is rejected",
+			image:  unsigned,
+			stores: ca.stores(),
+			err:    ErrNoSignature.Error(),
+		},
+		{
+			name:   "signature created for another image is rejected",
+			image:  wrongTarget,
+			stores: ca.stores(),
+			err:    "content descriptor mismatch",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier, err := NewVerifier(tt.stores, tt.identities)
+			require.NoError(t, err)
+
+			signature, err := verifier.Verify(context.Background(), tt.image)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.subject, signature.Subject)
+			assert.NotEmpty(t, signature.Digest)
+		})
+	}
+}
+
+func TestNewVerifier(t *testing.T) {
+	ca := newTestCA(t, "acme root")
+
+	_, err := NewVerifier(nil, nil)
+	assert.Error(t, err, "a trust store is required")
+
+	_, err = NewVerifier(ca.stores(), []string{"x509.subject: CN=release"})
+	assert.ErrorContains(t, err, "invalid trust policy")
+}
+
+func TestCopySignatures(t *testing.T) {
+	registry := httptest.NewServer(handlers.NewApp(context.Background(), &configuration.Configuration{
+		Storage: configuration.Storage{"inmemory": configuration.Parameters{}},
+	}))
+	defer registry.Close()
+	host := strings.TrimPrefix(registry.URL, "http://")
+
+	ca := newTestCA(t, "acme root")
+	source, desc := pushImage(t, host+"/source/app")
+	pushSignature(t, source, desc, MediaTypeJWS, ca.sign(t, MediaTypeJWS, pkix.Name{CommonName: "release"}, desc))
+
+	img, err := remote.Image(source)
+	require.NoError(t, err)
+	destination, err := name.NewDigest(host + "/destination/app@" + desc.Digest.String())
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(destination, img))
+
+	verifier, err := NewVerifier(ca.stores(), nil)
+	require.NoError(t, err)
+
+	_, err = verifier.Verify(context.Background(), destination)
+	require.ErrorIs(t, err, ErrNoSignature)
+
+	n, err := CopySignatures(context.Background(), source, destination)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = verifier.Verify(context.Background(), destination)
+	assert.NoError(t, err)
+}
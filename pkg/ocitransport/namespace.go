@@ -0,0 +1,276 @@
+package ocitransport
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/log"
+	"github.com/open-component-model/ocm/pkg/blobaccess"
+	"github.com/open-component-model/ocm/pkg/common"
+	"github.com/open-component-model/ocm/pkg/common/accessio"
+	oci_repository_prepare "github.com/open-component-model/ocm/pkg/contexts/oci/actions/oci-repository-prepare"
+	"github.com/open-component-model/ocm/pkg/contexts/oci/artdesc"
+	"github.com/open-component-model/ocm/pkg/contexts/oci/cpi"
+	"github.com/open-component-model/ocm/pkg/contexts/oci/cpi/support"
+	"github.com/open-component-model/ocm/pkg/contexts/oci/repositories/ocireg"
+	"github.com/open-component-model/ocm/pkg/docker/resolve"
+	"github.com/open-component-model/ocm/pkg/errors"
+	"github.com/opencontainers/go-digest"
+	"github.com/sirupsen/logrus"
+)
+
+// NamespaceContainer implements a namespace, i.e. an OCI repository, of a registry.
+type NamespaceContainer struct {
+	impl     support.NamespaceAccessImpl
+	repo     *RepositoryImpl
+	resolver resolve.Resolver
+	lister   resolve.Lister
+	fetcher  resolve.Fetcher
+	blobs    *ocireg.BlobContainers
+	checked  bool
+}
+
+var _ support.NamespaceContainer = (*NamespaceContainer)(nil)
+
+// NewNamespace creates the namespace with the given name in the repository.
+func NewNamespace(repo *RepositoryImpl, name string) (cpi.NamespaceAccess, error) {
+	ref := repo.GetRef(name, "")
+
+	resolver, err := repo.getResolver(name)
+	if err != nil {
+		return nil, err
+	}
+
+	fetcher, err := resolver.Fetcher(context.Background(), ref)
+	if err != nil {
+		return nil, err
+	}
+
+	pusher, err := resolver.Pusher(context.Background(), ref)
+	if err != nil {
+		return nil, err
+	}
+
+	lister, err := resolver.Lister(context.Background(), ref)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &NamespaceContainer{
+		repo:     repo,
+		resolver: resolver,
+		lister:   lister,
+		fetcher:  fetcher,
+		blobs:    ocireg.NewBlobContainers(repo.GetContext(), fetcher, pusher),
+	}
+
+	return support.NewNamespaceAccess(name, c, repo)
+}
+
+func (n *NamespaceContainer) Close() error {
+	return n.blobs.Release()
+}
+
+func (n *NamespaceContainer) SetImplementation(impl support.NamespaceAccessImpl) {
+	n.impl = impl
+}
+
+func (n *NamespaceContainer) getPusher(vers string) (resolve.Pusher, error) {
+	if err := n.assureCreated(); err != nil {
+		return nil, err
+	}
+
+	ref := n.repo.GetRef(n.impl.GetNamespace(), vers)
+	resolver := n.resolver
+
+	if ok, _ := artdesc.IsDigest(vers); !ok {
+		var err error
+		if resolver, err = n.repo.getResolver(n.impl.GetNamespace()); err != nil {
+			return nil, fmt.Errorf("unable get resolver: %w", err)
+		}
+	}
+
+	return resolver.Pusher(dummyContext, ref)
+}
+
+func (n *NamespaceContainer) push(vers string, blob cpi.BlobAccess) error {
+	p, err := n.getPusher(vers)
+	if err != nil {
+		return fmt.Errorf("unable to get pusher: %w", err)
+	}
+
+	desc := *artdesc.DefaultBlobDescriptor(blob)
+	if desc.Size == 0 {
+		desc.Size = -1
+	}
+
+	req, err := p.Push(dummyContext, desc, blob)
+	if err != nil {
+		if errdefs.IsAlreadyExists(err) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to push: %w", err)
+	}
+
+	return req.Commit(dummyContext, desc.Size, desc.Digest)
+}
+
+func (n *NamespaceContainer) IsReadOnly() bool {
+	return n.repo.IsReadOnly()
+}
+
+func (n *NamespaceContainer) GetBlobDescriptor(digest.Digest) *cpi.Descriptor {
+	return nil
+}
+
+func (n *NamespaceContainer) GetBlobData(digest digest.Digest) (int64, cpi.DataAccess, error) {
+	blob, err := n.blobs.Get("")
+	if err != nil {
+		return -1, nil, fmt.Errorf("failed to retrieve blob data: %w", err)
+	}
+
+	return blob.GetBlobData(digest)
+}
+
+func (n *NamespaceContainer) AddBlob(blob cpi.BlobAccess) error {
+	blobData, err := n.blobs.Get("")
+	if err != nil {
+		return fmt.Errorf("failed to retrieve blob data: %w", err)
+	}
+
+	if err := n.assureCreated(); err != nil {
+		return err
+	}
+
+	if _, _, err := blobData.AddBlob(blob); err != nil {
+		return fmt.Errorf("unable to add blob (OCI repository %s): %w", n.impl.GetNamespace(), err)
+	}
+
+	return nil
+}
+
+func (n *NamespaceContainer) ListTags() ([]string, error) {
+	return n.lister.List(dummyContext)
+}
+
+func (n *NamespaceContainer) GetArtifact(i support.NamespaceAccessImpl, vers string) (cpi.ArtifactAccess, error) {
+	ref := n.repo.GetRef(n.impl.GetNamespace(), vers)
+
+	_, desc, err := n.resolver.Resolve(context.Background(), ref)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return nil, errors.ErrNotFound(cpi.KIND_OCIARTIFACT, ref, n.impl.GetNamespace())
+		}
+
+		return nil, err
+	}
+
+	blobData, err := n.blobs.Get(desc.MediaType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve blob data, blob data was empty: %w", err)
+	}
+
+	_, acc, err := blobData.GetBlobData(desc.Digest)
+	if err != nil {
+		return nil, err
+	}
+
+	return support.NewArtifactForBlob(i, blobaccess.ForDataAccess(desc.Digest, desc.Size, desc.MediaType, acc))
+}
+
+func (n *NamespaceContainer) HasArtifact(vers string) (bool, error) {
+	if _, _, err := n.resolver.Resolve(context.Background(), n.repo.GetRef(n.impl.GetNamespace(), vers)); err != nil {
+		if errdefs.IsNotFound(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// assureCreated runs the actions registered to prepare a repository, e.g. to create it in registries that don't
+// create repositories on push.
+func (n *NamespaceContainer) assureCreated() error {
+	if n.checked {
+		return nil
+	}
+
+	var props common.Properties
+	if creds, err := n.repo.getCreds(n.impl.GetNamespace()); err == nil && creds != nil {
+		props = creds.Properties()
+	}
+
+	_, err := oci_repository_prepare.Execute(n.repo.GetContext().GetActions(), n.repo.info.HostPort(), n.impl.GetNamespace(), props)
+	n.checked = true
+
+	return err
+}
+
+func (n *NamespaceContainer) AddArtifact(artifact cpi.Artifact, tags ...string) (blobaccess.BlobAccess, error) {
+	blob, err := artifact.Blob()
+	if err != nil {
+		return nil, err
+	}
+
+	if n.repo.info.Legacy {
+		blob = artdesc.MapArtifactBlobMimeType(blob, true)
+	}
+
+	blobData, err := n.blobs.Get(blob.MimeType())
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve blob data: %w", err)
+	}
+
+	if _, _, err := blobData.AddBlob(blob); err != nil {
+		return nil, err
+	}
+
+	for _, tag := range tags {
+		if err := n.push(tag, blob); err != nil {
+			return nil, err
+		}
+	}
+
+	return blob, nil
+}
+
+func (n *NamespaceContainer) AddTags(digest digest.Digest, tags ...string) error {
+	_, desc, err := n.resolver.Resolve(context.Background(), n.repo.GetRef(n.impl.GetNamespace(), digest.String()))
+	if err != nil {
+		return fmt.Errorf("unable to resolve: %w", err)
+	}
+
+	acc, err := ocireg.NewDataAccess(n.fetcher, desc.Digest, desc.MediaType, false)
+	if err != nil {
+		return fmt.Errorf("error creating new data access: %w", err)
+	}
+
+	blob := blobaccess.ForDataAccess(desc.Digest, desc.Size, desc.MediaType, acc)
+	for _, tag := range tags {
+		if err := n.push(tag, blob); err != nil {
+			return fmt.Errorf("unable to push: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (n *NamespaceContainer) NewArtifact(i support.NamespaceAccessImpl, art ...cpi.Artifact) (cpi.ArtifactAccess, error) {
+	if n.IsReadOnly() {
+		return nil, accessio.ErrReadOnly
+	}
+
+	return support.NewArtifact(i, art...)
+}
+
+// dummyContext is the context of registry requests, it drops the debug logs of containerd.
+var dummyContext = func() context.Context {
+	logger := logrus.New()
+	logger.Level = logrus.ErrorLevel
+
+	return log.WithLogger(context.Background(), logrus.NewEntry(logger))
+}()
@@ -0,0 +1,158 @@
+package ocitransport
+
+import (
+	"context"
+	"net/http"
+	"path"
+
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/remotes/docker/config"
+	"github.com/open-component-model/ocm/pkg/contexts/credentials"
+	"github.com/open-component-model/ocm/pkg/contexts/credentials/builtin/oci/identity"
+	"github.com/open-component-model/ocm/pkg/contexts/oci/artdesc"
+	"github.com/open-component-model/ocm/pkg/contexts/oci/cpi"
+	"github.com/open-component-model/ocm/pkg/contexts/oci/repositories/ocireg"
+	"github.com/open-component-model/ocm/pkg/docker"
+	"github.com/open-component-model/ocm/pkg/docker/resolve"
+	"github.com/open-component-model/ocm/pkg/errors"
+	"github.com/open-component-model/ocm/pkg/refmgmt"
+	"github.com/open-component-model/ocm/pkg/utils"
+)
+
+// RepositoryImpl implements an OCI registry repository sending its requests with the transport of its specification.
+type RepositoryImpl struct {
+	cpi.RepositoryImplBase
+	spec *RepositorySpec
+	info *ocireg.RepositoryInfo
+}
+
+var (
+	_ cpi.RepositoryImpl                   = (*RepositoryImpl)(nil)
+	_ credentials.ConsumerIdentityProvider = (*RepositoryImpl)(nil)
+)
+
+// NewRepository creates the repository for the specification.
+func NewRepository(ctx cpi.Context, spec *RepositorySpec, info *ocireg.RepositoryInfo) (cpi.Repository, error) {
+	return cpi.NewRepository(&RepositoryImpl{
+		RepositoryImplBase: cpi.NewRepositoryImplBase(ctx),
+		spec:               spec,
+		info:               info,
+	}), nil
+}
+
+func (r *RepositoryImpl) GetSpecification() cpi.RepositorySpec {
+	return r.spec
+}
+
+func (r *RepositoryImpl) Close() error {
+	return nil
+}
+
+func (r *RepositoryImpl) GetConsumerId(uctx ...credentials.UsageContext) credentials.ConsumerIdentity {
+	if c, ok := utils.Optional(uctx...).(credentials.StringUsageContext); ok {
+		return identity.GetConsumerId(r.info.Locator, c.String())
+	}
+
+	return identity.GetConsumerId(r.info.Locator, "")
+}
+
+func (r *RepositoryImpl) GetIdentityMatcher() string {
+	return identity.CONSUMER_TYPE
+}
+
+func (r *RepositoryImpl) NamespaceLister() cpi.NamespaceLister {
+	return nil
+}
+
+func (r *RepositoryImpl) IsReadOnly() bool {
+	return false
+}
+
+func (r *RepositoryImpl) getCreds(comp string) (credentials.Credentials, error) {
+	if r.info.Creds != nil {
+		return r.info.Creds, nil
+	}
+
+	return identity.GetCredentials(r.GetContext(), r.info.Locator, comp)
+}
+
+// getResolver returns a resolver for the namespace. Unlike OCM, it leaves TLS to the transport of the specification,
+// which configures it from the credentials of the registry.
+func (r *RepositoryImpl) getResolver(comp string) (resolve.Resolver, error) {
+	creds, err := r.getCreds(comp)
+	if err != nil && !errors.IsErrUnknownKind(err, credentials.KIND_CONSUMER) {
+		return nil, err
+	}
+
+	hosts := config.ConfigureHosts(context.Background(), config.HostOptions{
+		Credentials: func(string) (string, string, error) {
+			if creds == nil {
+				return "", "", nil
+			}
+
+			secret := creds.GetProperty(credentials.ATTR_IDENTITY_TOKEN)
+			if secret == "" {
+				secret = creds.GetProperty(credentials.ATTR_PASSWORD)
+			}
+
+			return creds.GetProperty(credentials.ATTR_USERNAME), secret, nil
+		},
+		DefaultScheme: r.info.Scheme,
+		UpdateClient: func(client *http.Client) error {
+			client.Transport = r.spec.transport
+
+			return nil
+		},
+	})
+
+	return docker.NewResolver(docker.ResolverOptions{Hosts: docker.ConvertHosts(hosts)}), nil
+}
+
+func (r *RepositoryImpl) GetRef(comp, vers string) string {
+	base := path.Join(r.info.Locator, comp)
+	if vers == "" {
+		return base
+	}
+
+	if ok, d := artdesc.IsDigest(vers); ok {
+		return base + "@" + d.String()
+	}
+
+	return base + ":" + vers
+}
+
+func (r *RepositoryImpl) GetBaseURL() string {
+	return r.spec.BaseURL
+}
+
+func (r *RepositoryImpl) ExistsArtifact(name string, version string) (bool, error) {
+	res, err := r.getResolver(name)
+	if err != nil {
+		return false, err
+	}
+
+	if _, _, err := res.Resolve(context.Background(), r.GetRef(name, version)); err != nil {
+		if errdefs.IsNotFound(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *RepositoryImpl) LookupArtifact(name string, version string) (acc cpi.ArtifactAccess, err error) {
+	ns, err := NewNamespace(r, name)
+	if err != nil {
+		return nil, err
+	}
+	// the namespace is only used to look up the artifact.
+	defer refmgmt.PropagateCloseTemporary(&err, ns)
+
+	return ns.GetArtifact(version)
+}
+
+func (r *RepositoryImpl) LookupNamespace(name string) (cpi.NamespaceAccess, error) {
+	return NewNamespace(r, name)
+}
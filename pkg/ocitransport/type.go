@@ -0,0 +1,81 @@
+// Package ocitransport provides an OCI registry repository for OCM that sends its requests with a given HTTP
+// transport. It's adapted from the ocireg repository of OCM, which always uses a transport of its own and thereby
+// ignores proxies, insecure registries and client certificates configured for a single repository.
+package ocitransport
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/containerd/containerd/reference"
+	"github.com/open-component-model/ocm/pkg/contexts/credentials"
+	"github.com/open-component-model/ocm/pkg/contexts/oci/cpi"
+	"github.com/open-component-model/ocm/pkg/contexts/oci/repositories/ocireg"
+)
+
+// RepositorySpec describes an OCI registry accessed with a custom transport. It's serialized like the ocireg
+// specification it embeds, so descriptors referring to the repository don't depend on the transport.
+type RepositorySpec struct {
+	*ocireg.RepositorySpec
+
+	transport http.RoundTripper
+}
+
+var _ cpi.RepositorySpec = (*RepositorySpec)(nil)
+
+// NewRepositorySpec creates a RepositorySpec for the registry at the base URL sending requests with the transport.
+func NewRepositorySpec(baseURL string, transport http.RoundTripper) *RepositorySpec {
+	return &RepositorySpec{
+		RepositorySpec: ocireg.NewRepositorySpec(baseURL),
+		transport:      transport,
+	}
+}
+
+// Repository returns the repository described by the specification.
+func (a *RepositorySpec) Repository(ctx cpi.Context, creds credentials.Credentials) (cpi.Repository, error) {
+	info, err := a.getInfo(creds)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRepository(ctx, a, info)
+}
+
+func (a *RepositorySpec) getInfo(creds credentials.Credentials) (*ocireg.RepositoryInfo, error) {
+	var u *url.URL
+
+	info := &ocireg.RepositoryInfo{}
+
+	ref, err := reference.Parse(a.BaseURL)
+	if err == nil {
+		if ref.Object != "" {
+			return nil, fmt.Errorf("invalid repository locator %q", a.BaseURL)
+		}
+
+		if u, err = url.Parse("https://" + ref.Locator); err != nil {
+			return nil, err
+		}
+
+		info.Locator = ref.Locator
+	} else {
+		if u, err = url.Parse(a.BaseURL); err != nil {
+			return nil, err
+		}
+
+		info.Locator = u.Host
+	}
+
+	if a.LegacyTypes != nil {
+		info.Legacy = *a.LegacyTypes
+	} else {
+		host, _, _ := strings.Cut(info.Locator, "/")
+		info.Legacy = host == "docker.io"
+	}
+
+	info.Scheme = u.Scheme
+	info.Creds = creds
+
+	return info, nil
+}
@@ -0,0 +1,187 @@
+package ocm
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/open-component-model/ocm/pkg/blobaccess"
+	"github.com/open-component-model/ocm/pkg/contexts/oci/artdesc"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/compdesc"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/repositories/genericocireg"
+	"github.com/open-component-model/ocm/pkg/signing/signutils"
+
+	"github.com/open-component-model/replication-controller/api/v1alpha1"
+	"github.com/open-component-model/replication-controller/pkg/attestation"
+	"github.com/open-component-model/replication-controller/pkg/keys"
+	"github.com/open-component-model/replication-controller/pkg/version"
+)
+
+const (
+	// attestationNamespace is the namespace below the path of the destination repository the attestations are
+	// stored in. It's kept apart from the component descriptors, so attestations aren't listed as versions.
+	attestationNamespace = "attestations"
+
+	attestationConfigMediaType = "application/vnd.ocm.software.replication.attestation.config.v1+json"
+)
+
+// AttestReplication creates a signed in-toto attestation of the replication of the source component version to the
+// destination and stores it as an OCI artifact in the attestation namespace of the destination. The attestation is signed
+// with the same key as the replication signature. The verification results are taken from the subscription status,
+// so VerifyComponent must have been called before.
+func (c *Client) AttestReplication(
+	ctx context.Context,
+	obj *v1alpha1.ComponentSubscription,
+	source ocm.ComponentVersionAccess,
+	destination ocm.ComponentVersionAccess,
+	startedOn time.Time,
+) (*v1alpha1.ReplicationAttestation, error) {
+	sourceDigest, err := compdesc.Hash(source.GetDescriptor(), compdesc.JsonNormalisationV2, sha256.New())
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash source component descriptor: %w", err)
+	}
+
+	destinationDigest, err := compdesc.Hash(destination.GetDescriptor(), compdesc.JsonNormalisationV2, sha256.New())
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash destination component descriptor: %w", err)
+	}
+
+	statement := attestation.NewStatement(destination.GetName(), destination.GetVersion(), destinationDigest, attestation.Predicate{
+		Source: attestation.Repository{
+			URL:    obj.Spec.Source.URL,
+			Digest: sourceDigest,
+		},
+		Destination: attestation.Repository{
+			URL:    obj.Spec.Destination.URL,
+			Digest: destinationDigest,
+		},
+		Subscription:  obj.Namespace + "/" + obj.Name,
+		Verifications: obj.Status.Verifications,
+		Transfer:      getTransferOptions(obj),
+		StartedOn:     startedOn.UTC(),
+		FinishedOn:    time.Now().UTC(),
+		Controller:    version.ReleaseVersion,
+	})
+
+	signer, pub, err := c.getAttestationSigner(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attestation signer: %w", err)
+	}
+
+	key, err := keys.ParseKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	envelope, err := attestation.Sign(ctx, statement, signer, key.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign attestation: %w", err)
+	}
+
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal attestation: %w", err)
+	}
+
+	reference, err := storeAttestation(*obj.Spec.Destination, destination, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store attestation: %w", err)
+	}
+
+	if err := c.publishPublicKey(ctx, pub); err != nil {
+		return nil, fmt.Errorf("failed to publish public key: %w", err)
+	}
+
+	return &v1alpha1.ReplicationAttestation{
+		Reference: reference,
+		KeyID:     key.ID,
+		PublicKey: base64.StdEncoding.EncodeToString(pub),
+	}, nil
+}
+
+// getAttestationSigner returns the key used for signing attestations and its PEM encoded public key or certificate
+// chain. It's the key SignDestinationComponent signs with, following the same precedence.
+func (c *Client) getAttestationSigner(ctx context.Context) (crypto.Signer, []byte, error) {
+	var priv, pub []byte
+
+	switch {
+	case c.signer != nil:
+		return c.signer, c.signer.PublicKeyPEM(), nil
+	case c.signingCertificateSecret != nil:
+		cert, err := c.getSigningCertificate(ctx, *c.signingCertificateSecret)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get signing certificate: %w", err)
+		}
+
+		priv, pub = cert.privateKey, cert.chain
+	default:
+		var err error
+		if priv, pub, err = c.generatedKey.get(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	key, err := signutils.ParsePrivateKey(priv)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+
+	return signer, pub, nil
+}
+
+// storeAttestation pushes the DSSE envelope as the single layer of an OCI artifact and returns its reference. The
+// artifact is stored in the repository `<destination>/attestations/<component>`, tagged with the component version.
+func storeAttestation(repository v1alpha1.OCMRepository, cv ocm.ComponentVersionAccess, envelope []byte) (_ string, err error) {
+	ocirepo := genericocireg.GetOCIRepository(cv.Repository())
+	if ocirepo == nil {
+		return "", fmt.Errorf("repository %s is not an OCI registry", repository.URL)
+	}
+
+	name := path.Join(repositoryPath(repository), attestationNamespace, cv.GetName())
+	ns, err := ocirepo.LookupNamespace(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to lookup namespace %s: %w", name, err)
+	}
+	defer func() {
+		err = errors.Join(err, ns.Close())
+	}()
+
+	art, err := ns.NewArtifact()
+	if err != nil {
+		return "", fmt.Errorf("failed to create artifact: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, art.Close())
+	}()
+
+	config := blobaccess.ForData(attestationConfigMediaType, []byte("{}"))
+	if err := art.ManifestAccess().SetConfigBlob(config, nil); err != nil {
+		return "", fmt.Errorf("failed to set config: %w", err)
+	}
+
+	if _, err := art.AddLayer(blobaccess.ForData(attestation.MediaType, envelope), &artdesc.Descriptor{
+		MediaType: attestation.MediaType,
+	}); err != nil {
+		return "", fmt.Errorf("failed to add attestation layer: %w", err)
+	}
+
+	tag := strings.ReplaceAll(cv.GetVersion(), "+", genericocireg.META_SEPARATOR)
+	if _, err := ns.AddArtifact(art, tag); err != nil {
+		return "", fmt.Errorf("failed to push attestation: %w", err)
+	}
+
+	return fmt.Sprintf("%s/%s:%s", repositoryHost(repository), name, tag), nil
+}
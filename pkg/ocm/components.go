@@ -0,0 +1,108 @@
+package ocm
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/repositories/genericocireg/componentmapping"
+
+	"github.com/open-component-model/replication-controller/api/v1alpha1"
+)
+
+// ListComponents lists the components of the source repository matching the component selector of the
+// subscription, or all of them if it has none. OCM doesn't keep an index of the components of a repository, so they
+// are discovered with the catalog API of the registry, which must be supported and accessible with the credentials
+// of the source.
+func (c *Client) ListComponents(ctx context.Context, octx ocm.Context, obj *v1alpha1.ComponentSubscription) ([]string, error) {
+	matches := func(string) bool { return true }
+	if obj.Spec.ComponentSelector != nil {
+		var err error
+		if matches, err = componentMatcher(*obj.Spec.ComponentSelector); err != nil {
+			return nil, err
+		}
+	}
+
+	repository, err := parseRepository(repositoryURL(obj.Spec.Source))
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := c.registryOptions(ctx, octx, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces, err := remote.Catalog(ctx, repository.Registry, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories of %s: %w", repository.RegistryStr(), err)
+	}
+
+	// components are stored in the component-descriptors namespace below the path of the repository.
+	prefix := path.Join(repository.RepositoryStr(), componentmapping.ComponentDescriptorNamespace) + "/"
+
+	var components []string
+	for _, namespace := range namespaces {
+		component, ok := strings.CutPrefix(namespace, prefix)
+		if ok && matches(component) {
+			components = append(components, component)
+		}
+	}
+
+	sort.Strings(components)
+
+	return components, nil
+}
+
+// componentMatcher returns a function matching component names against the selector.
+func componentMatcher(selector v1alpha1.ComponentSelector) (func(string) bool, error) {
+	switch {
+	case selector.Pattern != "" && selector.Regex != "":
+		return nil, fmt.Errorf("component selector must set either a pattern or a regex")
+	case selector.Pattern != "":
+		if _, err := path.Match(selector.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid component pattern %q: %w", selector.Pattern, err)
+		}
+
+		return func(component string) bool {
+			ok, _ := path.Match(selector.Pattern, component)
+
+			return ok
+		}, nil
+	case selector.Regex != "":
+		expression, err := regexp.Compile("^(?:" + selector.Regex + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid component regex %q: %w", selector.Regex, err)
+		}
+
+		return expression.MatchString, nil
+	default:
+		return nil, fmt.Errorf("component selector must set a pattern or a regex")
+	}
+}
+
+// parseRepository parses the URL of an OCM repository, which may be prefixed with a scheme, into its registry
+// and path. The path may be empty.
+func parseRepository(url string) (name.Repository, error) {
+	var opts []name.Option
+	if plain, ok := strings.CutPrefix(url, "http://"); ok {
+		url = plain
+		opts = append(opts, name.Insecure)
+	}
+
+	url = strings.TrimSuffix(strings.TrimPrefix(url, "https://"), "/")
+	host, repository, _ := strings.Cut(url, "/")
+
+	registry, err := name.NewRegistry(host, opts...)
+	if err != nil {
+		return name.Repository{}, fmt.Errorf("failed to parse repository %s: %w", url, err)
+	}
+
+	return registry.Repo(strings.Split(repository, "/")...), nil
+}
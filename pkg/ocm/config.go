@@ -0,0 +1,155 @@
+package ocm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/open-component-model/ocm/pkg/contexts/ocm"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+// ConfigKey is the key of the OCM configuration document in ConfigMaps and Secrets.
+const ConfigKey = ".ocmconfig"
+
+// Kinds of objects an OCM configuration can be stored in.
+const (
+	ConfigMapKind = "ConfigMap"
+	SecretKind    = "Secret"
+)
+
+// ConfigReference references a ConfigMap or Secret holding an OCM configuration document.
+type ConfigReference struct {
+	// Kind is either ConfigMapKind or SecretKind. Defaults to SecretKind.
+	Kind string
+
+	types.NamespacedName
+}
+
+// WithDefaultConfig configures the Client to apply the OCM configuration stored in the given object to the context
+// of every subscription, before the configuration of the subscription itself.
+func WithDefaultConfig(ref ConfigReference) ClientOption {
+	return func(c *Client) {
+		c.defaultConfig = &ref
+	}
+}
+
+// applyConfig reads the OCM configuration document from the referenced object and applies it to the context. The
+// document is the same as the ocm CLI reads from `.ocmconfig`, e.g. a generic configuration listing credential
+// repositories, consumers, signing keys and resolvers. Restricted documents, i.e. those of subscriptions, must not
+// make the controller read its files or run credential helpers, see checkRestrictedConfig.
+func (c *Client) applyConfig(ctx context.Context, octx ocm.Context, ref ConfigReference, restricted bool) error {
+	var (
+		data []byte
+		ok   bool
+	)
+
+	if ref.Kind == "" {
+		ref.Kind = SecretKind
+	}
+
+	switch ref.Kind {
+	case ConfigMapKind:
+		cm := &corev1.ConfigMap{}
+		if err := c.client.Get(ctx, ref.NamespacedName, cm); err != nil {
+			return fmt.Errorf("failed to get config map %s: %w", ref.NamespacedName, err)
+		}
+
+		var value string
+		value, ok = cm.Data[ConfigKey]
+		data = []byte(value)
+	case SecretKind:
+		secret := &corev1.Secret{}
+		if err := c.client.Get(ctx, ref.NamespacedName, secret); err != nil {
+			return fmt.Errorf("failed to get secret %s: %w", ref.NamespacedName, err)
+		}
+
+		data, ok = secret.Data[ConfigKey]
+	default:
+		return fmt.Errorf("unsupported kind %q for ocm config %s", ref.Kind, ref.NamespacedName)
+	}
+
+	if !ok {
+		return fmt.Errorf("%s %s has no %s key", ref.Kind, ref.NamespacedName, ConfigKey)
+	}
+
+	if restricted {
+		if err := checkRestrictedConfig(data); err != nil {
+			return fmt.Errorf("ocm config in %s is not allowed: %w", ref.NamespacedName, err)
+		}
+	}
+
+	// the document is applied as is, without spiff processing, so it can't read files or environment variables
+	// of the controller.
+	cfg, err := octx.ConfigContext().GetConfigForData(data, nil)
+	if err != nil {
+		return fmt.Errorf("invalid ocm config in %s: %w", ref.NamespacedName, err)
+	}
+
+	if err := octx.ConfigContext().ApplyConfig(cfg, ref.NamespacedName.String()); err != nil {
+		return fmt.Errorf("failed to apply ocm config in %s: %w", ref.NamespacedName, err)
+	}
+
+	return nil
+}
+
+// checkRestrictedConfig rejects the parts of an OCM configuration document that make the controller read its own
+// files or run programs: `path` keys, e.g. of signing keys or root certificates, DockerConfig credential
+// repositories reading a `dockerConfigFile`, and inline docker configurations with `credHelpers` or a `credsStore`.
+// Documents that can't be parsed are left to the OCM configuration context to report.
+func checkRestrictedConfig(data []byte) error {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil
+	}
+
+	return checkRestrictedValue(doc, "")
+}
+
+func checkRestrictedValue(value any, location string) error {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			nested := v[key]
+			field := strings.TrimPrefix(location+"."+key, ".")
+
+			switch key {
+			case "path":
+				return fmt.Errorf("%s: files of the controller can't be read", field)
+			case "dockerConfigFile":
+				return fmt.Errorf("%s: docker config files of the controller can't be read", field)
+			case "credHelpers", "credsStore":
+				return fmt.Errorf("%s: docker credential helpers can't be run", field)
+			case "dockerConfig":
+				// the inline docker configuration may also be given as a JSON string.
+				if s, ok := nested.(string); ok {
+					if err := json.Unmarshal([]byte(s), &nested); err != nil {
+						continue
+					}
+				}
+			}
+
+			if err := checkRestrictedValue(nested, field); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for i, nested := range v {
+			if err := checkRestrictedValue(nested, fmt.Sprintf("%s[%d]", location, i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
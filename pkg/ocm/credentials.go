@@ -0,0 +1,227 @@
+package ocm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/open-component-model/ocm/pkg/common"
+	"github.com/open-component-model/ocm/pkg/contexts/credentials/builtin/oci/identity"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/open-component-model/replication-controller/api/v1alpha1"
+)
+
+const (
+	defaultCredentialHelperTimeout = 30 * time.Second
+
+	// defaultCredentialHelperTTL is how long credentials are cached if the credential helper reports no expiry.
+	defaultCredentialHelperTTL = 5 * time.Minute
+
+	// credentialHelperExpiryMargin renews credentials before they expire, so they outlast the requests using them.
+	credentialHelperExpiryMargin = 30 * time.Second
+
+	// credentialHelperTokenUsername is returned by credential helpers as username for identity tokens.
+	credentialHelperTokenUsername = "<token>"
+)
+
+// credentialHelperResponse is printed by a credential helper for the `get` command.
+type credentialHelperResponse struct {
+	ServerURL string
+	Username  string
+	Secret    string
+	// ExpiresAt isn't part of the docker credential helper protocol. It allows helpers minting short-lived
+	// tokens to control how long they are cached.
+	ExpiresAt *time.Time `json:",omitempty"`
+}
+
+// configureHelperCredentials configures the credentials returned by the credential helper of the repository for
+// its registry. They're obtained again once the cached credentials expire.
+func (c *Client) configureHelperCredentials(ctx context.Context, octx ocm.Context, repository v1alpha1.OCMRepository) error {
+	host := repositoryHost(repository)
+	creds, err := newRefreshingCredentials(log.FromContext(ctx), func() (common.Properties, error) {
+		properties, err := c.credentialHelpers.get(ctx, repository.Credentials.Exec, host)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get credentials for %s: %w", host, err)
+		}
+
+		return properties, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	octx.CredentialsContext().SetCredentialsForConsumer(identity.GetConsumerId(host, ""), creds)
+
+	return nil
+}
+
+type cachedCredentials struct {
+	properties common.Properties
+	expiresAt  time.Time
+}
+
+// credentialHelpers runs credential helper plugins and caches the credentials they return until they expire.
+type credentialHelpers struct {
+	allowed    []string
+	allowedEnv []string
+	timeout    time.Duration
+	now        func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedCredentials
+}
+
+func newCredentialHelpers() *credentialHelpers {
+	return &credentialHelpers{
+		timeout: defaultCredentialHelperTimeout,
+		now:     time.Now,
+		cache:   map[string]cachedCredentials{},
+	}
+}
+
+// get returns the credentials for the registry host, either cached or obtained from the credential helper.
+func (h *credentialHelpers) get(ctx context.Context, helper *v1alpha1.ExecCredentials, host string) (common.Properties, error) {
+	if !slices.Contains(h.allowed, helper.Command) {
+		return nil, fmt.Errorf("credential helper %s is not allowed", helper.Command)
+	}
+
+	if err := h.validate(helper); err != nil {
+		return nil, err
+	}
+
+	key, err := credentialHelperKey(helper, host)
+	if err != nil {
+		return nil, err
+	}
+
+	h.mu.Lock()
+	cached, ok := h.cache[key]
+	h.mu.Unlock()
+
+	if ok && h.now().Before(cached.expiresAt) {
+		return cached.properties, nil
+	}
+
+	response, err := h.run(ctx, helper, host)
+	if err != nil {
+		return nil, err
+	}
+
+	properties := common.Properties{}
+	if response.Username == credentialHelperTokenUsername {
+		properties.SetNonEmptyValue(identity.ATTR_IDENTITY_TOKEN, response.Secret)
+	} else {
+		properties.SetNonEmptyValue(identity.ATTR_USERNAME, response.Username)
+		properties.SetNonEmptyValue(identity.ATTR_PASSWORD, response.Secret)
+	}
+
+	expiresAt := h.now().Add(defaultCredentialHelperTTL)
+	if response.ExpiresAt != nil {
+		expiresAt = response.ExpiresAt.Add(-credentialHelperExpiryMargin)
+	}
+
+	h.mu.Lock()
+	h.evict()
+	h.cache[key] = cachedCredentials{properties: properties, expiresAt: expiresAt}
+	h.mu.Unlock()
+
+	return properties, nil
+}
+
+// validate rejects arguments and environment variables of a credential helper that could change what the allowed
+// command does. Arguments must be options, so they can't replace the `get` command, and environment variables must
+// be allowed by the operator, so e.g. LD_PRELOAD or PATH can't be set.
+func (h *credentialHelpers) validate(helper *v1alpha1.ExecCredentials) error {
+	for _, arg := range helper.Args {
+		if !strings.HasPrefix(arg, "-") || arg == "-" || arg == "--" {
+			return fmt.Errorf("argument %q of credential helper %s is not an option", arg, helper.Command)
+		}
+	}
+
+	for _, env := range helper.Env {
+		if !slices.Contains(h.allowedEnv, env.Name) {
+			return fmt.Errorf("environment variable %s of credential helper %s is not allowed", env.Name, helper.Command)
+		}
+	}
+
+	return nil
+}
+
+// evict drops expired credentials, so credentials of deleted subscriptions or changed helpers don't pile up. It must
+// be called with the lock held.
+func (h *credentialHelpers) evict() {
+	now := h.now()
+	for key, cached := range h.cache {
+		if !now.Before(cached.expiresAt) {
+			delete(h.cache, key)
+		}
+	}
+}
+
+// forget drops the cached credentials of the credential helpers of the repositories, e.g. because the registry
+// rejected them before they expired.
+func (h *credentialHelpers) forget(repositories ...v1alpha1.OCMRepository) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, repository := range repositories {
+		if repository.Credentials == nil || repository.Credentials.Exec == nil {
+			continue
+		}
+
+		if key, err := credentialHelperKey(repository.Credentials.Exec, repositoryHost(repository)); err == nil {
+			delete(h.cache, key)
+		}
+	}
+}
+
+func credentialHelperKey(helper *v1alpha1.ExecCredentials, host string) (string, error) {
+	key, err := json.Marshal(struct {
+		Helper *v1alpha1.ExecCredentials
+		Host   string
+	}{helper, host})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal credential helper: %w", err)
+	}
+
+	return string(key), nil
+}
+
+func (h *credentialHelpers) run(ctx context.Context, helper *v1alpha1.ExecCredentials, host string) (*credentialHelperResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, helper.Command, append(append([]string{}, helper.Args...), "get")...) //nolint:gosec // allowed by the operator
+	cmd.Stdin = strings.NewReader(host)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = os.Environ()
+	for _, env := range helper.Env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", env.Name, env.Value))
+	}
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to run credential helper %s: %w: %s", helper.Command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	response := &credentialHelperResponse{}
+	if err := json.Unmarshal(stdout.Bytes(), response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal output of credential helper %s: %w", helper.Command, err)
+	}
+
+	if response.Secret == "" {
+		return nil, fmt.Errorf("credential helper %s returned no secret for %s", helper.Command, host)
+	}
+
+	return response, nil
+}
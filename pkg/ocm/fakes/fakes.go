@@ -2,9 +2,11 @@ package fakes
 
 import (
 	"context"
+	"time"
 
 	"github.com/open-component-model/ocm/pkg/contexts/ocm"
 	ocm2 "github.com/open-component-model/replication-controller/pkg/ocm"
+	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/open-component-model/replication-controller/api/v1alpha1"
 )
@@ -18,6 +20,7 @@ type MockFetcher struct {
 	getComponentVersionMap              map[string]ocm.ComponentVersionAccess
 	getComponentVersionErr              error
 	getComponentVersionCalledWith       [][]any
+	getDestinationVersionCalledWith     [][]any
 	verifySourceComponentErr            error
 	verifySourceComponentVerified       bool
 	verifySourceComponentCalledWith     [][]any
@@ -27,11 +30,26 @@ type MockFetcher struct {
 	transferComponentVersionErr         error
 	transferComponentVersionCalledWith  [][]any
 	signDestinationComponentCalledWith  [][]any
+	addProvenanceErr                    error
+	addProvenanceCalledWith             [][]any
+	attestReplicationAttestation        *v1alpha1.ReplicationAttestation
+	attestReplicationErr                error
+	attestReplicationCalledWith         [][]any
+	mirrorImagesErr                     error
+	mirrorImagesCalledWith              [][]any
+	listComponentsComponents            []string
+	listComponentsErr                   error
+	listComponentsCalledWith            [][]any
+	listPendingVersionsVersions         map[string][]string
+	listPendingVersionsPending          map[string][]string
+	listPendingVersionsErr              map[string]error
+	listPendingVersionsCalledWith       [][]any
+	authorizeSecretReferenceErr         error
 }
 
 var _ ocm2.Contract = &MockFetcher{}
 
-func (m *MockFetcher) SignDestinationComponent(_ context.Context, component ocm.ComponentVersionAccess) ([]byte, error) {
+func (m *MockFetcher) SignDestinationComponent(_ context.Context, _ *v1alpha1.ComponentSubscription, component ocm.ComponentVersionAccess) ([]byte, error) {
 	m.signDestinationComponentCalledWith = append(m.signDestinationComponentCalledWith, []any{component.GetName()})
 	return m.signDestinationComponentPubKey, nil
 }
@@ -51,6 +69,63 @@ func (m *MockFetcher) SignDestinationComponentReturns(pub []byte, t error) {
 	m.signDestinationComponentPubKey = pub
 }
 
+func (m *MockFetcher) AddProvenance(_ context.Context, obj *v1alpha1.ComponentSubscription, source, destination ocm.ComponentVersionAccess) error {
+	m.addProvenanceCalledWith = append(m.addProvenanceCalledWith, []any{obj, source, destination})
+	return m.addProvenanceErr
+}
+
+func (m *MockFetcher) AddProvenanceReturns(err error) {
+	m.addProvenanceErr = err
+}
+
+func (m *MockFetcher) AddProvenanceWasNotCalled() bool {
+	return len(m.addProvenanceCalledWith) == 0
+}
+
+func (m *MockFetcher) AddProvenanceCallingArgumentsOnCall(i int) []any {
+	return m.addProvenanceCalledWith[i]
+}
+
+func (m *MockFetcher) AttestReplication(
+	_ context.Context,
+	obj *v1alpha1.ComponentSubscription,
+	source, destination ocm.ComponentVersionAccess,
+	startedOn time.Time,
+) (*v1alpha1.ReplicationAttestation, error) {
+	m.attestReplicationCalledWith = append(m.attestReplicationCalledWith, []any{obj, source, destination, startedOn})
+	return m.attestReplicationAttestation, m.attestReplicationErr
+}
+
+func (m *MockFetcher) AttestReplicationReturns(attestation *v1alpha1.ReplicationAttestation, err error) {
+	m.attestReplicationAttestation = attestation
+	m.attestReplicationErr = err
+}
+
+func (m *MockFetcher) AttestReplicationWasNotCalled() bool {
+	return len(m.attestReplicationCalledWith) == 0
+}
+
+func (m *MockFetcher) AttestReplicationCallingArgumentsOnCall(i int) []any {
+	return m.attestReplicationCalledWith[i]
+}
+
+func (m *MockFetcher) MirrorImages(_ context.Context, _ ocm.Context, obj *v1alpha1.ComponentSubscription, cv ocm.ComponentVersionAccess) error {
+	m.mirrorImagesCalledWith = append(m.mirrorImagesCalledWith, []any{obj, cv})
+	return m.mirrorImagesErr
+}
+
+func (m *MockFetcher) MirrorImagesReturns(err error) {
+	m.mirrorImagesErr = err
+}
+
+func (m *MockFetcher) MirrorImagesWasNotCalled() bool {
+	return len(m.mirrorImagesCalledWith) == 0
+}
+
+func (m *MockFetcher) MirrorImagesCallingArgumentsOnCall(i int) []any {
+	return m.mirrorImagesCalledWith[i]
+}
+
 func (m *MockFetcher) CreateAuthenticatedOCMContext(ctx context.Context, obj *v1alpha1.ComponentSubscription) (ocm.Context, error) {
 	return ocm.New(), nil
 }
@@ -76,6 +151,15 @@ func (m *MockFetcher) GetComponentVersionWasNotCalled() bool {
 	return len(m.getComponentVersionCalledWith) == 0
 }
 
+func (m *MockFetcher) GetDestinationComponentVersion(ctx context.Context, octx ocm.Context, obj *v1alpha1.ComponentSubscription, version string) (ocm.ComponentVersionAccess, error) {
+	m.getDestinationVersionCalledWith = append(m.getDestinationVersionCalledWith, []any{obj, version})
+	return m.getComponentVersionMap[obj.Spec.Component], m.getComponentVersionErr
+}
+
+func (m *MockFetcher) GetDestinationComponentVersionCallingArgumentsOnCall(i int) []any {
+	return m.getDestinationVersionCalledWith[i]
+}
+
 func (m *MockFetcher) VerifyComponent(ctx context.Context, obj *v1alpha1.ComponentSubscription, cv ocm.ComponentVersionAccess) (bool, error) {
 	m.verifySourceComponentCalledWith = append(m.verifySourceComponentCalledWith, []any{obj, cv})
 	return m.verifySourceComponentVerified, m.verifySourceComponentErr
@@ -128,3 +212,56 @@ func (m *MockFetcher) TransferComponentWasNotCalled() bool {
 func (m *MockFetcher) TransferComponentCallingArgumentsOnCall(i int) []any {
 	return m.transferComponentVersionCalledWith[i]
 }
+
+func (m *MockFetcher) ListComponents(_ context.Context, _ ocm.Context, obj *v1alpha1.ComponentSubscription) ([]string, error) {
+	m.listComponentsCalledWith = append(m.listComponentsCalledWith, []any{obj})
+	return m.listComponentsComponents, m.listComponentsErr
+}
+
+func (m *MockFetcher) ListComponentsReturns(components []string, err error) {
+	m.listComponentsComponents = components
+	m.listComponentsErr = err
+}
+
+func (m *MockFetcher) ListComponentsWasNotCalled() bool {
+	return len(m.listComponentsCalledWith) == 0
+}
+
+func (m *MockFetcher) ListPendingVersions(_ context.Context, _ ocm.Context, obj *v1alpha1.ComponentSubscription, mirrored map[string]string) ([]string, []v1alpha1.MirrorQueueEntry, error) {
+	m.listPendingVersionsCalledWith = append(m.listPendingVersionsCalledWith, []any{obj, mirrored})
+	component := obj.Spec.Component
+
+	var pending []v1alpha1.MirrorQueueEntry
+	for _, version := range m.listPendingVersionsPending[component] {
+		pending = append(pending, v1alpha1.MirrorQueueEntry{Component: component, Version: version, Digest: "digest-" + version})
+	}
+
+	return m.listPendingVersionsVersions[component], pending, m.listPendingVersionsErr[component]
+}
+
+func (m *MockFetcher) ListPendingVersionsReturnsForName(name string, versions, pending []string, err error) {
+	if m.listPendingVersionsVersions == nil {
+		m.listPendingVersionsVersions = make(map[string][]string)
+		m.listPendingVersionsPending = make(map[string][]string)
+		m.listPendingVersionsErr = make(map[string]error)
+	}
+	m.listPendingVersionsVersions[name] = versions
+	m.listPendingVersionsPending[name] = pending
+	m.listPendingVersionsErr[name] = err
+}
+
+func (m *MockFetcher) ListPendingVersionsWasNotCalled() bool {
+	return len(m.listPendingVersionsCalledWith) == 0
+}
+
+func (m *MockFetcher) ListPendingVersionsCallingArgumentsOnCall(i int) []any {
+	return m.listPendingVersionsCalledWith[i]
+}
+
+func (m *MockFetcher) AuthorizeSecretReference(_ context.Context, _ string, _ types.NamespacedName) error {
+	return m.authorizeSecretReferenceErr
+}
+
+func (m *MockFetcher) AuthorizeSecretReferenceReturns(err error) {
+	m.authorizeSecretReferenceErr = err
+}
@@ -0,0 +1,48 @@
+package ocm
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/open-component-model/replication-controller/api/v1alpha1"
+)
+
+// SecretReferenceKey returns the key of a Secret referenced from the given namespace. References without a
+// namespace are local to the referrer.
+func SecretReferenceKey(namespace string, ref *corev1.SecretReference) types.NamespacedName {
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+
+	return types.NamespacedName{Namespace: namespace, Name: ref.Name}
+}
+
+// AuthorizeSecretReference checks that objects of the referrer namespace may read or, for propagated pull secrets,
+// write the given Secret. Secrets of other namespaces require a SecretReferenceGrant in the namespace of the Secret.
+// An empty referrer is used for cluster-scoped objects, e.g. trust policies, which may reference any Secret.
+func (c *Client) AuthorizeSecretReference(ctx context.Context, referrer string, secret types.NamespacedName) error {
+	if referrer == "" || referrer == secret.Namespace {
+		return nil
+	}
+
+	if c.noCrossNamespaceRefs {
+		return fmt.Errorf("cross-namespace reference to secret %s is not allowed", secret)
+	}
+
+	grants := &v1alpha1.SecretReferenceGrantList{}
+	if err := c.client.List(ctx, grants, client.InNamespace(secret.Namespace)); err != nil {
+		return fmt.Errorf("failed to list secret reference grants in %s: %w", secret.Namespace, err)
+	}
+
+	for i := range grants.Items {
+		if grants.Items[i].Allows(referrer, secret.Name) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("secret %s is not granted to namespace %s", secret, referrer)
+}
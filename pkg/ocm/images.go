@@ -0,0 +1,338 @@
+package ocm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/open-component-model/ocm/pkg/contexts/credentials"
+	"github.com/open-component-model/ocm/pkg/contexts/credentials/builtin/oci/identity"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/accessmethods/ociartifact"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/resourcetypes"
+	"github.com/open-component-model/ocm/pkg/signing/signutils"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/open-component-model/replication-controller/api/v1alpha1"
+	"github.com/open-component-model/replication-controller/pkg/notation"
+)
+
+// imageResource is an ociImage resource of a component version.
+type imageResource struct {
+	component string
+	resource  string
+	version   string
+	reference string
+}
+
+// key identifies the resource independent of the repository it is stored in.
+func (i imageResource) key() string {
+	return i.component + "/" + i.resource
+}
+
+// listImages returns the ociImage resources of the component version and all the components it references through
+// references accepted by the filter. Only resources accessed by an OCI reference are returned.
+func listImages(cv ocm.ComponentVersionAccess, selected referenceFilter) ([]imageResource, error) {
+	var result []imageResource
+
+	collect := func(cv ocm.ComponentVersionAccess) error {
+		component := fmt.Sprintf("%s:%s", cv.GetName(), cv.GetVersion())
+		for _, res := range cv.GetResources() {
+			if res.Meta().GetType() != resourcetypes.OCI_IMAGE {
+				continue
+			}
+
+			spec, err := res.Access()
+			if err != nil {
+				return fmt.Errorf("failed to get access of resource %s in %s: %w", res.Meta().GetName(), component, err)
+			}
+
+			reference := ""
+			if global := spec.GlobalAccessSpec(cv.GetContext()); global != nil {
+				spec = global
+			}
+
+			if acc, ok := spec.(*ociartifact.AccessSpec); ok {
+				reference = acc.ImageReference
+			}
+
+			if reference == "" {
+				continue
+			}
+
+			version := res.Meta().GetVersion()
+			if version == "" {
+				version = cv.GetVersion()
+			}
+
+			result = append(result, imageResource{
+				component: component,
+				resource:  res.Meta().GetName(),
+				version:   version,
+				reference: reference,
+			})
+		}
+
+		return nil
+	}
+
+	if err := collect(cv); err != nil {
+		return nil, err
+	}
+
+	var walkErr error
+	if err := walkReferences(cv, selected, func(ref ocm.ComponentVersionAccess) {
+		if walkErr == nil {
+			walkErr = collect(ref)
+		}
+	}); err != nil {
+		return nil, err
+	}
+
+	return result, walkErr
+}
+
+// parseReference parses an image reference that may be prefixed with a scheme like the URLs of OCM repositories.
+func parseReference(reference string) (name.Reference, error) {
+	// OCM accepts an explicit scheme for registries served over plain HTTP.
+	var opts []name.Option
+	if plain, ok := strings.CutPrefix(reference, "http://"); ok {
+		reference = plain
+		opts = append(opts, name.Insecure)
+	}
+
+	ref, err := name.ParseReference(strings.TrimPrefix(reference, "https://"), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse image reference %s: %w", reference, err)
+	}
+
+	return ref, nil
+}
+
+// resolveDigest returns the digest reference of an image, looking up the digest of tagged references.
+func resolveDigest(reference string, opts ...remote.Option) (name.Digest, error) {
+	ref, err := parseReference(reference)
+	if err != nil {
+		return name.Digest{}, err
+	}
+
+	if digest, ok := ref.(name.Digest); ok {
+		return digest, nil
+	}
+
+	desc, err := remote.Head(ref, opts...)
+	if err != nil {
+		return name.Digest{}, fmt.Errorf("failed to resolve image %s: %w", reference, err)
+	}
+
+	return ref.Context().Digest(desc.Digest.String()), nil
+}
+
+// verifyImages verifies the Notary Project signatures of all images of the component version and records the results
+// in the status of the subscription. It fails if any image could not be verified.
+func (c *Client) verifyImages(ctx context.Context, octx ocm.Context, obj *v1alpha1.ComponentSubscription, cv ocm.ComponentVersionAccess) error {
+	verification := obj.Spec.VerifyImages
+
+	stores := make(notation.TrustStores, len(verification.TrustStores))
+	for _, store := range verification.TrustStores {
+		data, err := c.getCACertificates(ctx, obj.Namespace, store.Name)
+		if err != nil {
+			return fmt.Errorf("failed to get trust store %s: %w", store.Name, err)
+		}
+
+		certs, err := signutils.GetCertificateChain(data, false)
+		if err != nil {
+			return fmt.Errorf("failed to parse root certificates in trust store %s: %w", store.Name, err)
+		}
+
+		stores[store.Name] = certs
+	}
+
+	verifier, err := notation.NewVerifier(stores, verification.TrustedIdentities)
+	if err != nil {
+		return err
+	}
+
+	images, err := listImages(cv, selectedReferences(obj))
+	if err != nil {
+		return fmt.Errorf("failed to list images: %w", err)
+	}
+
+	remoteOpts, err := c.registryOptions(ctx, octx, obj)
+	if err != nil {
+		return err
+	}
+	obj.Status.ImageVerifications = nil
+
+	var failed []string
+	for _, image := range images {
+		result := v1alpha1.ImageSignatureVerification{
+			Component: image.component,
+			Resource:  image.resource,
+			Image:     image.reference,
+		}
+
+		signature, err := verifyImage(ctx, verifier, image, remoteOpts...)
+		if err != nil {
+			result.Message = err.Error()
+			failed = append(failed, image.key())
+		} else {
+			result.Image = signature.image
+			result.Verified = true
+			result.Signer = signature.Subject
+		}
+
+		obj.Status.ImageVerifications = append(obj.Status.ImageVerifications, result)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to verify signatures of images: %s", strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// imageSignature is a verified signature of the image with the given digest reference.
+type imageSignature struct {
+	notation.Signature
+	image string
+}
+
+func verifyImage(ctx context.Context, verifier *notation.Verifier, image imageResource, opts ...remote.Option) (imageSignature, error) {
+	digest, err := resolveDigest(image.reference, opts...)
+	if err != nil {
+		return imageSignature{}, err
+	}
+
+	signature, err := verifier.Verify(ctx, digest, opts...)
+	if err != nil {
+		return imageSignature{}, err
+	}
+
+	return imageSignature{Signature: signature, image: digest.String()}, nil
+}
+
+// copyImageSignatures copies the Notary Project signatures of all images of the source component version to the
+// copies of the images referenced by the destination component version.
+func (c *Client) copyImageSignatures(
+	ctx context.Context,
+	octx ocm.Context,
+	obj *v1alpha1.ComponentSubscription,
+	source, destination ocm.ComponentVersionAccess,
+) error {
+	logger := log.FromContext(ctx)
+
+	// excluded references aren't in the destination.
+	sourceImages, err := listImages(source, transferredReferences(obj))
+	if err != nil {
+		return fmt.Errorf("failed to list source images: %w", err)
+	}
+
+	destinationImages, err := listImages(destination, transferredReferences(obj))
+	if err != nil {
+		return fmt.Errorf("failed to list destination images: %w", err)
+	}
+
+	copies := make(map[string]string, len(destinationImages))
+	for _, image := range destinationImages {
+		copies[image.key()] = image.reference
+	}
+
+	opts, err := c.registryOptions(ctx, octx, obj)
+	if err != nil {
+		return err
+	}
+	for _, image := range sourceImages {
+		reference, ok := copies[image.key()]
+		if !ok || reference == image.reference {
+			continue
+		}
+
+		src, err := resolveDigest(image.reference, opts...)
+		if err != nil {
+			return err
+		}
+
+		dst, err := resolveDigest(reference, opts...)
+		if err != nil {
+			return err
+		}
+
+		// signatures of image indexes that were trimmed to some platforms don't apply to the copy.
+		if src.DigestStr() != dst.DigestStr() {
+			logger.V(4).Info("skipping signatures of modified image", "resource", image.key(), "image", dst.String())
+
+			continue
+		}
+
+		n, err := notation.CopySignatures(ctx, src, dst, opts...)
+		if err != nil {
+			return fmt.Errorf("failed to copy signatures of %s: %w", image.key(), err)
+		}
+
+		logger.V(4).Info("copied image signatures", "resource", image.key(), "image", dst.String(), "signatures", n)
+	}
+
+	return nil
+}
+
+// registryOptions returns the options to access OCI registries with the credentials and certificates configured in
+// the OCM context. Registries of insecure repositories of the subscription are accessed without verification and
+// the proxies configured for the repositories are used.
+func (c *Client) registryOptions(ctx context.Context, octx ocm.Context, obj *v1alpha1.ComponentSubscription) ([]remote.Option, error) {
+	proxies, err := c.registryProxies(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return []remote.Option{
+		remote.WithContext(ctx),
+		remote.WithAuthFromKeychain(&keychain{octx: octx}),
+		remote.WithTransport(newRegistryTransport(octx, proxies, RegistryRepositories(obj)...)),
+	}, nil
+}
+
+// keychain resolves registry credentials from the credentials configured in an OCM context.
+type keychain struct {
+	octx ocm.Context
+}
+
+var _ authn.Keychain = &keychain{}
+
+// Resolve returns the credentials configured for the repository or anonymous access if there are none.
+func (k *keychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	repository := strings.TrimPrefix(strings.TrimPrefix(target.String(), target.RegistryStr()), "/")
+
+	creds, err := identity.GetCredentials(k.octx, target.RegistryStr(), repository)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials for %s: %w", target, err)
+	}
+
+	// credentials holding only certificates don't authenticate.
+	if creds == nil || (creds.GetProperty(identity.ATTR_USERNAME) == "" && creds.GetProperty(identity.ATTR_IDENTITY_TOKEN) == "") {
+		return authn.Anonymous, nil
+	}
+
+	return &credentialsAuthenticator{creds: creds}, nil
+}
+
+// credentialsAuthenticator reads the credentials whenever the registry asks for them, e.g. to renew an expired
+// token, so refreshed credentials are used.
+type credentialsAuthenticator struct {
+	creds credentials.Credentials
+}
+
+var _ authn.Authenticator = &credentialsAuthenticator{}
+
+func (a *credentialsAuthenticator) Authorization() (*authn.AuthConfig, error) {
+	properties := a.creds.Properties()
+
+	return &authn.AuthConfig{
+		Username:      properties[identity.ATTR_USERNAME],
+		Password:      properties[identity.ATTR_PASSWORD],
+		IdentityToken: properties[identity.ATTR_IDENTITY_TOKEN],
+	}, nil
+}
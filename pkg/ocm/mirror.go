@@ -0,0 +1,253 @@
+package ocm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	ggcrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/repositories/genericocireg"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/open-component-model/replication-controller/api/v1alpha1"
+	"github.com/open-component-model/replication-controller/pkg/notation"
+)
+
+// MirrorImages pushes the ociImage resources of the component version and all the components it references as
+// plain tagged images to the repository configured in the subscription. The mirrored images are recorded in the
+// status of the subscription and the optional ConfigMap. Without a destination, the component version is not
+// transferred, so the signatures are verified here instead. Notary Project signatures are copied along with images
+// that were not trimmed if the subscription copies image signatures.
+func (c *Client) MirrorImages(ctx context.Context, octx ocm.Context, obj *v1alpha1.ComponentSubscription, cv ocm.ComponentVersionAccess) error {
+	logger := log.FromContext(ctx)
+	mirror := obj.Spec.MirrorImages
+
+	if obj.Spec.Destination == nil {
+		ok, err := c.VerifyComponent(ctx, obj, cv)
+		if err != nil {
+			return fmt.Errorf("failed to verify signature: %w", err)
+		}
+
+		if !ok {
+			return fmt.Errorf("one of the signatures failed to match")
+		}
+
+		if obj.Spec.VerifyImages != nil {
+			if err := c.verifyImages(ctx, octx, obj, cv); err != nil {
+				return fmt.Errorf("failed to verify images: %w", err)
+			}
+		}
+	}
+
+	var platforms []ggcrv1.Platform
+	if obj.Spec.Transfer != nil {
+		for _, platform := range obj.Spec.Transfer.Platforms {
+			os, arch, err := splitPlatform(platform)
+			if err != nil {
+				return err
+			}
+
+			platforms = append(platforms, ggcrv1.Platform{OS: os, Architecture: arch})
+		}
+	}
+
+	images, err := listImages(cv, selectedReferences(obj))
+	if err != nil {
+		return fmt.Errorf("failed to list images: %w", err)
+	}
+
+	opts, err := c.registryOptions(ctx, octx, obj)
+	if err != nil {
+		return err
+	}
+	copySignatures := obj.Spec.VerifyImages != nil && obj.Spec.VerifyImages.CopySignatures
+	sources := make(map[string]name.Digest, len(images))
+
+	var mirrored []v1alpha1.MirroredImage
+	for _, image := range images {
+		source, err := resolveDigest(image.reference, opts...)
+		if err != nil {
+			return err
+		}
+
+		component, _, _ := strings.Cut(image.component, ":")
+		tag := strings.ReplaceAll(image.version, "+", genericocireg.META_SEPARATOR)
+		target, err := parseReference(strings.TrimSuffix(repositoryURL(mirror.Destination), "/") + "/" + component + "/" + image.resource + ":" + tag)
+		if err != nil {
+			return err
+		}
+
+		// the same component version may be referenced several times.
+		if existing, ok := sources[target.String()]; ok {
+			if existing.DigestStr() != source.DigestStr() {
+				return fmt.Errorf("image resources %s and %s are both mirrored to %s", existing, source, target)
+			}
+
+			continue
+		}
+		sources[target.String()] = source
+
+		digest, err := copyImage(source, target, platforms, opts...)
+		if err != nil {
+			return fmt.Errorf("failed to mirror %s: %w", image.key(), err)
+		}
+
+		logger.V(4).Info("mirrored image", "resource", image.key(), "image", target.String(), "digest", digest.String())
+
+		// signatures of image indexes that were trimmed to some platforms don't apply to the copy.
+		if copySignatures && digest.String() == source.DigestStr() {
+			n, err := notation.CopySignatures(ctx, source, target.Context().Digest(digest.String()), opts...)
+			if err != nil {
+				return fmt.Errorf("failed to copy signatures of %s: %w", image.key(), err)
+			}
+
+			logger.V(4).Info("copied image signatures", "resource", image.key(), "image", target.String(), "signatures", n)
+		}
+
+		mirrored = append(mirrored, v1alpha1.MirroredImage{
+			Component: image.component,
+			Resource:  image.resource,
+			Source:    source.String(),
+			Image:     target.String(),
+			Digest:    digest.String(),
+		})
+	}
+
+	obj.Status.MirroredImages = mirrored
+
+	if mirror.ConfigMapName == "" {
+		return nil
+	}
+
+	return c.writeMirroredImages(ctx, obj)
+}
+
+// copyImage copies the source image to the target reference. Image indexes are trimmed to the given platforms, if
+// any. It returns the digest of the copy.
+func copyImage(source name.Digest, target name.Reference, platforms []ggcrv1.Platform, opts ...remote.Option) (ggcrv1.Hash, error) {
+	desc, err := remote.Get(source, opts...)
+	if err != nil {
+		return ggcrv1.Hash{}, fmt.Errorf("failed to get image %s: %w", source, err)
+	}
+
+	if !desc.MediaType.IsIndex() {
+		img, err := desc.Image()
+		if err != nil {
+			return ggcrv1.Hash{}, fmt.Errorf("failed to read image %s: %w", source, err)
+		}
+
+		if err := remote.Write(target, img, opts...); err != nil {
+			return ggcrv1.Hash{}, fmt.Errorf("failed to push image %s: %w", target, err)
+		}
+
+		return desc.Digest, nil
+	}
+
+	index, err := desc.ImageIndex()
+	if err != nil {
+		return ggcrv1.Hash{}, fmt.Errorf("failed to read image index %s: %w", source, err)
+	}
+
+	if len(platforms) > 0 {
+		manifest, err := index.IndexManifest()
+		if err != nil {
+			return ggcrv1.Hash{}, fmt.Errorf("failed to read image index %s: %w", source, err)
+		}
+
+		var selected []ggcrv1.Descriptor
+		for _, m := range manifest.Manifests {
+			if matchesPlatform(m, platforms) {
+				selected = append(selected, m)
+			}
+		}
+
+		switch len(selected) {
+		case 0:
+			return ggcrv1.Hash{}, fmt.Errorf("image index %s contains no image for the selected platforms", desc.Digest)
+		case 1:
+			// like OCM, a single remaining image replaces the index.
+			img, err := index.Image(selected[0].Digest)
+			if err != nil {
+				return ggcrv1.Hash{}, fmt.Errorf("failed to read image %s: %w", selected[0].Digest, err)
+			}
+
+			if err := remote.Write(target, img, opts...); err != nil {
+				return ggcrv1.Hash{}, fmt.Errorf("failed to push image %s: %w", target, err)
+			}
+
+			return selected[0].Digest, nil
+		}
+
+		if len(selected) < len(manifest.Manifests) {
+			index = mutate.RemoveManifests(index, func(m ggcrv1.Descriptor) bool {
+				return !matchesPlatform(m, platforms)
+			})
+		}
+	}
+
+	if err := remote.WriteIndex(target, index, opts...); err != nil {
+		return ggcrv1.Hash{}, fmt.Errorf("failed to push image index %s: %w", target, err)
+	}
+
+	digest, err := index.Digest()
+	if err != nil {
+		return ggcrv1.Hash{}, fmt.Errorf("failed to compute digest of image index: %w", err)
+	}
+
+	return digest, nil
+}
+
+// matchesPlatform returns whether the operating system and architecture of the manifest match any of the platforms.
+// Manifests without a platform, e.g. attestations, never match.
+func matchesPlatform(desc ggcrv1.Descriptor, platforms []ggcrv1.Platform) bool {
+	if desc.Platform == nil {
+		return false
+	}
+
+	for _, platform := range platforms {
+		if desc.Platform.OS == platform.OS && desc.Platform.Architecture == platform.Architecture {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mirroredImageKey returns the ConfigMap key of a mirrored image. It is made of the component name and the resource
+// name with slashes replaced by underscores, as ConfigMap keys must not contain slashes.
+func mirroredImageKey(image v1alpha1.MirroredImage) string {
+	component, _, _ := strings.Cut(image.Component, ":")
+
+	return strings.ReplaceAll(component+"/"+image.Resource, "/", "_")
+}
+
+// writeMirroredImages writes the references of the mirrored images to the ConfigMap configured in the subscription.
+// The ConfigMap is owned by the subscription.
+func (c *Client) writeMirroredImages(ctx context.Context, obj *v1alpha1.ComponentSubscription) error {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      obj.Spec.MirrorImages.ConfigMapName,
+			Namespace: obj.Namespace,
+		},
+	}
+
+	if _, err := controllerutil.CreateOrUpdate(ctx, c.client, cm, func() error {
+		cm.Data = make(map[string]string, len(obj.Status.MirroredImages))
+		for _, image := range obj.Status.MirroredImages {
+			cm.Data[mirroredImageKey(image)] = image.Image + "@" + image.Digest
+		}
+
+		return controllerutil.SetOwnerReference(obj, cm, c.client.Scheme())
+	}); err != nil {
+		return fmt.Errorf("failed to write mirrored images to config map %s: %w", cm.Name, err)
+	}
+
+	return nil
+}
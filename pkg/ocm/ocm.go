@@ -2,9 +2,14 @@ package ocm
 
 import (
 	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"errors"
 	"fmt"
+	"path"
 	"sort"
+	"sync"
+	"time"
 
 	"github.com/Masterminds/semver"
 	"github.com/go-logr/logr"
@@ -13,34 +18,77 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
-	csdk "github.com/open-component-model/ocm-controller/pkg/ocm"
-	"github.com/open-component-model/ocm/pkg/contexts/credentials/repositories/dockerconfig"
+	ocmv1alpha1 "github.com/open-component-model/ocm-controller/api/v1alpha1"
 	"github.com/open-component-model/ocm/pkg/contexts/ocm"
 	"github.com/open-component-model/ocm/pkg/contexts/ocm/attrs/signingattr"
-	"github.com/open-component-model/ocm/pkg/contexts/ocm/repositories/ocireg"
 	"github.com/open-component-model/ocm/pkg/contexts/ocm/signing"
 	"github.com/open-component-model/ocm/pkg/contexts/ocm/transfer"
 	"github.com/open-component-model/ocm/pkg/contexts/ocm/transfer/transferhandler/standard"
 	ocmsigning "github.com/open-component-model/ocm/pkg/signing"
 	"github.com/open-component-model/ocm/pkg/signing/handlers/rsa"
+	"github.com/open-component-model/ocm/pkg/signing/signutils"
 
 	"github.com/open-component-model/replication-controller/api/v1alpha1"
+	"github.com/open-component-model/replication-controller/pkg/attestation"
+	"github.com/open-component-model/replication-controller/pkg/keys"
 	"github.com/open-component-model/replication-controller/pkg/sign"
 )
 
-const dockerConfigKey = ".dockerconfigjson"
+const caCertKey = "ca.crt"
+
+// transferOptions are the options every component version is transferred with.
+var transferOptions = attestation.TransferOptions{
+	Recursive:        true,
+	ResourcesByValue: true,
+	Overwrite:        true,
+}
+
+// getTransferOptions returns the options the component version of the subscription is transferred with. Referenced
+// components aren't transferred if the subscription follows them with subscriptions of their own.
+func getTransferOptions(obj *v1alpha1.ComponentSubscription) attestation.TransferOptions {
+	options := transferOptions
+	options.Recursive = !obj.Spec.FollowReferences
+	if obj.Spec.Transfer != nil {
+		options.Platforms = obj.Spec.Transfer.Platforms
+	}
+
+	return options
+}
 
 // Contract defines a subset of capabilities from the OCM library.
 type Contract interface {
 	CreateAuthenticatedOCMContext(ctx context.Context, obj *v1alpha1.ComponentSubscription) (ocm.Context, error)
 	VerifyComponent(ctx context.Context, obj *v1alpha1.ComponentSubscription, cv ocm.ComponentVersionAccess) (bool, error)
-	SignDestinationComponent(ctx context.Context, component ocm.ComponentVersionAccess) ([]byte, error)
+	SignDestinationComponent(
+		ctx context.Context,
+		obj *v1alpha1.ComponentSubscription,
+		component ocm.ComponentVersionAccess,
+	) ([]byte, error)
 	GetComponentVersion(
 		ctx context.Context,
 		octx ocm.Context,
 		obj *v1alpha1.ComponentSubscription,
 		version string,
 	) (ocm.ComponentVersionAccess, error)
+	AddProvenance(
+		ctx context.Context,
+		obj *v1alpha1.ComponentSubscription,
+		source ocm.ComponentVersionAccess,
+		destination ocm.ComponentVersionAccess,
+	) error
+	AttestReplication(
+		ctx context.Context,
+		obj *v1alpha1.ComponentSubscription,
+		source ocm.ComponentVersionAccess,
+		destination ocm.ComponentVersionAccess,
+		startedOn time.Time,
+	) (*v1alpha1.ReplicationAttestation, error)
+	GetDestinationComponentVersion(
+		ctx context.Context,
+		octx ocm.Context,
+		obj *v1alpha1.ComponentSubscription,
+		version string,
+	) (ocm.ComponentVersionAccess, error)
 	GetLatestSourceComponentVersion(ctx context.Context, octx ocm.Context, obj *v1alpha1.ComponentSubscription) (string, error)
 	TransferComponent(
 		ctx context.Context,
@@ -48,42 +96,220 @@ type Contract interface {
 		obj *v1alpha1.ComponentSubscription,
 		sourceComponentVersion ocm.ComponentVersionAccess,
 	) error
+	MirrorImages(ctx context.Context, octx ocm.Context, obj *v1alpha1.ComponentSubscription, cv ocm.ComponentVersionAccess) error
+	ListComponents(ctx context.Context, octx ocm.Context, obj *v1alpha1.ComponentSubscription) ([]string, error)
+	ListPendingVersions(ctx context.Context, octx ocm.Context, obj *v1alpha1.ComponentSubscription, mirrored map[string]string) ([]string, []v1alpha1.MirrorQueueEntry, error)
+	AuthorizeSecretReference(ctx context.Context, referrer string, secret types.NamespacedName) error
 }
 
 // Client implements the OCM fetcher interface.
 type Client struct {
 	client client.Client
+
+	// signingCertificateSecret optionally references a Secret holding a private key and the certificate
+	// chain issued for it. If set, components are signed with it instead of a generated key pair.
+	signingCertificateSecret *types.NamespacedName
+
+	// signer optionally signs components with a key the controller has no direct access to. It takes precedence
+	// over the signing certificate Secret.
+	signer sign.Signer
+
+	// publicKeys records the public keys used for signing so they can be published to consumers.
+	publicKeys *keys.Store
+
+	// publicKeysConfigMap optionally references a ConfigMap the recorded public keys are exported to.
+	publicKeysConfigMap *types.NamespacedName
+
+	// defaultConfig optionally references an OCM configuration applied to the context of every subscription.
+	defaultConfig *ConfigReference
+
+	// noCrossNamespaceRefs rejects references to Secrets of other namespaces, even if they are granted.
+	noCrossNamespaceRefs bool
+
+	// credentialHelpers runs the credential helpers of repositories and caches their credentials across reconciles.
+	credentialHelpers *credentialHelpers
+
+	// generatedKey signs components and attestations if neither a signer nor a signing certificate is configured.
+	generatedKey *generatedKey
 }
 
 var _ Contract = &Client{}
 
+// ClientOption configures optional behaviour of the Client.
+type ClientOption func(c *Client)
+
+// WithSigningCertificateSecret configures the Client to sign components using the private key and the
+// certificate chain stored in the given kubernetes.io/tls Secret. An optional `ca.crt` key may contain the
+// root certificates the chain is validated against.
+func WithSigningCertificateSecret(key types.NamespacedName) ClientOption {
+	return func(c *Client) {
+		c.signingCertificateSecret = &key
+	}
+}
+
+// WithSigner configures the Client to sign components using the given Signer, e.g. backed by an HSM or an
+// external signing binary.
+func WithSigner(signer sign.Signer) ClientOption {
+	return func(c *Client) {
+		c.signer = signer
+	}
+}
+
+// WithPublicKeyStore configures the Client to record every public key used for signing in the given Store.
+func WithPublicKeyStore(store *keys.Store) ClientOption {
+	return func(c *Client) {
+		c.publicKeys = store
+	}
+}
+
+// WithPublicKeyConfigMap configures the Client to export the recorded public keys to the given ConfigMap after
+// signing. It has no effect unless a public key Store is configured as well.
+func WithPublicKeyConfigMap(key types.NamespacedName) ClientOption {
+	return func(c *Client) {
+		c.publicKeysConfigMap = &key
+	}
+}
+
+// WithoutCrossNamespaceReferences configures the Client to reject references of subscriptions to Secrets of
+// other namespaces regardless of SecretReferenceGrants, e.g. for strict multi-tenancy.
+func WithoutCrossNamespaceReferences() ClientOption {
+	return func(c *Client) {
+		c.noCrossNamespaceRefs = true
+	}
+}
+
+// WithCredentialHelpers configures the credential helper binaries repositories may run to obtain credentials.
+// Without it, credential helpers are rejected.
+func WithCredentialHelpers(commands ...string) ClientOption {
+	return func(c *Client) {
+		c.credentialHelpers.allowed = commands
+	}
+}
+
+// WithCredentialHelperEnv configures the environment variables repositories may set for credential helpers.
+// Without it, credential helpers run with the environment of the controller only.
+func WithCredentialHelperEnv(names ...string) ClientOption {
+	return func(c *Client) {
+		c.credentialHelpers.allowedEnv = names
+	}
+}
+
+// WithKeyRotation configures the Client to replace the generated signing key once it is older than the given
+// interval. Without it, the generated key is kept until the controller restarts.
+func WithKeyRotation(interval time.Duration) ClientOption {
+	return func(c *Client) {
+		c.generatedKey.interval = interval
+	}
+}
+
 // NewClient creates a new fetcher Client using the provided k8s client.
-func NewClient(client client.Client) *Client {
-	return &Client{
-		client: client,
+func NewClient(client client.Client, opts ...ClientOption) *Client {
+	c := &Client{
+		client:            client,
+		credentialHelpers: newCredentialHelpers(),
+		generatedKey:      &generatedKey{now: time.Now},
+	}
+
+	for _, o := range opts {
+		o(c)
 	}
+
+	return c
 }
 
-// SignDestinationComponent signs the component before transferring it and returns the public key for storing it on the
-// subscription.
-func (c *Client) SignDestinationComponent(_ context.Context, component ocm.ComponentVersionAccess) ([]byte, error) {
-	priv, pub, err := sign.GenerateSigningKeyPEMPair()
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+// SignDestinationComponent signs the replicated copy of a component in the destination repository and returns the public
+// key for storing it on the subscription. The component must have been looked up from the destination repository, the
+// signature is persisted once it is closed. The source repository is never touched. If a signer or a signing
+// certificate is configured, the returned value is its PEM encoded public key or certificate chain. Referenced
+// components are signed as well if requested by the subscription.
+func (c *Client) SignDestinationComponent(
+	ctx context.Context,
+	obj *v1alpha1.ComponentSubscription,
+	component ocm.ComponentVersionAccess,
+) ([]byte, error) {
+	resolvers := []ocm.ComponentVersionResolver{component.Repository()}
+	if obj.Spec.FollowReferences {
+		source, err := component.GetContext().RepositoryForSpec(repositorySpec(component.GetContext(), obj.Spec.Source))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get source repo: %w", err)
+		}
+		defer source.Close()
+
+		// followed references are replicated by their own subscriptions once the component is signed, so the
+		// digests of the references are calculated from the source descriptors.
+		resolvers = []ocm.ComponentVersionResolver{source, component.Repository()}
 	}
 
-	resolver := ocm.NewCompoundResolver(component.Repository())
-	opts := signing.NewOptions(
-		signing.Sign(
-			ocmsigning.DefaultHandlerRegistry().GetSigner(rsa.Algorithm),
-			v1alpha1.InternalSignatureName,
-		),
-		signing.Resolver(resolver),
-		signing.PrivateKey(v1alpha1.InternalSignatureName, priv),
+	if fallback := obj.Spec.Transfer.GetFallback(); fallback != nil {
+		repository, err := component.GetContext().RepositoryForSpec(repositorySpec(component.GetContext(), *fallback))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get fallback repo: %w", err)
+		}
+		defer repository.Close()
+
+		// excluded references are resolved from the fallback repository.
+		resolvers = append(resolvers, repository)
+	}
+
+	signOpts := []signing.Option{
+		signing.Resolver(ocm.NewCompoundResolver(resolvers...)),
 		signing.Update(),
 		signing.VerifyDigests(),
-	)
+		// followed references are signed by their own subscriptions.
+		signing.Recursive(obj.Spec.VerifyReferences != nil && obj.Spec.VerifyReferences.Sign && !obj.Spec.FollowReferences),
+	}
+
+	rsaSigner := signing.Sign(ocmsigning.DefaultHandlerRegistry().GetSigner(rsa.Algorithm), v1alpha1.InternalSignatureName)
+
+	var pub []byte
+	switch {
+	case c.signer != nil:
+		pub = c.signer.PublicKeyPEM()
+		signOpts = append(signOpts,
+			signing.Sign(sign.Handler{}, v1alpha1.InternalSignatureName),
+			signing.PrivateKey(v1alpha1.InternalSignatureName, c.signer),
+			signing.PublicKey(v1alpha1.InternalSignatureName, pub),
+		)
+
+		if certs, err := signutils.ParseCertificateChain(pub, false); err == nil && len(certs) > 0 {
+			signOpts = append(signOpts, signing.PKIXIssuer(certs[0].Subject))
+
+			// a chain ending with its self-signed root is validated against it, otherwise the system roots are used.
+			if root := certs[len(certs)-1]; len(certs) > 1 && root.CheckSignatureFrom(root) == nil {
+				roots := x509.NewCertPool()
+				roots.AddCert(root)
+				signOpts = append(signOpts, signing.RootCertificates(roots))
+			}
+		}
+	case c.signingCertificateSecret != nil:
+		cert, err := c.getSigningCertificate(ctx, *c.signingCertificateSecret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get signing certificate: %w", err)
+		}
+
+		signOpts = append(signOpts,
+			rsaSigner,
+			signing.PrivateKey(v1alpha1.InternalSignatureName, cert.privateKey),
+			signing.PublicKey(v1alpha1.InternalSignatureName, cert.chain),
+			signing.PKIXIssuer(cert.subject),
+		)
 
+		if cert.roots != nil {
+			signOpts = append(signOpts, signing.RootCertificates(cert.roots))
+		}
+
+		pub = cert.chain
+	default:
+		priv, generated, err := c.generatedKey.get()
+		if err != nil {
+			return nil, err
+		}
+
+		signOpts = append(signOpts, rsaSigner, signing.PrivateKey(v1alpha1.InternalSignatureName, priv))
+		pub = generated
+	}
+
+	opts := signing.NewOptions(signOpts...)
 	if err := opts.Complete(signingattr.Get(component.GetContext())); err != nil {
 		return nil, fmt.Errorf("failed to complete signing: %w", err)
 	}
@@ -92,14 +318,142 @@ func (c *Client) SignDestinationComponent(_ context.Context, component ocm.Compo
 		return nil, fmt.Errorf("failed to finalize signing: %w", err)
 	}
 
+	if err := c.publishPublicKey(ctx, pub); err != nil {
+		return nil, fmt.Errorf("failed to publish public key: %w", err)
+	}
+
 	return pub, nil
 }
 
+// publishPublicKey records the public key used for signing if it differs from the current one and exports all
+// recorded keys if configured.
+func (c *Client) publishPublicKey(ctx context.Context, pub []byte) error {
+	if c.publicKeys == nil {
+		return nil
+	}
+
+	key, err := keys.ParseKey(pub)
+	if err != nil {
+		return fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	// only rotations are recorded, signing with the current key leaves the store and the ConfigMap untouched.
+	if current, ok := c.publicKeys.Current(); ok && current.ID == key.ID {
+		return nil
+	}
+
+	if _, err := c.publicKeys.Add(pub); err != nil {
+		return fmt.Errorf("failed to record public key: %w", err)
+	}
+
+	if c.publicKeysConfigMap == nil {
+		return nil
+	}
+
+	return c.publicKeys.ExportConfigMap(ctx, c.client, *c.publicKeysConfigMap)
+}
+
+// generatedKey is the key pair the controller signs with unless a signer or a signing certificate is configured.
+// It's generated on first use, so replication signatures and attestations share it, and replaced once it is older
+// than the rotation interval.
+type generatedKey struct {
+	mu       sync.Mutex
+	priv     []byte
+	pub      []byte
+	created  time.Time
+	interval time.Duration
+	now      func() time.Time
+}
+
+// get returns the PEM encoded private and public key, generating them if needed.
+func (k *generatedKey) get() ([]byte, []byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := k.now()
+	if k.priv == nil || (k.interval > 0 && !now.Before(k.created.Add(k.interval))) {
+		priv, pub, err := sign.GenerateSigningKeyPEMPair()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate signing key: %w", err)
+		}
+
+		k.priv, k.pub, k.created = priv, pub, now
+	}
+
+	return k.priv, k.pub, nil
+}
+
+// signingCertificate holds the key material loaded from a signing certificate Secret.
+type signingCertificate struct {
+	privateKey []byte
+	chain      []byte
+	subject    pkix.Name
+	roots      *x509.CertPool
+}
+
+// getSigningCertificate loads the private key, the certificate chain and the optional root certificates
+// stored in a signing certificate Secret.
+func (c *Client) getSigningCertificate(ctx context.Context, key types.NamespacedName) (*signingCertificate, error) {
+	secret := &corev1.Secret{}
+	if err := c.client.Get(ctx, key, secret); err != nil {
+		return nil, fmt.Errorf("failed to get signing certificate secret: %w", err)
+	}
+
+	priv, ok := secret.Data[corev1.TLSPrivateKeyKey]
+	if !ok {
+		return nil, fmt.Errorf("failed to find %s in secret %s", corev1.TLSPrivateKeyKey, key)
+	}
+
+	chain, ok := secret.Data[corev1.TLSCertKey]
+	if !ok {
+		return nil, fmt.Errorf("failed to find %s in secret %s", corev1.TLSCertKey, key)
+	}
+
+	certs, err := signutils.ParseCertificateChain(chain, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate chain in secret %s: %w", key, err)
+	}
+
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("certificate chain in secret %s is empty", key)
+	}
+
+	result := &signingCertificate{
+		privateKey: priv,
+		chain:      chain,
+		subject:    certs[0].Subject,
+	}
+
+	if data, ok := secret.Data[caCertKey]; ok {
+		if result.roots, err = signutils.GetCertPool(data, false); err != nil {
+			return nil, fmt.Errorf("failed to parse root certificates in secret %s: %w", key, err)
+		}
+	}
+
+	return result, nil
+}
+
 func (c *Client) CreateAuthenticatedOCMContext(ctx context.Context, obj *v1alpha1.ComponentSubscription) (ocm.Context, error) {
 	octx := ocm.New()
 
+	if c.defaultConfig != nil {
+		if err := c.applyConfig(ctx, octx, *c.defaultConfig, false); err != nil {
+			return nil, fmt.Errorf("failed to configure default ocm config: %w", err)
+		}
+	}
+
+	if obj.Spec.OCMConfigRef != nil {
+		ref := ConfigReference{
+			Kind:           obj.Spec.OCMConfigRef.Kind,
+			NamespacedName: types.NamespacedName{Namespace: obj.Namespace, Name: obj.Spec.OCMConfigRef.Name},
+		}
+		if err := c.applyConfig(ctx, octx, ref, true); err != nil {
+			return nil, fmt.Errorf("failed to configure ocm config: %w", err)
+		}
+	}
+
 	if obj.Spec.ServiceAccountName != "" {
-		if err := c.configureServiceAccountAccess(ctx, octx, obj.Spec.ServiceAccountName, obj.Namespace); err != nil {
+		if err := c.configureServiceAccountAccess(ctx, octx, obj); err != nil {
 			return nil, fmt.Errorf("failed to configure service account access: %w", err)
 		}
 	}
@@ -114,6 +468,22 @@ func (c *Client) CreateAuthenticatedOCMContext(ctx context.Context, obj *v1alpha
 		}
 	}
 
+	if obj.Spec.MirrorImages != nil {
+		if err := c.configureAccessCredentials(ctx, octx, obj.Spec.MirrorImages.Destination, obj.Namespace); err != nil {
+			return nil, fmt.Errorf("failed to configure credentials for image mirror: %w", err)
+		}
+	}
+
+	if fallback := obj.Spec.Transfer.GetFallback(); fallback != nil {
+		if err := c.configureAccessCredentials(ctx, octx, *fallback, obj.Namespace); err != nil {
+			return nil, fmt.Errorf("failed to configure credentials for fallback repository: %w", err)
+		}
+	}
+
+	if err := c.configureRegistryTransport(ctx, octx, obj); err != nil {
+		return nil, fmt.Errorf("failed to configure registry transport: %w", err)
+	}
+
 	return octx, nil
 }
 
@@ -124,8 +494,31 @@ func (c *Client) GetComponentVersion(
 	obj *v1alpha1.ComponentSubscription,
 	version string,
 ) (ocm.ComponentVersionAccess, error) {
-	repoSpec := ocireg.NewRepositorySpec(obj.Spec.Source.URL, nil)
-	repo, err := octx.RepositoryForSpec(repoSpec)
+	return c.lookupComponentVersion(ctx, octx, obj.Spec.Source, obj.Spec.Component, version)
+}
+
+// GetDestinationComponentVersion returns the replicated component Version from the destination repository. It's the
+// caller's responsibility to close the component Version once done with it.
+func (c *Client) GetDestinationComponentVersion(
+	ctx context.Context,
+	octx ocm.Context,
+	obj *v1alpha1.ComponentSubscription,
+	version string,
+) (ocm.ComponentVersionAccess, error) {
+	if obj.Spec.Destination == nil {
+		return nil, fmt.Errorf("destination repository is not set")
+	}
+
+	return c.lookupComponentVersion(ctx, octx, *obj.Spec.Destination, obj.Spec.Component, version)
+}
+
+func (c *Client) lookupComponentVersion(
+	ctx context.Context,
+	octx ocm.Context,
+	repository v1alpha1.OCMRepository,
+	component, version string,
+) (ocm.ComponentVersionAccess, error) {
+	repo, err := octx.RepositoryForSpec(repositorySpec(octx, repository))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get repository for spec: %w", err)
 	}
@@ -133,9 +526,9 @@ func (c *Client) GetComponentVersion(
 
 	logger := log.FromContext(ctx)
 
-	logger.Info("fetching component version", "component", obj.Spec.Component, "version", version)
+	logger.Info("fetching component version", "component", component, "version", version, "repository", repository.URL)
 
-	cv, err := repo.LookupComponentVersion(obj.Spec.Component, version)
+	cv, err := repo.LookupComponentVersion(component, version)
 	if err != nil {
 		return nil, fmt.Errorf("failed to look up component Version: %w", err)
 	}
@@ -145,66 +538,406 @@ func (c *Client) GetComponentVersion(
 	return cv, nil
 }
 
+// VerifyComponent verifies the signatures configured on the subscription and the signatures required by any
+// applicable TrustPolicy according to their verification policies. Component versions transferred along with it
+// must satisfy the TrustPolicies matching them. If reference verification is enabled, every referenced component
+// version is verified against the signatures of the subscription as well. The outcome for each signature is
+// recorded in the status of the subscription.
 func (c *Client) VerifyComponent(ctx context.Context, obj *v1alpha1.ComponentSubscription, cv ocm.ComponentVersionAccess) (bool, error) {
-	for _, signature := range obj.Spec.Verify {
-		var (
-			cert []byte
-			err  error
-		)
+	policies := &v1alpha1.TrustPolicyList{}
+	if err := c.client.List(ctx, policies); err != nil {
+		return false, fmt.Errorf("verify error: failed to list trust policies: %w", err)
+	}
 
-		if signature.PublicKey.Value != "" {
-			cert, err = signature.PublicKey.DecodePublicValue()
-		} else {
-			if signature.PublicKey.SecretRef == nil {
-				return false, fmt.Errorf("kubernetes secret reference not provided")
+	sets, err := getSignatureSets(obj, policies.Items)
+	if err != nil {
+		return false, fmt.Errorf("verify error: %w", err)
+	}
+
+	obj.Status.Verifications = nil
+	errs := c.verifySignatureSets(ctx, obj, cv, "", sets)
+
+	// trust policies apply to the transferred references whether reference verification is enabled or not.
+	selected := transferredReferences(obj)
+	if obj.Spec.VerifyReferences != nil {
+		selected = selectedReferences(obj)
+	}
+
+	if obj.Spec.VerifyReferences != nil || len(policies.Items) > 0 {
+		if err := walkReferences(cv, selected, func(ref ocm.ComponentVersionAccess) {
+			component := fmt.Sprintf("%s:%s", ref.GetName(), ref.GetVersion())
+			sets := getReferenceSignatureSets(obj, policies.Items, ref.GetName())
+
+			for _, err := range c.verifySignatureSets(ctx, obj, ref, component, sets) {
+				errs = append(errs, fmt.Errorf("referenced component %s: %w", component, err))
 			}
+		}); err != nil {
+			return false, fmt.Errorf("verify error: %w", err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return false, errors.Join(errs...)
+	}
+
+	return true, nil
+}
+
+// signatureSet groups signatures that are verified together under a single verification policy.
+type signatureSet struct {
+	trustPolicy string
+	// referrer is the namespace of the subscription the Secrets are referenced from. It is empty for trust
+	// policies.
+	referrer           string
+	namespace          string
+	verify             []ocmv1alpha1.Signature
+	verifyCertificates []v1alpha1.CertificateSignature
+	policy             *v1alpha1.VerificationPolicy
+}
+
+// getSignatureSets returns the signatures configured on the subscription followed by those of every
+// TrustPolicy that either matches the component or is referenced by the subscription.
+func getSignatureSets(obj *v1alpha1.ComponentSubscription, policies []v1alpha1.TrustPolicy) ([]signatureSet, error) {
+	sets := []signatureSet{
+		{
+			referrer:           obj.Namespace,
+			namespace:          verifySecretNamespace(obj),
+			verify:             obj.Spec.Verify,
+			verifyCertificates: obj.Spec.VerifyCertificates,
+			policy:             obj.Spec.VerificationPolicy,
+		},
+	}
+
+	referenced := make(map[string]bool, len(obj.Spec.TrustPolicies))
+	for _, name := range obj.Spec.TrustPolicies {
+		referenced[name] = true
+	}
 
-			cert, err = c.getPublicKey(
-				ctx,
-				obj.Namespace,
-				signature.PublicKey.SecretRef.Name,
-				signature.Name,
-			)
+	for _, policy := range policies {
+		if !referenced[policy.Name] && !policy.Matches(obj.Spec.Component) {
+			continue
 		}
-		if err != nil {
-			return false, fmt.Errorf("verify error: %w", err)
+
+		delete(referenced, policy.Name)
+
+		sets = append(sets, trustPolicySignatureSet(policy))
+	}
+
+	for _, name := range obj.Spec.TrustPolicies {
+		if referenced[name] {
+			return nil, fmt.Errorf("trust policy %s not found", name)
 		}
+	}
 
-		opts := signing.NewOptions(
-			signing.Resolver(cv.Repository()),
-			signing.PublicKey(signature.Name, cert),
-			signing.VerifyDigests(),
-			signing.VerifySignature(signature.Name),
-		)
+	return sets, nil
+}
 
-		if err := opts.Complete(signingattr.Get(cv.GetContext())); err != nil {
-			return false, fmt.Errorf("verify error: %w", err)
+// getReferenceSignatureSets returns the signatures a referenced component must carry. If reference verification is
+// enabled, these are taken from the first matching reference verification entry, falling back to the signatures
+// configured on the subscription. They're followed by those of every TrustPolicy matching the referenced component.
+func getReferenceSignatureSets(obj *v1alpha1.ComponentSubscription, policies []v1alpha1.TrustPolicy, component string) []signatureSet {
+	var sets []signatureSet
+	for _, policy := range policies {
+		if policy.Matches(component) {
+			sets = append(sets, trustPolicySignatureSet(policy))
+		}
+	}
+
+	if obj.Spec.VerifyReferences == nil {
+		return sets
+	}
+
+	own := signatureSet{
+		referrer:           obj.Namespace,
+		namespace:          verifySecretNamespace(obj),
+		verify:             obj.Spec.Verify,
+		verifyCertificates: obj.Spec.VerifyCertificates,
+		policy:             obj.Spec.VerificationPolicy,
+	}
+
+	for _, signatures := range obj.Spec.VerifyReferences.Components {
+		if ok, _ := path.Match(signatures.Name, component); ok {
+			own.verify = signatures.Verify
+			own.verifyCertificates = signatures.VerifyCertificates
+			own.policy = signatures.VerificationPolicy
+
+			break
+		}
+	}
+
+	return append([]signatureSet{own}, sets...)
+}
+
+// verifySecretNamespace returns the namespace of the Secrets referenced by the signatures of the subscription.
+func verifySecretNamespace(obj *v1alpha1.ComponentSubscription) string {
+	if obj.Spec.VerifySecretNamespace != "" {
+		return obj.Spec.VerifySecretNamespace
+	}
+
+	return obj.Namespace
+}
+
+func trustPolicySignatureSet(policy v1alpha1.TrustPolicy) signatureSet {
+	return signatureSet{
+		trustPolicy:        policy.Name,
+		namespace:          policy.Spec.SecretNamespace,
+		verify:             policy.Spec.Verify,
+		verifyCertificates: policy.Spec.VerifyCertificates,
+		policy:             policy.Spec.VerificationPolicy,
+	}
+}
+
+// walkReferences calls visit for every component version referenced directly or transitively by cv through
+// references accepted by the filter. Each component version is visited once, the filter is called for every reference.
+func walkReferences(cv ocm.ComponentVersionAccess, selected referenceFilter, visit func(ref ocm.ComponentVersionAccess)) error {
+	visited := make(map[string]bool)
+
+	var walk func(parent ocm.ComponentVersionAccess) error
+	walk = func(parent ocm.ComponentVersionAccess) error {
+		for _, ref := range parent.GetDescriptor().References {
+			key := fmt.Sprintf("%s:%s", ref.ComponentName, ref.Version)
+			if !selected(parent, ref) || visited[key] {
+				continue
+			}
+
+			visited[key] = true
+
+			nested, err := parent.Repository().LookupComponentVersion(ref.ComponentName, ref.Version)
+			if err != nil {
+				return fmt.Errorf("failed to look up referenced component %s: %w", key, err)
+			}
+
+			visit(nested)
+			err = walk(nested)
+
+			if cerr := nested.Close(); cerr != nil {
+				err = errors.Join(err, cerr)
+			}
+
+			if err != nil {
+				return err
+			}
 		}
 
-		dig, err := signing.Apply(nil, nil, cv, opts)
+		return nil
+	}
+
+	return walk(cv)
+}
+
+// verifySignatureSets verifies the given signature sets on a component version and records the outcome in the
+// status of the subscription. Component identifies referenced component versions and is empty for the root.
+func (c *Client) verifySignatureSets(
+	ctx context.Context,
+	obj *v1alpha1.ComponentSubscription,
+	cv ocm.ComponentVersionAccess,
+	component string,
+	sets []signatureSet,
+) []error {
+	var errs []error
+	for _, set := range sets {
+		results, err := c.verifySignatureSet(ctx, cv, set)
+		for i := range results {
+			results[i].Component = component
+		}
+
+		obj.Status.Verifications = append(obj.Status.Verifications, results...)
+
 		if err != nil {
-			return false, fmt.Errorf("verify error: %w", err)
+			if set.trustPolicy != "" {
+				err = fmt.Errorf("trust policy %s: %w", set.trustPolicy, err)
+			}
+
+			errs = append(errs, err)
 		}
+	}
+
+	return errs
+}
 
-		var value string
-		for _, s := range cv.GetDescriptor().Signatures {
-			if s.Name == signature.Name {
-				value = s.Digest.Value
+// verifySignatureSet verifies the signatures of a set and returns the outcome for each signature name.
+func (c *Client) verifySignatureSet(ctx context.Context, cv ocm.ComponentVersionAccess, set signatureSet) ([]v1alpha1.SignatureVerification, error) {
+	var names []string
+	keys := make(map[string][]verificationKey)
+	addKey := func(name string, key verificationKey) {
+		if _, ok := keys[name]; !ok {
+			names = append(names, name)
+		}
+
+		keys[name] = append(keys[name], key)
+	}
+
+	for _, signature := range set.verify {
+		signature := signature
+		addKey(signature.Name, func() ([]signing.Option, error) {
+			if signature.PublicKey.SecretRef != nil {
+				key := types.NamespacedName{Namespace: set.namespace, Name: signature.PublicKey.SecretRef.Name}
+				if err := c.AuthorizeSecretReference(ctx, set.referrer, key); err != nil {
+					return nil, fmt.Errorf("verify error: %w", err)
+				}
+			}
+
+			cert, err := c.getSignaturePublicKey(ctx, set.namespace, signature)
+			if err != nil {
+				return nil, fmt.Errorf("verify error: %w", err)
+			}
+
+			return []signing.Option{signing.PublicKey(signature.Name, cert)}, nil
+		})
+	}
+
+	for _, signature := range set.verifyCertificates {
+		signature := signature
+		addKey(signature.Name, func() ([]signing.Option, error) {
+			key := types.NamespacedName{Namespace: set.namespace, Name: signature.RootCertificatesSecretRef.Name}
+			if err := c.AuthorizeSecretReference(ctx, set.referrer, key); err != nil {
+				return nil, err
+			}
+
+			return c.getCertificateVerificationOptions(ctx, set.namespace, signature)
+		})
+	}
+
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	var (
+		results  []v1alpha1.SignatureVerification
+		verified int
+		errs     []error
+	)
+
+	for _, name := range names {
+		result := v1alpha1.SignatureVerification{
+			Name:        name,
+			TrustPolicy: set.trustPolicy,
+		}
+
+		var keyErrs []error
+		for _, key := range keys[name] {
+			err := verifyWithKey(cv, name, key)
+			if err == nil {
+				result.Verified = true
 
 				break
 			}
+
+			keyErrs = append(keyErrs, err)
 		}
 
-		if value == "" {
-			return false, fmt.Errorf("signature with name '%s' not found in the list of provided ocm signatures", signature.Name)
+		if result.Verified {
+			verified++
+		} else {
+			err := errors.Join(keyErrs...)
+			result.Message = err.Error()
+			errs = append(errs, err)
 		}
 
-		if dig.Value != value {
-			return false, fmt.Errorf("%s signature did not match key value", signature.Name)
+		results = append(results, result)
+	}
+
+	required := set.policy.GetRequiredSignatures(len(names))
+	if required <= 0 {
+		return results, fmt.Errorf("verification policy requires %d signatures, at least one is required", required)
+	}
+
+	if verified < required {
+		return results, fmt.Errorf("%d of %d required signatures verified: %w", verified, required, errors.Join(errs...))
+	}
+
+	return results, nil
+}
+
+// verificationKey returns the signing options to verify a signature with one of its configured keys.
+type verificationKey func() ([]signing.Option, error)
+
+func verifyWithKey(cv ocm.ComponentVersionAccess, name string, key verificationKey) error {
+	opts, err := key()
+	if err != nil {
+		return err
+	}
+
+	return verifySignature(cv, name, opts...)
+}
+
+// getSignaturePublicKey returns the public key of a signature either from its value or the referenced secret.
+func (c *Client) getSignaturePublicKey(ctx context.Context, namespace string, signature ocmv1alpha1.Signature) ([]byte, error) {
+	if signature.PublicKey.Value != "" {
+		return signature.PublicKey.DecodePublicValue()
+	}
+
+	if signature.PublicKey.SecretRef == nil {
+		return nil, fmt.Errorf("kubernetes secret reference not provided")
+	}
+
+	return c.getPublicKey(
+		ctx,
+		namespace,
+		signature.PublicKey.SecretRef.Name,
+		signature.Name,
+	)
+}
+
+// getCertificateVerificationOptions returns the signing options to verify a signature against trusted root certificates.
+func (c *Client) getCertificateVerificationOptions(ctx context.Context, namespace string, signature v1alpha1.CertificateSignature) ([]signing.Option, error) {
+	roots, err := c.getRootCertificates(ctx, namespace, signature.RootCertificatesSecretRef.Name)
+	if err != nil {
+		return nil, fmt.Errorf("verify error: %w", err)
+	}
+
+	// OCM calls the subject of the signing certificate the issuer of the signature. Without an explicit one, it
+	// expects the certificate to be issued for the signature name. Use an empty name instead, so any certificate
+	// rooted in the trusted certificates is accepted.
+	subject := &pkix.Name{}
+	if signature.Subject != "" {
+		if subject, err = signutils.ParseDN(signature.Subject); err != nil {
+			return nil, fmt.Errorf("verify error: invalid subject for signature %s: %w", signature.Name, err)
 		}
 	}
 
-	return true, nil
+	return []signing.Option{
+		signing.RootCertificates(roots),
+		signing.PKIXIssuerFor(signature.Name, *subject),
+	}, nil
+}
+
+// verifySignature verifies the named signature of a component version using the given key options and
+// makes sure that the computed digest matches the one stored with the signature.
+func verifySignature(cv ocm.ComponentVersionAccess, name string, keyOpts ...signing.Option) error {
+	opts := signing.NewOptions(
+		signing.Resolver(cv.Repository()),
+		signing.VerifyDigests(),
+		signing.VerifySignature(name),
+	).Eval(keyOpts...)
+
+	if err := opts.Complete(signingattr.Get(cv.GetContext())); err != nil {
+		return fmt.Errorf("verify error: %w", err)
+	}
+
+	dig, err := signing.Apply(nil, nil, cv, opts)
+	if err != nil {
+		return fmt.Errorf("verify error: %w", err)
+	}
+
+	var value string
+	for _, s := range cv.GetDescriptor().Signatures {
+		if s.Name == name {
+			value = s.Digest.Value
+
+			break
+		}
+	}
+
+	if value == "" {
+		return fmt.Errorf("signature with name '%s' not found in the list of provided ocm signatures", name)
+	}
+
+	if dig.Value != value {
+		return fmt.Errorf("%s signature did not match key value", name)
+	}
+
+	return nil
 }
 
 func (c *Client) getPublicKey(ctx context.Context, namespace, name, signature string) ([]byte, error) {
@@ -226,6 +959,39 @@ func (c *Client) getPublicKey(ctx context.Context, namespace, name, signature st
 	return nil, errors.New("public key not found")
 }
 
+func (c *Client) getRootCertificates(ctx context.Context, namespace, name string) (*x509.CertPool, error) {
+	data, err := c.getCACertificates(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	roots, err := signutils.GetCertPool(data, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse root certificates in secret %s: %w", name, err)
+	}
+
+	return roots, nil
+}
+
+// getCACertificates returns the PEM encoded certificates stored under the `ca.crt` key of a Secret.
+func (c *Client) getCACertificates(ctx context.Context, namespace, name string) ([]byte, error) {
+	var secret corev1.Secret
+	secretKey := client.ObjectKey{
+		Namespace: namespace,
+		Name:      name,
+	}
+	if err := c.client.Get(ctx, secretKey, &secret); err != nil {
+		return nil, err
+	}
+
+	data, ok := secret.Data[caCertKey]
+	if !ok {
+		return nil, fmt.Errorf("failed to find %s in secret %s", caCertKey, name)
+	}
+
+	return data, nil
+}
+
 func (c *Client) GetLatestSourceComponentVersion(ctx context.Context, octx ocm.Context, obj *v1alpha1.ComponentSubscription) (string, error) {
 	log := log.FromContext(ctx)
 
@@ -269,8 +1035,7 @@ type Version struct {
 }
 
 func (c *Client) listComponentVersions(logger logr.Logger, octx ocm.Context, obj *v1alpha1.ComponentSubscription) ([]Version, error) {
-	repoSpec := ocireg.NewRepositorySpec(obj.Spec.Source.URL, nil)
-	repo, err := octx.RepositoryForSpec(repoSpec)
+	repo, err := octx.RepositoryForSpec(repositorySpec(octx, obj.Spec.Source))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get repository for spec: %w", err)
 	}
@@ -311,8 +1076,7 @@ func (c *Client) TransferComponent(
 	obj *v1alpha1.ComponentSubscription,
 	sourceComponentVersion ocm.ComponentVersionAccess,
 ) error {
-	sourceRepoSpec := ocireg.NewRepositorySpec(obj.Spec.Source.URL, nil)
-	source, err := octx.RepositoryForSpec(sourceRepoSpec)
+	source, err := octx.RepositoryForSpec(repositorySpec(octx, obj.Spec.Source))
 	if err != nil {
 		return fmt.Errorf("failed to get source repo: %w", err)
 	}
@@ -327,17 +1091,53 @@ func (c *Client) TransferComponent(
 		return fmt.Errorf("on of the signatures failed to match: %w", err)
 	}
 
-	targetRepoSpec := ocireg.NewRepositorySpec(obj.Spec.Destination.URL, nil)
-	target, err := octx.RepositoryForSpec(targetRepoSpec)
+	if obj.Spec.VerifyImages != nil {
+		if err := c.verifyImages(ctx, octx, obj, sourceComponentVersion); err != nil {
+			return fmt.Errorf("failed to verify images: %w", err)
+		}
+	}
+
+	var platforms *platformHandler
+	if obj.Spec.Transfer != nil && len(obj.Spec.Transfer.Platforms) > 0 {
+		filter, err := parsePlatforms(obj.Spec.Transfer.Platforms)
+		if err != nil {
+			return fmt.Errorf("failed to parse platforms: %w", err)
+		}
+
+		platforms = registerPlatformHandler(octx, filter)
+	}
+
+	target, err := octx.RepositoryForSpec(repositorySpec(octx, *obj.Spec.Destination))
 	if err != nil {
 		return fmt.Errorf("failed to get target repo: %w", err)
 	}
 	defer target.Close()
 
-	handler, err := standard.New(
-		standard.Recursive(true),
-		standard.ResourcesByValue(true),
-		standard.Overwrite(true),
+	obj.Status.References = nil
+	if !obj.Spec.FollowReferences {
+		if obj.Status.References, err = referenceTree(sourceComponentVersion, transferredReferences(obj)); err != nil {
+			return fmt.Errorf("failed to list references: %w", err)
+		}
+	}
+
+	// excluded references aren't transferred, they must be available from the fallback repository instead.
+	if fallback := obj.Spec.Transfer.GetFallback(); fallback != nil {
+		repository, err := octx.RepositoryForSpec(repositorySpec(octx, *fallback))
+		if err != nil {
+			return fmt.Errorf("failed to get fallback repo: %w", err)
+		}
+		defer repository.Close()
+
+		if err := checkFallback(repository, obj.Status.References); err != nil {
+			return err
+		}
+	}
+
+	options := getTransferOptions(obj)
+	standardHandler, err := standard.New(
+		standard.Recursive(options.Recursive),
+		standard.ResourcesByValue(options.ResourcesByValue),
+		standard.Overwrite(options.Overwrite),
 		standard.Resolver(source),
 		standard.Resolver(target),
 	)
@@ -345,48 +1145,120 @@ func (c *Client) TransferComponent(
 		return fmt.Errorf("failed to construct target handler: %w", err)
 	}
 
-	if err := transfer.TransferVersion(
-		nil,
-		transfer.TransportClosure{},
-		sourceComponentVersion,
-		target,
-		handler,
-	); err != nil {
+	handler := &referenceHandler{
+		TransferHandler: c.newRetryingHandler(ctx, octx, RegistryRepositories(obj), standardHandler),
+		selected:        transferredReferences(obj),
+	}
+	if err := transfer.TransferVersion(nil, transfer.TransportClosure{}, sourceComponentVersion, target, handler); err != nil {
 		return fmt.Errorf("failed to transfer version to destination repository: %w", err)
 	}
 
+	if platforms != nil {
+		destination, err := target.LookupComponentVersion(sourceComponentVersion.GetName(), sourceComponentVersion.GetVersion())
+		if err != nil {
+			return fmt.Errorf("failed to look up transferred component version: %w", err)
+		}
+
+		// closing the component version persists the updated digests.
+		err = errors.Join(platforms.updateDigests(destination, transferredReferences(obj)), destination.Close())
+		if err != nil {
+			return fmt.Errorf("failed to update digests of trimmed images: %w", err)
+		}
+	}
+
+	if obj.Spec.VerifyImages != nil && obj.Spec.VerifyImages.CopySignatures {
+		destination, err := target.LookupComponentVersion(sourceComponentVersion.GetName(), sourceComponentVersion.GetVersion())
+		if err != nil {
+			return fmt.Errorf("failed to look up transferred component version: %w", err)
+		}
+		defer destination.Close()
+
+		if err := c.copyImageSignatures(ctx, octx, obj, sourceComponentVersion, destination); err != nil {
+			return fmt.Errorf("failed to copy image signatures: %w", err)
+		}
+	}
+
 	return nil
 }
 
 // configureAccessCredentials configures access credentials if needed for a source/destination repository.
 func (c *Client) configureAccessCredentials(ctx context.Context, ocmCtx ocm.Context, repository v1alpha1.OCMRepository, namespace string) error {
-	// If there are no credentials, this call is a no-op.
-	if repository.SecretRef == nil {
-		return nil
+	logger := log.FromContext(ctx)
+
+	if repository.SecretRef != nil && repository.Credentials != nil {
+		return fmt.Errorf("secretRef and credentials are mutually exclusive for %s", repository.URL)
 	}
 
-	logger := log.FromContext(ctx)
+	if repository.SecretRef != nil {
+		key := SecretReferenceKey(namespace, repository.SecretRef)
+		if err := c.AuthorizeSecretReference(ctx, namespace, key); err != nil {
+			return err
+		}
 
-	if err := csdk.ConfigureCredentials(ctx, ocmCtx, c.client, repository.URL, repository.SecretRef.Name, namespace); err != nil {
-		logger.V(v1alpha1.LevelDebug).Error(err, "failed to find destination credentials")
+		if err := c.configureSecretCredentials(ctx, ocmCtx, key, repositoryHost(repository)); err != nil {
+			return err
+		}
+
+		logger.V(v1alpha1.LevelDebug).Info("credentials configured")
+	}
 
-		// we don't ignore not found errors
-		return fmt.Errorf("failed to configure credentials for component: %w", err)
+	if repository.Credentials != nil && repository.Credentials.Exec != nil {
+		if err := c.configureHelperCredentials(ctx, ocmCtx, repository); err != nil {
+			return err
+		}
+
+		logger.V(v1alpha1.LevelDebug).Info("credentials obtained from credential helper")
 	}
 
-	logger.V(v1alpha1.LevelDebug).Info("credentials configured")
+	// the certificates are added to the credentials configured above.
+	if repository.CertSecretRef != nil {
+		if err := c.configureCertificates(ctx, ocmCtx, repository, namespace); err != nil {
+			return fmt.Errorf("failed to configure certificates: %w", err)
+		}
+
+		logger.V(v1alpha1.LevelDebug).Info("certificates configured")
+	}
 
 	return nil
 }
 
-func (c *Client) configureServiceAccountAccess(ctx context.Context, octx ocm.Context, serviceAccountName, namespace string) error {
+// ocmRepositories returns the OCM repositories of the subscription, i.e. the source, the optional destination and
+// the optional fallback repository of excluded references.
+func ocmRepositories(obj *v1alpha1.ComponentSubscription) []v1alpha1.OCMRepository {
+	repositories := []v1alpha1.OCMRepository{obj.Spec.Source}
+	if obj.Spec.Destination != nil {
+		repositories = append(repositories, *obj.Spec.Destination)
+	}
+
+	if fallback := obj.Spec.Transfer.GetFallback(); fallback != nil {
+		repositories = append(repositories, *fallback)
+	}
+
+	return repositories
+}
+
+// RegistryRepositories returns all repositories of the subscription accessed as OCI registries, i.e. the OCM
+// repositories and the optional image mirror.
+func RegistryRepositories(obj *v1alpha1.ComponentSubscription) []v1alpha1.OCMRepository {
+	repositories := ocmRepositories(obj)
+	if obj.Spec.MirrorImages != nil {
+		repositories = append(repositories, obj.Spec.MirrorImages.Destination)
+	}
+
+	return repositories
+}
+
+// configureServiceAccountAccess configures the image pull secrets of the service account of the subscription.
+// Pull secrets that aren't docker configs must name the registry they're used for, as the service account may be
+// shared by subscriptions of different registries.
+func (c *Client) configureServiceAccountAccess(ctx context.Context, octx ocm.Context, obj *v1alpha1.ComponentSubscription) error {
 	logger := log.FromContext(ctx)
 
 	logger.V(v1alpha1.LevelDebug).Info("configuring service account credentials")
 	account := &corev1.ServiceAccount{}
 	if err := c.client.Get(ctx, types.NamespacedName{
-		Name:      serviceAccountName,
-		Namespace: namespace,
+		Name:      obj.Spec.ServiceAccountName,
+		Namespace: obj.Namespace,
 	}, account); err != nil {
 		return fmt.Errorf("failed to fetch service account: %w", err)
 	}
@@ -398,20 +1270,29 @@ func (c *Client) configureServiceAccountAccess(ctx context.Context, octx ocm.Con
 
 		if err := c.client.Get(ctx, types.NamespacedName{
 			Name:      imagePullSecret.Name,
-			Namespace: namespace,
+			Namespace: obj.Namespace,
 		}, secret); err != nil {
 			return fmt.Errorf("failed to get image pull secret: %w", err)
 		}
 
-		data, ok := secret.Data[dockerConfigKey]
-		if !ok {
-			return fmt.Errorf("failed to find .dockerconfigjson in secret %s", secret.Name)
+		creds, err := parseRegistryCredentials(secret)
+		if err != nil {
+			return err
 		}
 
-		repository := dockerconfig.NewRepositorySpecForConfig(data, true)
+		var hosts []string
+		if creds.dockerConfig == nil {
+			registry := secretRegistry(secret)
+			if registry == "" {
+				return fmt.Errorf("image pull secret %s isn't a docker config, name its registry with the %s annotation or a %q key",
+					client.ObjectKeyFromObject(secret), v1alpha1.RegistryAnnotation, registryKey)
+			}
+
+			hosts = append(hosts, registry)
+		}
 
-		if _, err := octx.CredentialsContext().RepositoryForSpec(repository); err != nil {
-			return fmt.Errorf("failed to configure credentials for repository: %w", err)
+		if err := configureRegistryCredentials(octx, secret, hosts...); err != nil {
+			return err
 		}
 	}
 
@@ -2,23 +2,77 @@ package ocm
 
 import (
 	"context"
+	"crypto"
+	"crypto/rand"
+	gorsa "crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	ctrdocker "github.com/containerd/containerd/remotes/docker"
+	remoteserrors "github.com/containerd/containerd/remotes/errors"
+	"github.com/distribution/distribution/v3/configuration"
+	"github.com/distribution/distribution/v3/registry/handlers"
 	_ "github.com/distribution/distribution/v3/registry/storage/driver/inmemory"
+	"github.com/go-logr/logr"
+	"github.com/google/go-containerregistry/pkg/name"
+	ggcrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	ggcrtypes "github.com/google/go-containerregistry/pkg/v1/types"
+	notarysignature "github.com/notaryproject/notation-core-go/signature"
+	ociv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	ocmv1alpha1 "github.com/open-component-model/ocm-controller/api/v1alpha1"
 	ocmcontext "github.com/open-component-model/ocm-controller/pkg/fakes"
+	"github.com/open-component-model/ocm/pkg/common"
+	"github.com/open-component-model/ocm/pkg/common/accessio"
 	"github.com/open-component-model/ocm/pkg/contexts/credentials/cpi"
+	"github.com/open-component-model/ocm/pkg/contexts/datacontext/attrs/rootcertsattr"
 	"github.com/open-component-model/ocm/pkg/contexts/oci/identity"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/accessmethods/ociartifact"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/compdesc"
+	ocmmetav1 "github.com/open-component-model/ocm/pkg/contexts/ocm/compdesc/meta/v1"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/repositories/ocireg"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/resourcetypes"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/signing"
+	"github.com/open-component-model/ocm/pkg/mime"
+	"github.com/open-component-model/ocm/pkg/signing/handlers/rsa"
+	"github.com/open-component-model/ocm/pkg/signing/signutils"
 	"github.com/open-component-model/replication-controller/api/v1alpha1"
+	"github.com/open-component-model/replication-controller/pkg/attestation"
+	"github.com/open-component-model/replication-controller/pkg/keys"
+	"github.com/open-component-model/replication-controller/pkg/notation"
+	"github.com/open-component-model/replication-controller/pkg/ocitransport"
 )
 
 func TestClient_GetComponentVersion(t *testing.T) {
@@ -57,7 +111,7 @@ func TestClient_GetComponentVersion(t *testing.T) {
 						Semver:    "v0.0.1",
 						Source: v1alpha1.OCMRepository{
 							URL: "localhost",
-							SecretRef: &corev1.LocalObjectReference{
+							SecretRef: &corev1.SecretReference{
 								Name: "test-name-secret",
 							},
 						},
@@ -226,6 +280,246 @@ func TestClient_CreateAuthenticatedOCMContext(t *testing.T) {
 	assert.Equal(t, "ghcr.io", consumer.Properties()["serverAddress"])
 }
 
+func TestClient_CreateAuthenticatedOCMContextWithConfig(t *testing.T) {
+	config := func(username string) string {
+		return fmt.Sprintf(`type: generic.config.ocm.software/v1
+configurations:
+- type: credentials.config.ocm.software
+  consumers:
+  - identity:
+      type: OCIRegistry
+      hostname: ghcr.io
+      pathprefix: acme
+    credentials:
+    - type: Credentials
+      properties:
+        username: %s
+        password: password
+`, username)
+	}
+
+	testCases := []struct {
+		name          string
+		ref           *v1alpha1.OCMConfigReference
+		defaultConfig *ConfigReference
+		objects       []client.Object
+		username      string
+		err           string
+	}{
+		{
+			name: "config is loaded from a secret",
+			ref:  &v1alpha1.OCMConfigReference{Name: "ocm-config"},
+			objects: []client.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "ocm-config", Namespace: "default"},
+					Data:       map[string][]byte{ConfigKey: []byte(config("secret-user"))},
+				},
+			},
+			username: "secret-user",
+		},
+		{
+			name: "config is loaded from a config map",
+			ref:  &v1alpha1.OCMConfigReference{Kind: ConfigMapKind, Name: "ocm-config"},
+			objects: []client.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "ocm-config", Namespace: "default"},
+					Data:       map[string]string{ConfigKey: config("config-map-user")},
+				},
+			},
+			username: "config-map-user",
+		},
+		{
+			name: "default config is applied",
+			defaultConfig: &ConfigReference{
+				Kind:           ConfigMapKind,
+				NamespacedName: types.NamespacedName{Namespace: "ocm-system", Name: "default-config"},
+			},
+			objects: []client.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "default-config", Namespace: "ocm-system"},
+					Data:       map[string]string{ConfigKey: config("default-user")},
+				},
+			},
+			username: "default-user",
+		},
+		{
+			name: "subscription config overrides the default config",
+			ref:  &v1alpha1.OCMConfigReference{Kind: SecretKind, Name: "ocm-config"},
+			defaultConfig: &ConfigReference{
+				NamespacedName: types.NamespacedName{Namespace: "ocm-system", Name: "default-config"},
+			},
+			objects: []client.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "default-config", Namespace: "ocm-system"},
+					Data:       map[string][]byte{ConfigKey: []byte(config("default-user"))},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "ocm-config", Namespace: "default"},
+					Data:       map[string][]byte{ConfigKey: []byte(config("secret-user"))},
+				},
+			},
+			username: "secret-user",
+		},
+		{
+			name: "missing config key",
+			ref:  &v1alpha1.OCMConfigReference{Name: "ocm-config"},
+			objects: []client.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "ocm-config", Namespace: "default"},
+				},
+			},
+			err: "failed to configure ocm config: Secret default/ocm-config has no .ocmconfig key",
+		},
+		{
+			name: "invalid config",
+			ref:  &v1alpha1.OCMConfigReference{Name: "ocm-config"},
+			objects: []client.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "ocm-config", Namespace: "default"},
+					Data:       map[string][]byte{ConfigKey: []byte("{")},
+				},
+			},
+			err: "failed to configure ocm config: invalid ocm config in default/ocm-config",
+		},
+		{
+			name: "subscription config must not read files",
+			ref:  &v1alpha1.OCMConfigReference{Name: "ocm-config"},
+			objects: []client.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "ocm-config", Namespace: "default"},
+					Data: map[string][]byte{ConfigKey: []byte(`type: generic.config.ocm.software/v1
+configurations:
+- type: keys.config.ocm.software
+  privateKeys:
+    acme:
+      path: /var/run/secrets/kubernetes.io/serviceaccount/token
+`)},
+				},
+			},
+			err: "ocm config in default/ocm-config is not allowed: configurations[0].privateKeys.acme.path: files of the controller can't be read",
+		},
+		{
+			name: "subscription config must not read docker config files",
+			ref:  &v1alpha1.OCMConfigReference{Name: "ocm-config"},
+			objects: []client.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "ocm-config", Namespace: "default"},
+					Data: map[string][]byte{ConfigKey: []byte(`type: generic.config.ocm.software/v1
+configurations:
+- type: credentials.config.ocm.software
+  repositories:
+  - repository:
+      type: DockerConfig/v1
+      dockerConfigFile: ~/.docker/config.json
+`)},
+				},
+			},
+			err: "configurations[0].repositories[0].repository.dockerConfigFile: docker config files of the controller can't be read",
+		},
+		{
+			name: "subscription config must not run credential helpers",
+			ref:  &v1alpha1.OCMConfigReference{Name: "ocm-config"},
+			objects: []client.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "ocm-config", Namespace: "default"},
+					Data: map[string][]byte{ConfigKey: []byte(`type: generic.config.ocm.software/v1
+configurations:
+- type: credentials.config.ocm.software
+  repositories:
+  - repository:
+      type: DockerConfig/v1
+      dockerConfig:
+        credHelpers:
+          ghcr.io: evil
+`)},
+				},
+			},
+			err: "configurations[0].repositories[0].repository.dockerConfig.credHelpers: docker credential helpers can't be run",
+		},
+		{
+			name: "subscription config must not use a credential store",
+			ref:  &v1alpha1.OCMConfigReference{Name: "ocm-config"},
+			objects: []client.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "ocm-config", Namespace: "default"},
+					Data: map[string][]byte{ConfigKey: []byte(`type: generic.config.ocm.software/v1
+configurations:
+- type: credentials.config.ocm.software
+  repositories:
+  - repository:
+      type: DockerConfig/v1
+      dockerConfig: '{"credsStore": "evil"}'
+`)},
+				},
+			},
+			err: "configurations[0].repositories[0].repository.dockerConfig.credsStore: docker credential helpers can't be run",
+		},
+		{
+			name: "default config may read files",
+			defaultConfig: &ConfigReference{
+				NamespacedName: types.NamespacedName{Namespace: "ocm-system", Name: "default-config"},
+			},
+			objects: []client.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "default-config", Namespace: "ocm-system"},
+					Data: map[string][]byte{ConfigKey: []byte(config("default-user") + `- type: keys.config.ocm.software
+  publicKeys:
+    acme:
+      path: testdata/public1_key.pem
+`)},
+				},
+			},
+			username: "default-user",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := &v1alpha1.ComponentSubscription{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-name",
+					Namespace: "default",
+				},
+				Spec: v1alpha1.ComponentSubscriptionSpec{
+					Component: "github.com/open-component-model/ocm-demo-index",
+					Source: v1alpha1.OCMRepository{
+						URL: "localhost",
+					},
+					OCMConfigRef: tt.ref,
+				},
+			}
+
+			var opts []ClientOption
+			if tt.defaultConfig != nil {
+				opts = append(opts, WithDefaultConfig(*tt.defaultConfig))
+			}
+
+			ocmClient := NewClient(env.FakeKubeClient(WithObjects(append(tt.objects, cs)...)), opts...)
+			octx, err := ocmClient.CreateAuthenticatedOCMContext(context.Background(), cs)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+
+				return
+			}
+			require.NoError(t, err)
+
+			id := cpi.ConsumerIdentity{
+				cpi.ID_TYPE:            identity.CONSUMER_TYPE,
+				identity.ID_HOSTNAME:   "ghcr.io",
+				identity.ID_PATHPREFIX: "acme",
+			}
+			creds, err := octx.CredentialsContext().GetCredentialsForConsumer(id)
+			require.NoError(t, err)
+			consumer, err := creds.Credentials(octx.CredentialsContext())
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.username, consumer.Properties()["username"])
+			assert.Equal(t, "password", consumer.Properties()["password"])
+		})
+	}
+}
+
 func TestClient_GetLatestValidComponentVersion(t *testing.T) {
 	testCases := []struct {
 		name             string
@@ -477,7 +771,7 @@ func TestClient_SignComponent(t *testing.T) {
 	}
 	require.NoError(t, octx.AddComponent(c))
 
-	pub, err := ocmClient.SignDestinationComponent(context.Background(), c)
+	pub, err := ocmClient.SignDestinationComponent(context.Background(), &v1alpha1.ComponentSubscription{}, c)
 	assert.NoError(t, err)
 
 	cv := &v1alpha1.ComponentSubscription{
@@ -567,3 +861,3418 @@ func TestClient_VerifyComponentDifferentPublicKey(t *testing.T) {
 	require.Error(t, err)
 	assert.False(t, verified, "verified should have been false, but it did not")
 }
+
+func TestClient_SignAndVerifyComponentWithCertificate(t *testing.T) {
+	caPriv, caPub, err := rsa.Handler{}.CreateKeyPair()
+	require.NoError(t, err)
+
+	caSpec := &signutils.Specification{
+		IsCA:         true,
+		PublicKey:    caPub,
+		CAPrivateKey: caPriv,
+		Subject: pkix.Name{
+			CommonName: "ca-authority",
+		},
+		Usages:   signutils.Usages{x509.ExtKeyUsageCodeSigning},
+		Validity: 10 * time.Hour,
+	}
+	ca, caPEM, err := signutils.CreateCertificate(caSpec)
+	require.NoError(t, err)
+
+	priv, pub, err := rsa.Handler{}.CreateKeyPair()
+	require.NoError(t, err)
+
+	_, certPEM, err := signutils.CreateCertificate(&signutils.Specification{
+		RootCAs:      ca,
+		CAChain:      ca,
+		PublicKey:    pub,
+		CAPrivateKey: caPriv,
+		Subject: pkix.Name{
+			CommonName:   "replication-controller",
+			Organization: []string{"acme"},
+		},
+		Usages:   signutils.Usages{x509.ExtKeyUsageCodeSigning},
+		Validity: 10 * time.Hour,
+	})
+	require.NoError(t, err)
+
+	signingSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "signing-certificate",
+			Namespace: "ocm-system",
+		},
+		Data: map[string][]byte{
+			corev1.TLSPrivateKeyKey: pem.EncodeToMemory(signutils.PemBlockForPrivateKey(priv)),
+			corev1.TLSCertKey:       certPEM,
+			"ca.crt":                caPEM,
+		},
+		Type: corev1.SecretTypeTLS,
+	}
+	rootsSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "trusted-roots",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			"ca.crt": caPEM,
+		},
+	}
+
+	testCases := []struct {
+		name    string
+		subject string
+		err     string
+	}{
+		{
+			name: "verifies against the root certificate",
+		},
+		{
+			name:    "verifies with matching subject",
+			subject: "CN=replication-controller,O=acme",
+		},
+		{
+			name:    "fails with different subject",
+			subject: "CN=someone-else",
+			err:     "issuer mismatch",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeKubeClient := env.FakeKubeClient(WithObjects(signingSecret, rootsSecret))
+			ocmClient := NewClient(fakeKubeClient, WithSigningCertificateSecret(types.NamespacedName{
+				Name:      signingSecret.Name,
+				Namespace: signingSecret.Namespace,
+			}))
+			component := "github.com/open-component-model/ocm-demo-index"
+
+			octx := ocmcontext.NewFakeOCMContext()
+
+			c := &ocmcontext.Component{
+				Name:    component,
+				Version: "v0.0.1",
+			}
+			require.NoError(t, octx.AddComponent(c))
+
+			chain, err := ocmClient.SignDestinationComponent(context.Background(), &v1alpha1.ComponentSubscription{}, c)
+			require.NoError(t, err)
+			assert.Equal(t, certPEM, chain)
+
+			cv := &v1alpha1.ComponentSubscription{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-name",
+					Namespace: "default",
+				},
+				Spec: v1alpha1.ComponentSubscriptionSpec{
+					Component: component,
+					Source: v1alpha1.OCMRepository{
+						URL: "localhost",
+					},
+					VerifyCertificates: []v1alpha1.CertificateSignature{
+						{
+							Name: v1alpha1.InternalSignatureName,
+							RootCertificatesSecretRef: corev1.LocalObjectReference{
+								Name: rootsSecret.Name,
+							},
+							Subject: tt.subject,
+						},
+					},
+				},
+			}
+
+			verified, err := ocmClient.VerifyComponent(context.Background(), cv, c)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				assert.False(t, verified)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.True(t, verified, "verified should have been true, but it did not")
+		})
+	}
+}
+
+func TestClient_SignComponentWithEmptyCertificateChain(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "signing-certificate",
+			Namespace: "ocm-system",
+		},
+		Data: map[string][]byte{
+			corev1.TLSPrivateKeyKey: []byte("key"),
+			corev1.TLSCertKey:       {},
+		},
+		Type: corev1.SecretTypeTLS,
+	}
+	ocmClient := NewClient(env.FakeKubeClient(WithObjects(secret)), WithSigningCertificateSecret(types.NamespacedName{
+		Name:      secret.Name,
+		Namespace: secret.Namespace,
+	}))
+
+	octx := ocmcontext.NewFakeOCMContext()
+	c := &ocmcontext.Component{
+		Name:    "github.com/open-component-model/ocm-demo-index",
+		Version: "v0.0.1",
+	}
+	require.NoError(t, octx.AddComponent(c))
+
+	_, err := ocmClient.SignDestinationComponent(context.Background(), &v1alpha1.ComponentSubscription{}, c)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "certificate chain in secret ocm-system/signing-certificate")
+	assert.NotContains(t, err.Error(), "%!w", "the error must not wrap a nil error")
+}
+
+func TestClient_VerifyComponentPolicy(t *testing.T) {
+	publicKey1, err := os.ReadFile(filepath.Join("testdata", "public1_key.pem"))
+	require.NoError(t, err)
+	publicKey2, err := os.ReadFile(filepath.Join("testdata", "public2_key.pem"))
+	require.NoError(t, err)
+	privateKey, err := os.ReadFile(filepath.Join("testdata", "private_key.pem"))
+	require.NoError(t, err)
+
+	validKey := func(name string) ocmv1alpha1.Signature {
+		return ocmv1alpha1.Signature{
+			Name: name,
+			PublicKey: ocmv1alpha1.PublicKey{
+				Value: base64.StdEncoding.EncodeToString(publicKey1),
+			},
+		}
+	}
+	invalidKey := func(name string) ocmv1alpha1.Signature {
+		return ocmv1alpha1.Signature{
+			Name: name,
+			PublicKey: ocmv1alpha1.PublicKey{
+				Value: base64.StdEncoding.EncodeToString(publicKey2),
+			},
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		verify        []ocmv1alpha1.Signature
+		policy        *v1alpha1.VerificationPolicy
+		verifications []bool
+		err           string
+	}{
+		{
+			name:          "any of the keys of a signature may match",
+			verify:        []ocmv1alpha1.Signature{invalidKey(Signature), validKey(Signature)},
+			verifications: []bool{true},
+		},
+		{
+			name:          "all signatures are required by default",
+			verify:        []ocmv1alpha1.Signature{validKey(Signature), validKey("missing-signature")},
+			verifications: []bool{true, false},
+			err:           "1 of 2 required signatures verified",
+		},
+		{
+			name:   "any of the signatures is enough with AnyOf",
+			verify: []ocmv1alpha1.Signature{validKey("missing-signature"), validKey(Signature)},
+			policy: &v1alpha1.VerificationPolicy{
+				Type: v1alpha1.VerificationPolicyAnyOf,
+			},
+			verifications: []bool{false, true},
+		},
+		{
+			name:   "fails if fewer than the minimum signatures are valid",
+			verify: []ocmv1alpha1.Signature{validKey(Signature), invalidKey("other-signature"), validKey("missing-signature")},
+			policy: &v1alpha1.VerificationPolicy{
+				Type:          v1alpha1.VerificationPolicyAtLeast,
+				MinSignatures: 2,
+			},
+			verifications: []bool{true, false, false},
+			err:           "1 of 2 required signatures verified",
+		},
+		{
+			name:   "fails if AtLeast has no minimum",
+			verify: []ocmv1alpha1.Signature{validKey(Signature)},
+			policy: &v1alpha1.VerificationPolicy{
+				Type: v1alpha1.VerificationPolicyAtLeast,
+			},
+			verifications: []bool{true},
+			err:           "verification policy requires 0 signatures",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeKubeClient := env.FakeKubeClient()
+			ocmClient := NewClient(fakeKubeClient)
+			component := "github.com/open-component-model/ocm-demo-index"
+
+			octx := ocmcontext.NewFakeOCMContext()
+
+			c := &ocmcontext.Component{
+				Name:    component,
+				Version: "v0.0.1",
+				Sign: &ocmcontext.Sign{
+					Name:    Signature,
+					PrivKey: privateKey,
+					PubKey:  publicKey1,
+					Digest:  "3d879ecdea45acb7f8d85b89fd653288d84af4476eac4141822142ec59c13745",
+				},
+			}
+			require.NoError(t, octx.AddComponent(c))
+
+			cv := &v1alpha1.ComponentSubscription{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-name",
+					Namespace: "default",
+				},
+				Spec: v1alpha1.ComponentSubscriptionSpec{
+					Component: component,
+					Source: v1alpha1.OCMRepository{
+						URL: "localhost",
+					},
+					Verify:             tt.verify,
+					VerificationPolicy: tt.policy,
+				},
+			}
+
+			verified, err := ocmClient.VerifyComponent(context.Background(), cv, c)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				assert.False(t, verified)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, verified)
+			}
+
+			require.Len(t, cv.Status.Verifications, len(tt.verifications))
+			for i, v := range tt.verifications {
+				assert.Equal(t, v, cv.Status.Verifications[i].Verified, cv.Status.Verifications[i].Name)
+				if !v {
+					assert.NotEmpty(t, cv.Status.Verifications[i].Message)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_VerifyComponentTrustPolicy(t *testing.T) {
+	publicKey1, err := os.ReadFile(filepath.Join("testdata", "public1_key.pem"))
+	require.NoError(t, err)
+	publicKey2, err := os.ReadFile(filepath.Join("testdata", "public2_key.pem"))
+	require.NoError(t, err)
+	privateKey, err := os.ReadFile(filepath.Join("testdata", "private_key.pem"))
+	require.NoError(t, err)
+
+	component := "github.com/open-component-model/ocm-demo-index"
+	keySecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "trusted-keys",
+			Namespace: "ocm-system",
+		},
+		Data: map[string][]byte{
+			Signature: publicKey1,
+		},
+	}
+	policy := func(name string, components []string, key []byte) *v1alpha1.TrustPolicy {
+		p := &v1alpha1.TrustPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Spec: v1alpha1.TrustPolicySpec{
+				Components:      components,
+				SecretNamespace: "ocm-system",
+				Verify: []ocmv1alpha1.Signature{
+					{
+						Name: Signature,
+						PublicKey: ocmv1alpha1.PublicKey{
+							SecretRef: &corev1.LocalObjectReference{
+								Name: keySecret.Name,
+							},
+						},
+					},
+				},
+			},
+		}
+		if key != nil {
+			p.Spec.Verify[0].PublicKey = ocmv1alpha1.PublicKey{
+				Value: base64.StdEncoding.EncodeToString(key),
+			}
+		}
+
+		return p
+	}
+
+	testCases := []struct {
+		name          string
+		policies      []client.Object
+		trustPolicies []string
+		verifications []v1alpha1.SignatureVerification
+		err           string
+	}{
+		{
+			name:     "matching trust policy is enforced",
+			policies: []client.Object{policy("open-component-model", []string{"github.com/open-component-model/*"}, nil)},
+			verifications: []v1alpha1.SignatureVerification{
+				{Name: Signature, TrustPolicy: "open-component-model", Verified: true},
+			},
+		},
+		{
+			name:     "matching trust policy fails verification",
+			policies: []client.Object{policy("open-component-model", []string{"github.com/open-component-model/*"}, publicKey2)},
+			verifications: []v1alpha1.SignatureVerification{
+				{Name: Signature, TrustPolicy: "open-component-model", Verified: false},
+			},
+			err: "trust policy open-component-model",
+		},
+		{
+			name:     "trust policy for other components is ignored",
+			policies: []client.Object{policy("acme", []string{"github.com/acme/*"}, publicKey2)},
+		},
+		{
+			name:          "referenced trust policy is enforced",
+			policies:      []client.Object{policy("acme", nil, nil)},
+			trustPolicies: []string{"acme"},
+			verifications: []v1alpha1.SignatureVerification{
+				{Name: Signature, TrustPolicy: "acme", Verified: true},
+			},
+		},
+		{
+			name:          "referenced trust policy must exist",
+			trustPolicies: []string{"acme"},
+			err:           "trust policy acme not found",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeKubeClient := env.FakeKubeClient(WithObjects(append(tt.policies, keySecret)...))
+			ocmClient := NewClient(fakeKubeClient)
+
+			octx := ocmcontext.NewFakeOCMContext()
+			c := &ocmcontext.Component{
+				Name:    component,
+				Version: "v0.0.1",
+				Sign: &ocmcontext.Sign{
+					Name:    Signature,
+					PrivKey: privateKey,
+					PubKey:  publicKey1,
+					Digest:  "3d879ecdea45acb7f8d85b89fd653288d84af4476eac4141822142ec59c13745",
+				},
+			}
+			require.NoError(t, octx.AddComponent(c))
+
+			cv := &v1alpha1.ComponentSubscription{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-name",
+					Namespace: "default",
+				},
+				Spec: v1alpha1.ComponentSubscriptionSpec{
+					Component: component,
+					Source: v1alpha1.OCMRepository{
+						URL: "localhost",
+					},
+					TrustPolicies: tt.trustPolicies,
+				},
+			}
+
+			verified, err := ocmClient.VerifyComponent(context.Background(), cv, c)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				assert.False(t, verified)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, verified)
+			}
+
+			require.Len(t, cv.Status.Verifications, len(tt.verifications))
+			for i, v := range tt.verifications {
+				assert.Equal(t, v.Name, cv.Status.Verifications[i].Name)
+				assert.Equal(t, v.TrustPolicy, cv.Status.Verifications[i].TrustPolicy)
+				assert.Equal(t, v.Verified, cv.Status.Verifications[i].Verified)
+			}
+		})
+	}
+}
+
+func TestClient_VerifyComponentReferences(t *testing.T) {
+	fakeKubeClient := env.FakeKubeClient()
+	ocmClient := NewClient(fakeKubeClient)
+	octx := ocmcontext.NewFakeOCMContext()
+
+	root := &ocmcontext.Component{
+		Name:    "github.com/acme/root",
+		Version: "v0.0.1",
+	}
+	refA := &ocmcontext.Component{
+		Name:    "github.com/acme/ref-a",
+		Version: "v0.0.1",
+	}
+	refB := &ocmcontext.Component{
+		Name:    "github.com/acme/ref-b",
+		Version: "v0.0.1",
+	}
+	for _, c := range []*ocmcontext.Component{root, refA, refB} {
+		require.NoError(t, octx.AddComponent(c))
+	}
+
+	reference := func(c *ocmcontext.Component) compdesc.ComponentReference {
+		return compdesc.ComponentReference{
+			ElementMeta: compdesc.ElementMeta{
+				Name:    c.Name,
+				Version: c.Version,
+			},
+			ComponentName: c.Name,
+		}
+	}
+	root.ComponentDescriptor.References = compdesc.References{reference(refA), reference(refB)}
+	refA.ComponentDescriptor.References = compdesc.References{reference(refB)}
+
+	// refB is signed by another controller, as every client signs with a single generated key.
+	pubB, err := NewClient(fakeKubeClient).SignDestinationComponent(context.Background(), &v1alpha1.ComponentSubscription{}, refB)
+	require.NoError(t, err)
+	pubA, err := ocmClient.SignDestinationComponent(context.Background(), &v1alpha1.ComponentSubscription{}, refA)
+	require.NoError(t, err)
+
+	signatures := func(name string, pub []byte) v1alpha1.ComponentSignatures {
+		return v1alpha1.ComponentSignatures{
+			Name: name,
+			Verify: []ocmv1alpha1.Signature{
+				{
+					Name: v1alpha1.InternalSignatureName,
+					PublicKey: ocmv1alpha1.PublicKey{
+						Value: base64.StdEncoding.EncodeToString(pub),
+					},
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name       string
+		components []v1alpha1.ComponentSignatures
+		verified   []bool
+		err        string
+	}{
+		{
+			name:       "verifies every referenced component with its own key",
+			components: []v1alpha1.ComponentSignatures{signatures(refA.Name, pubA), signatures("github.com/acme/*", pubB)},
+			verified:   []bool{true, true},
+		},
+		{
+			name:       "fails if a referenced component is signed with another key",
+			components: []v1alpha1.ComponentSignatures{signatures("github.com/acme/*", pubA)},
+			verified:   []bool{true, false},
+			err:        "referenced component github.com/acme/ref-b:v0.0.1",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			cv := &v1alpha1.ComponentSubscription{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-name",
+					Namespace: "default",
+				},
+				Spec: v1alpha1.ComponentSubscriptionSpec{
+					Component: root.Name,
+					Source: v1alpha1.OCMRepository{
+						URL: "localhost",
+					},
+					VerifyReferences: &v1alpha1.ReferenceVerification{
+						Components: tt.components,
+					},
+				},
+			}
+
+			verified, err := ocmClient.VerifyComponent(context.Background(), cv, root)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				assert.False(t, verified)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, verified)
+			}
+
+			require.Len(t, cv.Status.Verifications, len(tt.verified))
+			assert.Equal(t, "github.com/acme/ref-a:v0.0.1", cv.Status.Verifications[0].Component)
+			assert.Equal(t, "github.com/acme/ref-b:v0.0.1", cv.Status.Verifications[1].Component)
+			for i, v := range tt.verified {
+				assert.Equal(t, v, cv.Status.Verifications[i].Verified)
+			}
+		})
+	}
+
+	t.Run("trust policies apply to transferred references", func(t *testing.T) {
+		policy := &v1alpha1.TrustPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "acme-references"},
+			Spec: v1alpha1.TrustPolicySpec{
+				Components: []string{"github.com/acme/ref-*"},
+				Verify:     signatures("", pubA).Verify,
+			},
+		}
+		ocmClient := NewClient(env.FakeKubeClient(WithObjects(policy)))
+
+		for _, follow := range []bool{false, true} {
+			cv := &v1alpha1.ComponentSubscription{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-name",
+					Namespace: "default",
+				},
+				Spec: v1alpha1.ComponentSubscriptionSpec{
+					Component: root.Name,
+					Source: v1alpha1.OCMRepository{
+						URL: "localhost",
+					},
+					FollowReferences: follow,
+				},
+			}
+
+			verified, err := ocmClient.VerifyComponent(context.Background(), cv, root)
+			if follow {
+				// followed references are verified by the subscriptions created for them.
+				assert.NoError(t, err)
+				assert.True(t, verified)
+				assert.Empty(t, cv.Status.Verifications)
+
+				continue
+			}
+
+			assert.ErrorContains(t, err, "referenced component github.com/acme/ref-b:v0.0.1: trust policy acme-references")
+			assert.False(t, verified)
+			require.Len(t, cv.Status.Verifications, 2)
+			assert.Equal(t, "github.com/acme/ref-a:v0.0.1", cv.Status.Verifications[0].Component)
+			assert.True(t, cv.Status.Verifications[0].Verified)
+			assert.Equal(t, "github.com/acme/ref-b:v0.0.1", cv.Status.Verifications[1].Component)
+			assert.False(t, cv.Status.Verifications[1].Verified)
+		}
+	})
+}
+
+func TestClient_SignComponentReferences(t *testing.T) {
+	fakeKubeClient := env.FakeKubeClient()
+	ocmClient := NewClient(fakeKubeClient)
+	octx := ocmcontext.NewFakeOCMContext()
+
+	root := &ocmcontext.Component{
+		Name:    "github.com/acme/root",
+		Version: "v0.0.1",
+	}
+	ref := &ocmcontext.Component{
+		Name:    "github.com/acme/ref",
+		Version: "v0.0.1",
+	}
+	require.NoError(t, octx.AddComponent(root))
+	require.NoError(t, octx.AddComponent(ref))
+
+	root.ComponentDescriptor.References = compdesc.References{
+		{
+			ElementMeta: compdesc.ElementMeta{
+				Name:    ref.Name,
+				Version: ref.Version,
+			},
+			ComponentName: ref.Name,
+		},
+	}
+
+	cs := &v1alpha1.ComponentSubscription{
+		Spec: v1alpha1.ComponentSubscriptionSpec{
+			Component: root.Name,
+			VerifyReferences: &v1alpha1.ReferenceVerification{
+				Sign: true,
+			},
+		},
+	}
+
+	_, err := ocmClient.SignDestinationComponent(context.Background(), cs, root)
+	require.NoError(t, err)
+
+	require.Len(t, ref.GetDescriptor().Signatures, 1)
+	assert.Equal(t, v1alpha1.InternalSignatureName, ref.GetDescriptor().Signatures[0].Name)
+}
+
+func TestClient_ReplicationDoesNotModifySource(t *testing.T) {
+	registry := httptest.NewServer(handlers.NewApp(context.Background(), &configuration.Configuration{
+		Storage: configuration.Storage{"inmemory": configuration.Parameters{}},
+	}))
+	defer registry.Close()
+
+	component := "github.com/acme/component"
+	version := "v0.0.1"
+	obj := &v1alpha1.ComponentSubscription{
+		Spec: v1alpha1.ComponentSubscriptionSpec{
+			Component: component,
+			Source: v1alpha1.OCMRepository{
+				URL: registry.URL + "/source",
+			},
+			Destination: &v1alpha1.OCMRepository{
+				URL: registry.URL + "/destination",
+			},
+		},
+	}
+
+	octx := ocm.New()
+	repo, err := octx.RepositoryForSpec(ocireg.NewRepositorySpec(obj.Spec.Source.URL, nil))
+	require.NoError(t, err)
+	comp, err := repo.LookupComponent(component)
+	require.NoError(t, err)
+	cv, err := comp.NewVersion(version)
+	require.NoError(t, err)
+	cv.GetDescriptor().Provider.Name = "acme"
+	require.NoError(t, cv.SetResourceBlob(
+		compdesc.NewResourceMeta("data", resourcetypes.PLAIN_TEXT, ocmmetav1.LocalRelation),
+		accessio.BlobAccessForString(mime.MIME_TEXT, "data"),
+		"",
+		nil,
+	))
+	require.NoError(t, comp.AddVersion(cv))
+	require.NoError(t, cv.Close())
+	require.NoError(t, comp.Close())
+	require.NoError(t, repo.Close())
+
+	manifestDigest := func(repository string) string {
+		req, err := http.NewRequest(http.MethodHead, fmt.Sprintf("%s/v2/%s/component-descriptors/%s/manifests/%s", registry.URL, repository, component, version), nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", ociv1.MediaTypeImageManifest)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		return resp.Header.Get("Docker-Content-Digest")
+	}
+	sourceDigest := manifestDigest("source")
+
+	ocmClient := NewClient(env.FakeKubeClient())
+	source, err := ocmClient.GetComponentVersion(context.Background(), octx, obj, version)
+	require.NoError(t, err)
+	defer source.Close()
+
+	require.NoError(t, ocmClient.TransferComponent(context.Background(), octx, obj, source))
+
+	destination, err := ocmClient.GetDestinationComponentVersion(context.Background(), octx, obj, version)
+	require.NoError(t, err)
+	pub, err := ocmClient.SignDestinationComponent(context.Background(), obj, destination)
+	require.NoError(t, err)
+	require.NoError(t, destination.Close())
+
+	assert.Equal(t, sourceDigest, manifestDigest("source"), "source component version must not be modified")
+	assert.NotEqual(t, sourceDigest, manifestDigest("destination"))
+
+	unchanged, err := ocmClient.GetComponentVersion(context.Background(), octx, obj, version)
+	require.NoError(t, err)
+	defer unchanged.Close()
+	assert.Empty(t, unchanged.GetDescriptor().Signatures)
+
+	signed, err := ocmClient.GetDestinationComponentVersion(context.Background(), octx, obj, version)
+	require.NoError(t, err)
+	defer signed.Close()
+
+	obj.Spec.Verify = []ocmv1alpha1.Signature{
+		{
+			Name: v1alpha1.InternalSignatureName,
+			PublicKey: ocmv1alpha1.PublicKey{
+				Value: base64.StdEncoding.EncodeToString(pub),
+			},
+		},
+	}
+	verified, err := ocmClient.VerifyComponent(context.Background(), obj, signed)
+	require.NoError(t, err)
+	assert.True(t, verified)
+}
+
+func TestClient_SignComponentPublishesPublicKey(t *testing.T) {
+	fakeKubeClient := env.FakeKubeClient()
+	store := keys.NewStore()
+	configMap := types.NamespacedName{Namespace: "ocm-system", Name: "replication-public-keys"}
+	ocmClient := NewClient(fakeKubeClient, WithPublicKeyStore(store), WithPublicKeyConfigMap(configMap))
+
+	octx := ocmcontext.NewFakeOCMContext()
+	c := &ocmcontext.Component{
+		Name:    "github.com/open-component-model/ocm-demo-index",
+		Version: "v0.0.1",
+	}
+	require.NoError(t, octx.AddComponent(c))
+
+	pub, err := ocmClient.SignDestinationComponent(context.Background(), &v1alpha1.ComponentSubscription{}, c)
+	require.NoError(t, err)
+
+	published := store.Keys()
+	require.Len(t, published, 1)
+	assert.Equal(t, pub, published[0].PEM)
+
+	cm := &corev1.ConfigMap{}
+	require.NoError(t, fakeKubeClient.Get(context.Background(), configMap, cm))
+	assert.Equal(t, string(pub), cm.Data[published[0].ID+".pem"])
+
+	again, err := ocmClient.SignDestinationComponent(context.Background(), &v1alpha1.ComponentSubscription{}, c)
+	require.NoError(t, err)
+	assert.Equal(t, pub, again)
+	assert.Equal(t, published, store.Keys())
+}
+
+func TestClient_SignComponentRotatesGeneratedKey(t *testing.T) {
+	store := keys.NewStore()
+	ocmClient := NewClient(env.FakeKubeClient(), WithPublicKeyStore(store), WithKeyRotation(time.Hour))
+	now := time.Now()
+	ocmClient.generatedKey.now = func() time.Time { return now }
+
+	octx := ocmcontext.NewFakeOCMContext()
+	c := &ocmcontext.Component{
+		Name:    "github.com/open-component-model/ocm-demo-index",
+		Version: "v0.0.1",
+	}
+	require.NoError(t, octx.AddComponent(c))
+
+	first, err := ocmClient.SignDestinationComponent(context.Background(), &v1alpha1.ComponentSubscription{}, c)
+	require.NoError(t, err)
+
+	now = now.Add(59 * time.Minute)
+	kept, err := ocmClient.SignDestinationComponent(context.Background(), &v1alpha1.ComponentSubscription{}, c)
+	require.NoError(t, err)
+	assert.Equal(t, first, kept)
+	require.Len(t, store.Keys(), 1)
+
+	now = now.Add(time.Minute)
+	rotated, err := ocmClient.SignDestinationComponent(context.Background(), &v1alpha1.ComponentSubscription{}, c)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, rotated)
+
+	published := store.Keys()
+	require.Len(t, published, 2)
+	assert.Equal(t, rotated, published[0].PEM)
+	assert.Equal(t, first, published[1].PEM)
+}
+
+// testSigner is a sign.Signer backed by an in-memory private key.
+type testSigner struct {
+	*gorsa.PrivateKey
+	publicPEM []byte
+}
+
+func (s *testSigner) PublicKeyPEM() []byte {
+	return s.publicPEM
+}
+
+func TestClient_SignComponentWithSigner(t *testing.T) {
+	priv, pub, err := rsa.Handler{}.CreateKeyPair()
+	require.NoError(t, err)
+	key, ok := priv.(*gorsa.PrivateKey)
+	require.True(t, ok)
+
+	caPriv, caPub, err := rsa.Handler{}.CreateKeyPair()
+	require.NoError(t, err)
+	ca, caPEM, err := signutils.CreateCertificate(&signutils.Specification{
+		IsCA:         true,
+		PublicKey:    caPub,
+		CAPrivateKey: caPriv,
+		Subject: pkix.Name{
+			CommonName: "ca-authority",
+		},
+		Usages:   signutils.Usages{x509.ExtKeyUsageCodeSigning},
+		Validity: 10 * time.Hour,
+	})
+	require.NoError(t, err)
+	_, certPEM, err := signutils.CreateCertificate(&signutils.Specification{
+		RootCAs:      ca,
+		CAChain:      ca,
+		PublicKey:    pub,
+		CAPrivateKey: caPriv,
+		Subject: pkix.Name{
+			CommonName: "hsm",
+		},
+		Usages:   signutils.Usages{x509.ExtKeyUsageCodeSigning},
+		Validity: 10 * time.Hour,
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		publicPEM []byte
+	}{
+		{
+			name:      "signs with a plain public key",
+			publicPEM: pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&key.PublicKey)}),
+		},
+		{
+			name:      "signs with a certificate chain",
+			publicPEM: append(append([]byte{}, certPEM...), caPEM...),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			ocmClient := NewClient(env.FakeKubeClient(), WithSigner(&testSigner{PrivateKey: key, publicPEM: tt.publicPEM}))
+			component := "github.com/open-component-model/ocm-demo-index"
+
+			octx := ocmcontext.NewFakeOCMContext()
+			c := &ocmcontext.Component{
+				Name:    component,
+				Version: "v0.0.1",
+			}
+			require.NoError(t, octx.AddComponent(c))
+
+			published, err := ocmClient.SignDestinationComponent(context.Background(), &v1alpha1.ComponentSubscription{}, c)
+			require.NoError(t, err)
+			assert.Equal(t, tt.publicPEM, published)
+
+			require.Len(t, c.GetDescriptor().Signatures, 1)
+			assert.Equal(t, rsa.Algorithm, c.GetDescriptor().Signatures[0].Signature.Algorithm)
+			require.NoError(t, verifySignature(c, v1alpha1.InternalSignatureName,
+				signing.PublicKey(v1alpha1.InternalSignatureName, key.Public()),
+				signing.PKIXIssuerFor(v1alpha1.InternalSignatureName, pkix.Name{}),
+			))
+		})
+	}
+}
+
+func TestClient_AddProvenance(t *testing.T) {
+	ocmClient := NewClient(env.FakeKubeClient())
+	octx := ocmcontext.NewFakeOCMContext()
+
+	source := &ocmcontext.Component{
+		Name:    "github.com/acme/component",
+		Version: "v0.0.1",
+	}
+	destination := &ocmcontext.Component{
+		Name:    "github.com/acme/component",
+		Version: "v0.0.1",
+	}
+	require.NoError(t, octx.AddComponent(source))
+	require.NoError(t, octx.AddComponent(destination))
+
+	// the publisher signed the component before it was replicated.
+	publisherKey, publisherPub, err := rsa.Handler{}.CreateKeyPair()
+	require.NoError(t, err)
+	_, err = signing.SignComponentVersion(destination, "publisher",
+		signing.PrivateKey("publisher", publisherKey),
+		signing.Resolver(destination.Repository()),
+	)
+	require.NoError(t, err)
+	publisherOpts := []signing.Option{signing.PublicKey("publisher", publisherPub)}
+	require.NoError(t, verifySignature(destination, "publisher", publisherOpts...))
+
+	obj := &v1alpha1.ComponentSubscription{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "acme",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.ComponentSubscriptionSpec{
+			Component:  source.Name,
+			Provenance: true,
+			Source: v1alpha1.OCMRepository{
+				URL: "ghcr.io/acme",
+			},
+		},
+	}
+
+	require.NoError(t, ocmClient.AddProvenance(context.Background(), obj, source, destination))
+
+	labels := destination.GetDescriptor().Labels
+	for _, name := range []string{
+		v1alpha1.SourceRepositoryLabel,
+		v1alpha1.SourceDigestLabel,
+		v1alpha1.ReplicatedAtLabel,
+		v1alpha1.SubscriptionLabel,
+		v1alpha1.ControllerVersionLabel,
+	} {
+		label := labels.GetDef(name)
+		require.NotNil(t, label, "label %s should have been set", name)
+		assert.False(t, label.Signing, "label %s must not change the digest of the component", name)
+	}
+
+	var subscription string
+	_, err = labels.GetValue(v1alpha1.SubscriptionLabel, &subscription)
+	require.NoError(t, err)
+	assert.Equal(t, "default/acme", subscription)
+	assert.Empty(t, source.GetDescriptor().Labels, "the source must not be modified")
+	require.NoError(t, verifySignature(destination, "publisher", publisherOpts...), "the publisher signature must stay valid")
+
+	pub, err := ocmClient.SignDestinationComponent(context.Background(), obj, destination)
+	require.NoError(t, err)
+
+	require.NoError(t, verifySignature(destination, v1alpha1.InternalSignatureName, signing.PublicKey(v1alpha1.InternalSignatureName, pub)))
+	require.NoError(t, verifySignature(destination, "publisher", publisherOpts...), "the publisher signature must survive the replication signature")
+}
+
+func TestClient_AttestReplication(t *testing.T) {
+	registry := httptest.NewServer(handlers.NewApp(context.Background(), &configuration.Configuration{
+		Storage: configuration.Storage{"inmemory": configuration.Parameters{}},
+	}))
+	defer registry.Close()
+
+	component := "github.com/acme/component"
+	version := "v0.0.1+build.1"
+	obj := &v1alpha1.ComponentSubscription{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "subscription",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.ComponentSubscriptionSpec{
+			Component: component,
+			Source: v1alpha1.OCMRepository{
+				URL: registry.URL + "/source",
+			},
+			Destination: &v1alpha1.OCMRepository{
+				URL: registry.URL + "/destination",
+			},
+			Attestation: true,
+		},
+		Status: v1alpha1.ComponentSubscriptionStatus{
+			Verifications: []v1alpha1.SignatureVerification{
+				{Name: "upstream", Verified: true},
+			},
+		},
+	}
+
+	octx := ocm.New()
+	repo, err := octx.RepositoryForSpec(ocireg.NewRepositorySpec(obj.Spec.Source.URL, nil))
+	require.NoError(t, err)
+	comp, err := repo.LookupComponent(component)
+	require.NoError(t, err)
+	cv, err := comp.NewVersion(version)
+	require.NoError(t, err)
+	cv.GetDescriptor().Provider.Name = "acme"
+	require.NoError(t, comp.AddVersion(cv))
+	require.NoError(t, cv.Close())
+	require.NoError(t, comp.Close())
+	require.NoError(t, repo.Close())
+
+	store := keys.NewStore()
+	ocmClient := NewClient(env.FakeKubeClient(), WithPublicKeyStore(store))
+	source, err := ocmClient.GetComponentVersion(context.Background(), octx, obj, version)
+	require.NoError(t, err)
+	defer source.Close()
+
+	startedOn := time.Now()
+	require.NoError(t, ocmClient.TransferComponent(context.Background(), octx, obj, source))
+
+	destination, err := ocmClient.GetDestinationComponentVersion(context.Background(), octx, obj, version)
+	require.NoError(t, err)
+	pub, err := ocmClient.SignDestinationComponent(context.Background(), obj, destination)
+	require.NoError(t, err)
+	result, err := ocmClient.AttestReplication(context.Background(), obj, source, destination, startedOn)
+	require.NoError(t, err)
+	digest, err := compdesc.Hash(destination.GetDescriptor(), compdesc.JsonNormalisationV2, sha256.New())
+	require.NoError(t, err)
+	require.NoError(t, destination.Close())
+
+	host := strings.TrimPrefix(registry.URL, "http://")
+	namespace := "destination/attestations/" + component
+	assert.Equal(t, host+"/"+namespace+":v0.0.1.build-build.1", result.Reference)
+
+	key, ok := store.Get(result.KeyID)
+	require.True(t, ok, "attestation key must be published")
+	assert.Equal(t, base64.StdEncoding.EncodeToString(key.PEM), result.PublicKey)
+	assert.Equal(t, base64.StdEncoding.EncodeToString(pub), result.PublicKey, "attestations must be signed with the replication signing key")
+	assert.Len(t, store.Keys(), 1)
+
+	// the attestation must not show up as a version of the component.
+	repository, err := octx.RepositoryForSpec(ocireg.NewRepositorySpec(obj.Spec.Destination.URL, nil))
+	require.NoError(t, err)
+	versions, err := listVersions(repository, component)
+	require.NoError(t, err)
+	require.NoError(t, repository.Close())
+	assert.Equal(t, []string{version}, versions)
+
+	get := func(path string, accept string) []byte {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v2/%s/%s", registry.URL, namespace, path), nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", accept)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return data
+	}
+
+	manifest := ociv1.Manifest{}
+	require.NoError(t, json.Unmarshal(get("manifests/v0.0.1.build-build.1", ociv1.MediaTypeImageManifest), &manifest))
+	require.Len(t, manifest.Layers, 1)
+	assert.Equal(t, attestation.MediaType, manifest.Layers[0].MediaType)
+
+	envelope := dsse.Envelope{}
+	require.NoError(t, json.Unmarshal(get("blobs/"+manifest.Layers[0].Digest.String(), "*/*"), &envelope))
+	assert.Equal(t, attestation.PayloadType, envelope.PayloadType)
+	require.Len(t, envelope.Signatures, 1)
+	assert.Equal(t, result.KeyID, envelope.Signatures[0].KeyID)
+
+	payload, err := envelope.DecodeB64Payload()
+	require.NoError(t, err)
+	sig, err := base64.StdEncoding.DecodeString(envelope.Signatures[0].Sig)
+	require.NoError(t, err)
+	hash := sha256.Sum256(dsse.PAE(envelope.PayloadType, payload))
+	publicKey, ok := key.PublicKey.(*gorsa.PublicKey)
+	require.True(t, ok)
+	assert.NoError(t, gorsa.VerifyPKCS1v15(publicKey, crypto.SHA256, hash[:], sig))
+
+	statement := attestation.Statement{}
+	require.NoError(t, json.Unmarshal(payload, &statement))
+	assert.Equal(t, attestation.PredicateType, statement.PredicateType)
+	require.Len(t, statement.Subject, 1)
+	assert.Equal(t, component+":"+version, statement.Subject[0].Name)
+	assert.Equal(t, digest, statement.Subject[0].Digest["sha256"])
+	assert.Equal(t, obj.Spec.Source.URL, statement.Predicate.Source.URL)
+	assert.Equal(t, obj.Spec.Destination.URL, statement.Predicate.Destination.URL)
+	assert.Equal(t, "default/subscription", statement.Predicate.Subscription)
+	assert.Equal(t, obj.Status.Verifications, statement.Predicate.Verifications)
+	assert.True(t, statement.Predicate.Transfer.Recursive)
+	assert.False(t, statement.Predicate.FinishedOn.Before(statement.Predicate.StartedOn))
+}
+
+// signImage attaches a Notary Project signature created with a certificate issued by the CA to the image.
+func signImage(t *testing.T, ca *x509.Certificate, caPriv any, image name.Digest, opts ...remote.Option) {
+	t.Helper()
+
+	target, err := remote.Head(image, opts...)
+	require.NoError(t, err)
+
+	priv, pub, err := rsa.Handler{}.CreateKeyPair()
+	require.NoError(t, err)
+	cert, _, err := signutils.CreateCertificate(&signutils.Specification{
+		RootCAs:      ca,
+		CAChain:      ca,
+		PublicKey:    pub,
+		CAPrivateKey: caPriv,
+		Subject:      pkix.Name{CommonName: "release", Organization: []string{"acme"}, Province: []string{"BW"}, Country: []string{"DE"}},
+		Usages:       signutils.Usages{x509.ExtKeyUsageCodeSigning, x509.KeyUsageDigitalSignature},
+		Validity:     10 * time.Hour,
+	})
+	require.NoError(t, err)
+
+	signer, err := notarysignature.NewLocalSigner([]*x509.Certificate{cert, ca}, priv)
+	require.NoError(t, err)
+	payload, err := json.Marshal(map[string]any{"targetArtifact": target})
+	require.NoError(t, err)
+	env, err := notarysignature.NewEnvelope(notation.MediaTypeJWS)
+	require.NoError(t, err)
+	envelope, err := env.Sign(&notarysignature.SignRequest{
+		Payload:       notarysignature.Payload{ContentType: notation.MediaTypePayload, Content: payload},
+		Signer:        signer,
+		SigningTime:   time.Now(),
+		SigningScheme: notarysignature.SigningSchemeX509,
+		SigningAgent:  "replication-controller-test",
+	})
+	require.NoError(t, err)
+
+	img, err := mutate.Append(empty.Image, mutate.Addendum{Layer: static.NewLayer(envelope, notation.MediaTypeJWS)})
+	require.NoError(t, err)
+	img = mutate.ConfigMediaType(mutate.MediaType(img, ggcrtypes.OCIManifestSchema1), notation.ArtifactType)
+	img, ok := mutate.Subject(img, *target).(ggcrv1.Image)
+	require.True(t, ok)
+	digest, err := img.Digest()
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(image.Context().Digest(digest.String()), img, opts...))
+}
+
+func TestClient_TransferComponentVerifiesImages(t *testing.T) {
+	// OCM drops the scheme of image references, so the registry is served over TLS.
+	registry := httptest.NewTLSServer(handlers.NewApp(context.Background(), &configuration.Configuration{
+		Storage: configuration.Storage{"inmemory": configuration.Parameters{}},
+	}))
+	defer registry.Close()
+	host := strings.TrimPrefix(registry.URL, "https://")
+	transport := remote.WithTransport(registry.Client().Transport)
+
+	caPriv, caPub, err := rsa.Handler{}.CreateKeyPair()
+	require.NoError(t, err)
+	ca, caPEM, err := signutils.CreateCertificate(&signutils.Specification{
+		IsCA:         true,
+		PublicKey:    caPub,
+		CAPrivateKey: caPriv,
+		Subject:      pkix.Name{CommonName: "ca-authority"},
+		Usages:       signutils.Usages{x509.ExtKeyUsageCodeSigning},
+		Validity:     10 * time.Hour,
+	})
+	require.NoError(t, err)
+
+	pushImage := func(repository string) name.Digest {
+		img, err := random.Image(64, 1)
+		require.NoError(t, err)
+		ref, err := name.ParseReference(host + "/" + repository + ":v1")
+		require.NoError(t, err)
+		require.NoError(t, remote.Write(ref, mutate.MediaType(img, ggcrtypes.OCIManifestSchema1), transport))
+		desc, err := remote.Head(ref, transport)
+		require.NoError(t, err)
+
+		return ref.Context().Digest(desc.Digest.String())
+	}
+	signed := pushImage("images/signed")
+	signImage(t, ca, caPriv, signed, transport)
+	unsigned := pushImage("images/unsigned")
+
+	component := "github.com/acme/component"
+	version := "v0.0.1"
+	newContext := func() ocm.Context {
+		octx := ocm.New()
+		require.NoError(t, rootcertsattr.Get(octx).RegisterRootCertificates(registry.Certificate()))
+
+		return octx
+	}
+
+	createComponent := func(repository string, images map[string]string) {
+		repo, err := newContext().RepositoryForSpec(ocireg.NewRepositorySpec(host+"/"+repository, nil))
+		require.NoError(t, err)
+		comp, err := repo.LookupComponent(component)
+		require.NoError(t, err)
+		cv, err := comp.NewVersion(version)
+		require.NoError(t, err)
+		cv.GetDescriptor().Provider.Name = "acme"
+		for name, image := range images {
+			meta := compdesc.NewResourceMeta(name, resourcetypes.OCI_IMAGE, ocmmetav1.ExternalRelation)
+			meta.Version = "v1.0.0"
+			require.NoError(t, cv.SetResource(meta, ociartifact.New(image)))
+		}
+		require.NoError(t, comp.AddVersion(cv))
+		require.NoError(t, cv.Close())
+		require.NoError(t, comp.Close())
+		require.NoError(t, repo.Close())
+	}
+	createComponent("signed", map[string]string{"app": signed.String()})
+	createComponent("unsigned", map[string]string{"app": signed.String(), "sidecar": unsigned.String()})
+
+	trustStore := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "trust-store", Namespace: "default"},
+		Data:       map[string][]byte{caCertKey: caPEM},
+	}
+
+	testCases := []struct {
+		name        string
+		source      string
+		destination string
+		identities  []string
+		verified    []bool
+		err         string
+	}{
+		{
+			name:        "signed images are transferred with their signatures",
+			source:      "signed",
+			destination: "mirror-signed",
+			identities:  []string{"x509.subject: C=DE, ST=BW, O=acme"},
+			verified:    []bool{true},
+		},
+		{
+			name:        "unsigned images are not transferred",
+			source:      "unsigned",
+			destination: "mirror-unsigned",
+			verified:    []bool{true, false},
+			err:         "failed to verify signatures of images: github.com/acme/component:v0.0.1/sidecar",
+		},
+		{
+			name:        "images signed by an untrusted identity are not transferred",
+			source:      "signed",
+			destination: "mirror-evil",
+			identities:  []string{"x509.subject: C=DE, ST=BW, O=evil"},
+			verified:    []bool{false},
+			err:         "failed to verify images",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &v1alpha1.ComponentSubscription{
+				ObjectMeta: metav1.ObjectMeta{Name: "subscription", Namespace: "default"},
+				Spec: v1alpha1.ComponentSubscriptionSpec{
+					Component:   component,
+					Source:      v1alpha1.OCMRepository{URL: host + "/" + tt.source},
+					Destination: &v1alpha1.OCMRepository{URL: host + "/" + tt.destination},
+					VerifyImages: &v1alpha1.ImageVerification{
+						TrustStores:       []corev1.LocalObjectReference{{Name: trustStore.Name}},
+						TrustedIdentities: tt.identities,
+						CopySignatures:    true,
+					},
+				},
+			}
+
+			octx := newContext()
+			ocmClient := NewClient(env.FakeKubeClient(WithObjects(trustStore)))
+			source, err := ocmClient.GetComponentVersion(context.Background(), octx, obj, version)
+			require.NoError(t, err)
+			defer source.Close()
+
+			err = ocmClient.TransferComponent(context.Background(), octx, obj, source)
+
+			verified := make([]bool, 0, len(obj.Status.ImageVerifications))
+			for _, v := range obj.Status.ImageVerifications {
+				verified = append(verified, v.Verified)
+			}
+			assert.ElementsMatch(t, tt.verified, verified)
+
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				_, err := ocmClient.GetDestinationComponentVersion(context.Background(), octx, obj, version)
+				assert.Error(t, err, "component must not be transferred")
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, signed.String(), obj.Status.ImageVerifications[0].Image)
+			assert.Equal(t, "CN=release,O=acme,ST=BW,C=DE", obj.Status.ImageVerifications[0].Signer)
+
+			destination, err := ocmClient.GetDestinationComponentVersion(context.Background(), octx, obj, version)
+			require.NoError(t, err)
+			defer destination.Close()
+
+			images, err := listImages(destination, transferredReferences(obj))
+			require.NoError(t, err)
+			require.Len(t, images, 1)
+			assert.True(t, strings.HasPrefix(images[0].reference, host+"/mirror-signed/"), images[0].reference)
+
+			copied, err := resolveDigest(images[0].reference, transport)
+			require.NoError(t, err)
+			verifier, err := notation.NewVerifier(notation.TrustStores{trustStore.Name: {ca}}, nil)
+			require.NoError(t, err)
+			_, err = verifier.Verify(context.Background(), copied, transport)
+			assert.NoError(t, err, "signature must be copied to the destination")
+		})
+	}
+}
+
+func TestClient_TransferComponentPlatforms(t *testing.T) {
+	registry := httptest.NewTLSServer(handlers.NewApp(context.Background(), &configuration.Configuration{
+		Storage: configuration.Storage{"inmemory": configuration.Parameters{}},
+	}))
+	defer registry.Close()
+	host := strings.TrimPrefix(registry.URL, "https://")
+	transport := remote.WithTransport(registry.Client().Transport)
+
+	randomImage := func() ggcrv1.Image {
+		img, err := random.Image(64, 1)
+		require.NoError(t, err)
+
+		return mutate.MediaType(img, ggcrtypes.OCIManifestSchema1)
+	}
+
+	amd64, arm64 := randomImage(), randomImage()
+	amd64Digest, err := amd64.Digest()
+	require.NoError(t, err)
+
+	index := mutate.IndexMediaType(mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{Add: amd64, Descriptor: ggcrv1.Descriptor{Platform: &ggcrv1.Platform{OS: "linux", Architecture: "amd64"}}},
+		mutate.IndexAddendum{Add: arm64, Descriptor: ggcrv1.Descriptor{Platform: &ggcrv1.Platform{OS: "linux", Architecture: "arm64"}}},
+	), ggcrtypes.OCIImageIndex)
+	indexDigest, err := index.Digest()
+	require.NoError(t, err)
+	multiRef, err := name.ParseReference(host + "/images/multi:v1")
+	require.NoError(t, err)
+	require.NoError(t, remote.WriteIndex(multiRef, index, transport))
+
+	single := randomImage()
+	singleDigest, err := single.Digest()
+	require.NoError(t, err)
+	singleRef, err := name.ParseReference(host + "/images/single:v1")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(singleRef, single, transport))
+
+	newContext := func() ocm.Context {
+		octx := ocm.New()
+		require.NoError(t, rootcertsattr.Get(octx).RegisterRootCertificates(registry.Certificate()))
+
+		return octx
+	}
+
+	component := "github.com/acme/component"
+	dependency := "github.com/acme/dependency"
+	version := "v0.0.1"
+	repo, err := newContext().RepositoryForSpec(ocireg.NewRepositorySpec(host+"/source", nil))
+	require.NoError(t, err)
+	publish := func(name string, images map[string]string, references ...compdesc.ComponentReference) {
+		comp, err := repo.LookupComponent(name)
+		require.NoError(t, err)
+		cv, err := comp.NewVersion(version)
+		require.NoError(t, err)
+		cv.GetDescriptor().Provider.Name = "acme"
+		cv.GetDescriptor().References = references
+		for resource, image := range images {
+			meta := compdesc.NewResourceMeta(resource, resourcetypes.OCI_IMAGE, ocmmetav1.ExternalRelation)
+			meta.Version = "v1.0.0"
+			require.NoError(t, cv.SetResource(meta, ociartifact.New(image)))
+		}
+		require.NoError(t, comp.AddVersion(cv))
+		require.NoError(t, cv.Close())
+		require.NoError(t, comp.Close())
+	}
+	multiImage := multiRef.Context().Digest(indexDigest.String()).String()
+	publish(dependency, map[string]string{"multi": multiImage})
+	publish(component, map[string]string{
+		"multi":  multiImage,
+		"single": singleRef.Context().Digest(singleDigest.String()).String(),
+	}, compdesc.ComponentReference{
+		ElementMeta:   compdesc.ElementMeta{Name: "dependency", Version: version},
+		ComponentName: dependency,
+	})
+
+	// signing records the digest of the referenced component in the reference.
+	priv, _, err := rsa.Handler{}.CreateKeyPair()
+	require.NoError(t, err)
+	cv, err := repo.LookupComponentVersion(component, version)
+	require.NoError(t, err)
+	_, err = signing.SignComponentVersion(cv, "acme", signing.PrivateKey("acme", priv), signing.Resolver(repo))
+	require.NoError(t, err)
+	require.NoError(t, cv.Close())
+	require.NoError(t, repo.Close())
+
+	testCases := []struct {
+		name        string
+		destination string
+		platforms   []string
+		multi       ggcrv1.Hash
+		err         string
+	}{
+		{
+			name:        "index is trimmed to the selected platform",
+			destination: "mirror-amd64",
+			platforms:   []string{"linux/amd64"},
+			multi:       amd64Digest,
+		},
+		{
+			name:        "index matching all platforms is unchanged",
+			destination: "mirror-all",
+			platforms:   []string{"linux/amd64", "linux/arm64"},
+			multi:       indexDigest,
+		},
+		{
+			name:        "index without a matching platform fails the transfer",
+			destination: "mirror-windows",
+			platforms:   []string{"windows/amd64"},
+			err:         "contains no image for the selected platforms",
+		},
+		{
+			name:        "invalid platform is rejected",
+			destination: "mirror-invalid",
+			platforms:   []string{"linux"},
+			err:         "invalid platform \"linux\", expected os/arch",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &v1alpha1.ComponentSubscription{
+				ObjectMeta: metav1.ObjectMeta{Name: "subscription", Namespace: "default"},
+				Spec: v1alpha1.ComponentSubscriptionSpec{
+					Component:   component,
+					Source:      v1alpha1.OCMRepository{URL: host + "/source"},
+					Destination: &v1alpha1.OCMRepository{URL: host + "/" + tt.destination},
+					Transfer:    &v1alpha1.TransferSpec{Platforms: tt.platforms},
+				},
+			}
+
+			octx := newContext()
+			ocmClient := NewClient(env.FakeKubeClient())
+			source, err := ocmClient.GetComponentVersion(context.Background(), octx, obj, version)
+			require.NoError(t, err)
+			defer source.Close()
+
+			err = ocmClient.TransferComponent(context.Background(), octx, obj, source)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+
+				return
+			}
+			require.NoError(t, err)
+
+			destination, err := ocmClient.GetDestinationComponentVersion(context.Background(), octx, obj, version)
+			require.NoError(t, err)
+			defer destination.Close()
+
+			expected := map[string]ggcrv1.Hash{"multi": tt.multi, "single": singleDigest}
+			for _, res := range destination.GetResources() {
+				spec, err := res.Access()
+				require.NoError(t, err)
+				acc, ok := spec.(*ociartifact.AccessSpec)
+				require.True(t, ok)
+				assert.True(t, strings.HasPrefix(acc.ImageReference, host+"/"+tt.destination+"/"), acc.ImageReference)
+
+				image, err := resolveDigest(acc.ImageReference, transport)
+				require.NoError(t, err)
+				assert.Equal(t, expected[res.Meta().GetName()].String(), image.DigestStr())
+				assert.Equal(t, expected[res.Meta().GetName()].Hex, res.Meta().Digest.Value, "resource digest must match the image")
+			}
+
+			nested, err := destination.Repository().LookupComponentVersion(dependency, version)
+			require.NoError(t, err)
+			defer nested.Close()
+
+			resources := nested.GetResources()
+			require.Len(t, resources, 1)
+			assert.Equal(t, tt.multi.Hex, resources[0].Meta().Digest.Value, "resource digest of the reference must match the image")
+
+			references := destination.GetDescriptor().References
+			require.Len(t, references, 1)
+			require.NotNil(t, references[0].Digest)
+			digest, err := compdesc.Hash(nested.GetDescriptor(), references[0].Digest.NormalisationAlgorithm, sha256.New())
+			require.NoError(t, err)
+			assert.Equal(t, digest, references[0].Digest.Value, "reference digest must match the referenced component")
+			assert.Equal(t, tt.multi == indexDigest, len(destination.GetDescriptor().Signatures) == 1, "signature must only be kept if nothing changed")
+		})
+	}
+}
+
+func TestClient_MirrorImages(t *testing.T) {
+	registry := httptest.NewTLSServer(handlers.NewApp(context.Background(), &configuration.Configuration{
+		Storage: configuration.Storage{"inmemory": configuration.Parameters{}},
+	}))
+	defer registry.Close()
+	host := strings.TrimPrefix(registry.URL, "https://")
+	transport := remote.WithTransport(registry.Client().Transport)
+
+	randomImage := func() ggcrv1.Image {
+		img, err := random.Image(64, 1)
+		require.NoError(t, err)
+
+		return mutate.MediaType(img, ggcrtypes.OCIManifestSchema1)
+	}
+
+	amd64, arm64 := randomImage(), randomImage()
+	arm64Digest, err := arm64.Digest()
+	require.NoError(t, err)
+
+	index := mutate.IndexMediaType(mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{Add: amd64, Descriptor: ggcrv1.Descriptor{Platform: &ggcrv1.Platform{OS: "linux", Architecture: "amd64"}}},
+		mutate.IndexAddendum{Add: arm64, Descriptor: ggcrv1.Descriptor{Platform: &ggcrv1.Platform{OS: "linux", Architecture: "arm64"}}},
+	), ggcrtypes.OCIImageIndex)
+	indexDigest, err := index.Digest()
+	require.NoError(t, err)
+	appRef, err := name.ParseReference(host + "/images/app:latest")
+	require.NoError(t, err)
+	require.NoError(t, remote.WriteIndex(appRef, index, transport))
+
+	sidecar := randomImage()
+	sidecarDigest, err := sidecar.Digest()
+	require.NoError(t, err)
+	sidecarRef, err := name.ParseReference(host + "/images/sidecar:latest")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(sidecarRef, sidecar, transport))
+
+	dependencyImage := randomImage()
+	dependencyDigest, err := dependencyImage.Digest()
+	require.NoError(t, err)
+	dependencyRef, err := name.ParseReference(host + "/images/dependency:latest")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(dependencyRef, dependencyImage, transport))
+
+	caPriv, caPub, err := rsa.Handler{}.CreateKeyPair()
+	require.NoError(t, err)
+	ca, caPEM, err := signutils.CreateCertificate(&signutils.Specification{
+		IsCA:         true,
+		PublicKey:    caPub,
+		CAPrivateKey: caPriv,
+		Subject:      pkix.Name{CommonName: "ca-authority"},
+		Usages:       signutils.Usages{x509.ExtKeyUsageCodeSigning},
+		Validity:     10 * time.Hour,
+	})
+	require.NoError(t, err)
+	for _, image := range []name.Digest{
+		appRef.Context().Digest(indexDigest.String()),
+		sidecarRef.Context().Digest(sidecarDigest.String()),
+		dependencyRef.Context().Digest(dependencyDigest.String()),
+	} {
+		signImage(t, ca, caPriv, image, transport)
+	}
+	trustStore := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "trust-store", Namespace: "default"},
+		Data:       map[string][]byte{caCertKey: caPEM},
+	}
+
+	newContext := func() ocm.Context {
+		octx := ocm.New()
+		require.NoError(t, rootcertsattr.Get(octx).RegisterRootCertificates(registry.Certificate()))
+
+		return octx
+	}
+
+	component := "github.com/acme/component"
+	dependency := "github.com/acme/dependency"
+	version := "v0.0.1"
+	repo, err := newContext().RepositoryForSpec(ocireg.NewRepositorySpec(host+"/source", nil))
+	require.NoError(t, err)
+	type resource struct {
+		name, version, image string
+	}
+	publish := func(name string, resources []resource, references ...compdesc.ComponentReference) {
+		comp, err := repo.LookupComponent(name)
+		require.NoError(t, err)
+		cv, err := comp.NewVersion(version)
+		require.NoError(t, err)
+		cv.GetDescriptor().Provider.Name = "acme"
+		cv.GetDescriptor().References = references
+		for _, res := range resources {
+			meta := compdesc.NewResourceMeta(res.name, resourcetypes.OCI_IMAGE, ocmmetav1.ExternalRelation)
+			meta.Version = res.version
+			require.NoError(t, cv.SetResource(meta, ociartifact.New(res.image)))
+		}
+		require.NoError(t, comp.AddVersion(cv))
+		require.NoError(t, cv.Close())
+		require.NoError(t, comp.Close())
+	}
+	// both components have an app resource.
+	publish(dependency, []resource{
+		{"app", "v1.0.0", dependencyRef.Context().Digest(dependencyDigest.String()).String()},
+	})
+	publish(component, []resource{
+		{"app", "v1.0.0", appRef.Context().Digest(indexDigest.String()).String()},
+		{"sidecar", "v2.0.0+build.1", sidecarRef.Context().Digest(sidecarDigest.String()).String()},
+	}, compdesc.ComponentReference{
+		ElementMeta:   compdesc.ElementMeta{Name: "dependency", Version: version},
+		ComponentName: dependency,
+	})
+	require.NoError(t, repo.Close())
+
+	testCases := []struct {
+		name      string
+		mirror    string
+		platforms []string
+		app       ggcrv1.Hash
+	}{
+		{
+			name:   "images are tagged with the resource name and version",
+			mirror: "mirror",
+			app:    indexDigest,
+		},
+		{
+			name:      "image indexes are trimmed to the selected platforms",
+			mirror:    "mirror-arm64",
+			platforms: []string{"linux/arm64"},
+			app:       arm64Digest,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &v1alpha1.ComponentSubscription{
+				ObjectMeta: metav1.ObjectMeta{Name: "subscription", Namespace: "default", UID: "uid"},
+				Spec: v1alpha1.ComponentSubscriptionSpec{
+					Component: component,
+					Source:    v1alpha1.OCMRepository{URL: host + "/source"},
+					Transfer:  &v1alpha1.TransferSpec{Platforms: tt.platforms},
+					MirrorImages: &v1alpha1.ImageMirror{
+						Destination:   v1alpha1.OCMRepository{URL: host + "/" + tt.mirror},
+						ConfigMapName: "images",
+					},
+					VerifyImages: &v1alpha1.ImageVerification{
+						TrustStores:    []corev1.LocalObjectReference{{Name: trustStore.Name}},
+						CopySignatures: true,
+					},
+				},
+			}
+
+			octx := newContext()
+			fakeKubeClient := env.FakeKubeClient(WithObjects(trustStore))
+			ocmClient := NewClient(fakeKubeClient)
+			source, err := ocmClient.GetComponentVersion(context.Background(), octx, obj, version)
+			require.NoError(t, err)
+			defer source.Close()
+
+			require.NoError(t, ocmClient.MirrorImages(context.Background(), octx, obj, source))
+
+			mirror := host + "/" + tt.mirror
+			expected := map[string]struct {
+				key    string
+				image  string
+				digest ggcrv1.Hash
+				source ggcrv1.Hash
+			}{
+				component + ":" + version + "/app": {
+					"github.com_acme_component_app", mirror + "/" + component + "/app:v1.0.0", tt.app, indexDigest,
+				},
+				component + ":" + version + "/sidecar": {
+					"github.com_acme_component_sidecar", mirror + "/" + component + "/sidecar:v2.0.0.build-build.1", sidecarDigest, sidecarDigest,
+				},
+				dependency + ":" + version + "/app": {
+					"github.com_acme_dependency_app", mirror + "/" + dependency + "/app:v1.0.0", dependencyDigest, dependencyDigest,
+				},
+			}
+
+			require.Len(t, obj.Status.MirroredImages, 3)
+			cm := &corev1.ConfigMap{}
+			require.NoError(t, fakeKubeClient.Get(context.Background(), types.NamespacedName{Name: "images", Namespace: "default"}, cm))
+			require.Len(t, cm.OwnerReferences, 1)
+			assert.Equal(t, "subscription", cm.OwnerReferences[0].Name)
+			assert.Len(t, cm.Data, 3)
+
+			verifier, err := notation.NewVerifier(notation.TrustStores{trustStore.Name: {ca}}, nil)
+			require.NoError(t, err)
+
+			for _, mirrored := range obj.Status.MirroredImages {
+				want, ok := expected[mirrored.Component+"/"+mirrored.Resource]
+				require.True(t, ok, mirrored.Component+"/"+mirrored.Resource)
+				assert.Equal(t, want.image, mirrored.Image)
+				assert.Equal(t, want.digest.String(), mirrored.Digest)
+				assert.Equal(t, want.image+"@"+want.digest.String(), cm.Data[want.key])
+
+				image, err := resolveDigest(want.image, transport)
+				require.NoError(t, err)
+				assert.Equal(t, want.digest.String(), image.DigestStr())
+
+				_, err = verifier.Verify(context.Background(), image, transport)
+				if want.digest != want.source {
+					assert.ErrorIs(t, err, notation.ErrNoSignature, "trimmed images must not carry the source signatures")
+				} else {
+					assert.NoError(t, err, "signatures must be copied to the mirrored image")
+				}
+			}
+		})
+	}
+}
+
+func TestClient_RepositoryTLS(t *testing.T) {
+	newRegistry := func(tls bool) (*httptest.Server, string) {
+		app := handlers.NewApp(context.Background(), &configuration.Configuration{
+			Storage: configuration.Storage{"inmemory": configuration.Parameters{}},
+		})
+		if tls {
+			server := httptest.NewTLSServer(app)
+			return server, strings.TrimPrefix(server.URL, "https://")
+		}
+
+		server := httptest.NewServer(app)
+		return server, strings.TrimPrefix(server.URL, "http://")
+	}
+
+	plain, plainHost := newRegistry(false)
+	defer plain.Close()
+	secure, secureHost := newRegistry(true)
+	defer secure.Close()
+
+	clientCert, clientKey := newClientCertificate(t)
+	clientCAs := x509.NewCertPool()
+	require.True(t, clientCAs.AppendCertsFromPEM(clientCert))
+	mtls := httptest.NewUnstartedServer(handlers.NewApp(context.Background(), &configuration.Configuration{
+		Storage: configuration.Storage{"inmemory": configuration.Parameters{}},
+	}))
+	mtls.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs, MinVersion: tls.VersionTLS12}
+	mtls.StartTLS()
+	defer mtls.Close()
+	mtlsHost := strings.TrimPrefix(mtls.URL, "https://")
+
+	img, err := random.Image(64, 1)
+	require.NoError(t, err)
+	imgDigest, err := img.Digest()
+	require.NoError(t, err)
+	imgRef, err := name.ParseReference(plainHost+"/images/app:latest", name.Insecure)
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(imgRef, img))
+
+	component := "github.com/acme/component"
+	version := "v0.0.1"
+	octx := ocm.New()
+	require.NoError(t, rootcertsattr.Get(octx).RegisterRootCertificates(secure.Certificate()))
+	clientPair, err := tls.X509KeyPair(clientCert, clientKey)
+	require.NoError(t, err)
+	clientTransport := mtls.Client().Transport.(*http.Transport).Clone()
+	clientTransport.TLSClientConfig.Certificates = []tls.Certificate{clientPair}
+	mtlsSpec := ocireg.NewRepositorySpec(mtlsHost+"/source", nil)
+	mtlsSpec.RepositorySpec = ocitransport.NewRepositorySpec(mtlsHost, clientTransport)
+	for _, spec := range []ocm.RepositorySpec{
+		ocireg.NewRepositorySpec("http://"+plainHost+"/source", nil),
+		ocireg.NewRepositorySpec(secureHost+"/source", nil),
+		mtlsSpec,
+	} {
+		repo, err := octx.RepositoryForSpec(spec)
+		require.NoError(t, err)
+		comp, err := repo.LookupComponent(component)
+		require.NoError(t, err)
+		cv, err := comp.NewVersion(version)
+		require.NoError(t, err)
+		cv.GetDescriptor().Provider.Name = "acme"
+		meta := compdesc.NewResourceMeta("app", resourcetypes.OCI_IMAGE, ocmmetav1.ExternalRelation)
+		meta.Version = "v1.0.0"
+		require.NoError(t, cv.SetResource(meta, ociartifact.New(imgRef.Context().Digest(imgDigest.String()).String())))
+		require.NoError(t, comp.AddVersion(cv))
+		require.NoError(t, cv.Close())
+		require.NoError(t, comp.Close())
+		require.NoError(t, repo.Close())
+	}
+
+	caSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry-ca", Namespace: "default"},
+		Data: map[string][]byte{
+			caCertKey: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: secure.Certificate().Raw}),
+		},
+	}
+	clientSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "client-certificate", Namespace: "default"},
+		Data: map[string][]byte{
+			caCertKey:               caSecret.Data[caCertKey],
+			corev1.TLSCertKey:       clientCert,
+			corev1.TLSPrivateKeyKey: clientKey,
+		},
+	}
+	incompleteSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "client-cert", Namespace: "default"},
+		Data: map[string][]byte{
+			corev1.TLSCertKey: caSecret.Data[caCertKey],
+		},
+	}
+
+	testCases := []struct {
+		name        string
+		source      v1alpha1.OCMRepository
+		destination v1alpha1.OCMRepository
+		err         string
+	}{
+		{
+			name:        "plain HTTP source and CA bundle for the mirror",
+			source:      v1alpha1.OCMRepository{URL: plainHost + "/source", PlainHTTP: true},
+			destination: v1alpha1.OCMRepository{URL: secureHost + "/mirror-ca", CertSecretRef: &corev1.SecretReference{Name: "registry-ca"}},
+		},
+		{
+			name:        "CA bundle for the source",
+			source:      v1alpha1.OCMRepository{URL: secureHost + "/source", CertSecretRef: &corev1.SecretReference{Name: "registry-ca"}},
+			destination: v1alpha1.OCMRepository{URL: secureHost + "/mirror-source-ca", CertSecretRef: &corev1.SecretReference{Name: "registry-ca"}},
+		},
+		{
+			name:        "untrusted source",
+			source:      v1alpha1.OCMRepository{URL: secureHost + "/source"},
+			destination: v1alpha1.OCMRepository{URL: secureHost + "/mirror", Insecure: true},
+			err:         "x509: certificate signed by unknown authority",
+		},
+		{
+			name:        "insecure mirror",
+			source:      v1alpha1.OCMRepository{URL: plainHost + "/source", PlainHTTP: true},
+			destination: v1alpha1.OCMRepository{URL: secureHost + "/mirror-insecure", Insecure: true},
+		},
+		{
+			name:        "untrusted mirror",
+			source:      v1alpha1.OCMRepository{URL: plainHost + "/source", PlainHTTP: true},
+			destination: v1alpha1.OCMRepository{URL: secureHost + "/mirror-untrusted"},
+			err:         "x509: certificate signed by unknown authority",
+		},
+		{
+			name:        "source requires plain HTTP",
+			source:      v1alpha1.OCMRepository{URL: plainHost + "/source"},
+			destination: v1alpha1.OCMRepository{URL: secureHost + "/mirror", Insecure: true},
+			err:         "http: server gave HTTP response to HTTPS client",
+		},
+		{
+			name:        "insecure source",
+			source:      v1alpha1.OCMRepository{URL: secureHost + "/source", Insecure: true},
+			destination: v1alpha1.OCMRepository{URL: secureHost + "/mirror-insecure-source", Insecure: true},
+		},
+		{
+			name:        "client certificate without key",
+			source:      v1alpha1.OCMRepository{URL: plainHost + "/source", PlainHTTP: true},
+			destination: v1alpha1.OCMRepository{URL: secureHost + "/mirror", CertSecretRef: &corev1.SecretReference{Name: "client-cert"}},
+			err:         "certificate secret default/client-cert must contain both tls.crt and tls.key",
+		},
+		{
+			name:        "client certificate for the source",
+			source:      v1alpha1.OCMRepository{URL: mtlsHost + "/source", CertSecretRef: &corev1.SecretReference{Name: "client-certificate"}},
+			destination: v1alpha1.OCMRepository{URL: secureHost + "/mirror-client-certificate", Insecure: true},
+		},
+		{
+			name:        "source requires a client certificate",
+			source:      v1alpha1.OCMRepository{URL: mtlsHost + "/source", CertSecretRef: &corev1.SecretReference{Name: "registry-ca"}},
+			destination: v1alpha1.OCMRepository{URL: secureHost + "/mirror", Insecure: true},
+			err:         "certificate required",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &v1alpha1.ComponentSubscription{
+				ObjectMeta: metav1.ObjectMeta{Name: "subscription", Namespace: "default", UID: "uid"},
+				Spec: v1alpha1.ComponentSubscriptionSpec{
+					Component:    component,
+					Source:       tt.source,
+					MirrorImages: &v1alpha1.ImageMirror{Destination: tt.destination},
+				},
+			}
+
+			ocmClient := NewClient(env.FakeKubeClient(WithObjects(caSecret, clientSecret, incompleteSecret)))
+			err := func() error {
+				octx, err := ocmClient.CreateAuthenticatedOCMContext(context.Background(), obj)
+				if err != nil {
+					return err
+				}
+
+				source, err := ocmClient.GetComponentVersion(context.Background(), octx, obj, version)
+				if err != nil {
+					return err
+				}
+				defer source.Close()
+
+				return ocmClient.MirrorImages(context.Background(), octx, obj, source)
+			}()
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+
+				return
+			}
+			require.NoError(t, err)
+
+			require.Len(t, obj.Status.MirroredImages, 1)
+			assert.Equal(t, tt.destination.URL+"/"+component+"/app:v1.0.0", obj.Status.MirroredImages[0].Image)
+			assert.Equal(t, imgDigest.String(), obj.Status.MirroredImages[0].Digest)
+		})
+	}
+}
+
+func TestClient_RepositoryProxy(t *testing.T) {
+	newRegistry := func() (*httptest.Server, string) {
+		app := handlers.NewApp(context.Background(), &configuration.Configuration{
+			Storage: configuration.Storage{"inmemory": configuration.Parameters{}},
+		})
+		server := httptest.NewServer(app)
+		return server, strings.TrimPrefix(server.URL, "http://")
+	}
+
+	source, sourceHost := newRegistry()
+	defer source.Close()
+	mirror, mirrorHost := newRegistry()
+	defer mirror.Close()
+
+	var proxied []string
+	forward := &httputil.ReverseProxy{Director: func(*http.Request) {}}
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := parseProxyAuthorization(r); !ok || user != "proxy-user" || pass != "proxy-pass" {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+
+			return
+		}
+
+		proxied = append(proxied, r.URL.Host)
+		forward.ServeHTTP(w, r)
+	}))
+	defer proxy.Close()
+
+	img, err := random.Image(64, 1)
+	require.NoError(t, err)
+	imgDigest, err := img.Digest()
+	require.NoError(t, err)
+	imgRef, err := name.ParseReference(sourceHost+"/images/app:latest", name.Insecure)
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(imgRef, img))
+
+	component := "github.com/acme/component"
+	version := "v0.0.1"
+	octx := ocm.New()
+	repo, err := octx.RepositoryForSpec(ocireg.NewRepositorySpec("http://"+sourceHost+"/source", nil))
+	require.NoError(t, err)
+	comp, err := repo.LookupComponent(component)
+	require.NoError(t, err)
+	cv, err := comp.NewVersion(version)
+	require.NoError(t, err)
+	cv.GetDescriptor().Provider.Name = "acme"
+	meta := compdesc.NewResourceMeta("app", resourcetypes.OCI_IMAGE, ocmmetav1.ExternalRelation)
+	meta.Version = "v1.0.0"
+	require.NoError(t, cv.SetResource(meta, ociartifact.New(imgRef.Context().Digest(imgDigest.String()).String())))
+	require.NoError(t, comp.AddVersion(cv))
+	require.NoError(t, cv.Close())
+	require.NoError(t, comp.Close())
+	require.NoError(t, repo.Close())
+
+	proxySecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "proxy-credentials", Namespace: "default"},
+		Data: map[string][]byte{
+			"username": []byte("proxy-user"),
+			"password": []byte("proxy-pass"),
+		},
+	}
+
+	testCases := []struct {
+		name        string
+		source      v1alpha1.OCMRepository
+		destination v1alpha1.OCMRepository
+		proxied     []string
+		err         string
+	}{
+		{
+			name:   "mirror through an authenticated proxy",
+			source: v1alpha1.OCMRepository{URL: sourceHost + "/source", PlainHTTP: true},
+			destination: v1alpha1.OCMRepository{
+				URL:       mirrorHost + "/mirror",
+				PlainHTTP: true,
+				Proxy: &v1alpha1.ProxyConfig{
+					URL:       proxy.URL,
+					SecretRef: &corev1.LocalObjectReference{Name: "proxy-credentials"},
+				},
+			},
+			proxied: []string{mirrorHost},
+		},
+		{
+			name:   "proxy without credentials",
+			source: v1alpha1.OCMRepository{URL: sourceHost + "/source", PlainHTTP: true},
+			destination: v1alpha1.OCMRepository{
+				URL:       mirrorHost + "/mirror-anonymous",
+				PlainHTTP: true,
+				Proxy:     &v1alpha1.ProxyConfig{URL: proxy.URL},
+			},
+			err: "unexpected status code 407",
+		},
+		{
+			name:   "invalid proxy URL",
+			source: v1alpha1.OCMRepository{URL: sourceHost + "/source", PlainHTTP: true},
+			destination: v1alpha1.OCMRepository{
+				URL:       mirrorHost + "/mirror",
+				PlainHTTP: true,
+				Proxy:     &v1alpha1.ProxyConfig{URL: "proxy.local"},
+			},
+			err: `invalid proxy URL "proxy.local" for ` + mirrorHost + "/mirror",
+		},
+		{
+			name: "proxy for the source",
+			source: v1alpha1.OCMRepository{
+				URL:       sourceHost + "/source",
+				PlainHTTP: true,
+				Proxy: &v1alpha1.ProxyConfig{
+					URL:       proxy.URL,
+					SecretRef: &corev1.LocalObjectReference{Name: "proxy-credentials"},
+				},
+			},
+			destination: v1alpha1.OCMRepository{URL: mirrorHost + "/mirror-source-proxy", PlainHTTP: true},
+			proxied:     []string{sourceHost},
+		},
+		{
+			name: "source proxy without credentials",
+			source: v1alpha1.OCMRepository{
+				URL:       sourceHost + "/source",
+				PlainHTTP: true,
+				Proxy:     &v1alpha1.ProxyConfig{URL: proxy.URL},
+			},
+			destination: v1alpha1.OCMRepository{URL: mirrorHost + "/mirror", PlainHTTP: true},
+			err:         "407",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			proxied = nil
+			obj := &v1alpha1.ComponentSubscription{
+				ObjectMeta: metav1.ObjectMeta{Name: "subscription", Namespace: "default", UID: "uid"},
+				Spec: v1alpha1.ComponentSubscriptionSpec{
+					Component:    component,
+					Source:       tt.source,
+					MirrorImages: &v1alpha1.ImageMirror{Destination: tt.destination},
+				},
+			}
+
+			ocmClient := NewClient(env.FakeKubeClient(WithObjects(proxySecret)))
+			err := func() error {
+				octx, err := ocmClient.CreateAuthenticatedOCMContext(context.Background(), obj)
+				if err != nil {
+					return err
+				}
+
+				source, err := ocmClient.GetComponentVersion(context.Background(), octx, obj, version)
+				if err != nil {
+					return err
+				}
+				defer source.Close()
+
+				return ocmClient.MirrorImages(context.Background(), octx, obj, source)
+			}()
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+
+				return
+			}
+			require.NoError(t, err)
+
+			require.Len(t, obj.Status.MirroredImages, 1)
+			assert.Equal(t, imgDigest.String(), obj.Status.MirroredImages[0].Digest)
+			assert.NotEmpty(t, proxied)
+			for _, host := range proxied {
+				assert.Contains(t, tt.proxied, host)
+			}
+		})
+	}
+}
+
+func TestClient_TransferComponentThroughProxy(t *testing.T) {
+	newRegistry := func() (*httptest.Server, string) {
+		app := handlers.NewApp(context.Background(), &configuration.Configuration{
+			Storage: configuration.Storage{"inmemory": configuration.Parameters{}},
+		})
+		server := httptest.NewServer(app)
+		return server, strings.TrimPrefix(server.URL, "http://")
+	}
+
+	source, sourceHost := newRegistry()
+	defer source.Close()
+	destination, destinationHost := newRegistry()
+	defer destination.Close()
+
+	var proxied []string
+	forward := &httputil.ReverseProxy{Director: func(*http.Request) {}}
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.Host)
+		forward.ServeHTTP(w, r)
+	}))
+	defer proxy.Close()
+
+	component := "github.com/acme/component"
+	version := "v0.0.1"
+	octx := ocm.New()
+	repo, err := octx.RepositoryForSpec(ocireg.NewRepositorySpec("http://"+sourceHost+"/source", nil))
+	require.NoError(t, err)
+	comp, err := repo.LookupComponent(component)
+	require.NoError(t, err)
+	cv, err := comp.NewVersion(version)
+	require.NoError(t, err)
+	cv.GetDescriptor().Provider.Name = "acme"
+	require.NoError(t, cv.SetResourceBlob(
+		compdesc.NewResourceMeta("data", resourcetypes.PLAIN_TEXT, ocmmetav1.LocalRelation),
+		accessio.BlobAccessForString(mime.MIME_TEXT, "data"),
+		"",
+		nil,
+	))
+	require.NoError(t, comp.AddVersion(cv))
+	require.NoError(t, cv.Close())
+	require.NoError(t, comp.Close())
+	require.NoError(t, repo.Close())
+
+	obj := &v1alpha1.ComponentSubscription{
+		ObjectMeta: metav1.ObjectMeta{Name: "subscription", Namespace: "default"},
+		Spec: v1alpha1.ComponentSubscriptionSpec{
+			Component: component,
+			Source: v1alpha1.OCMRepository{
+				URL:       sourceHost + "/source",
+				PlainHTTP: true,
+				Proxy:     &v1alpha1.ProxyConfig{URL: proxy.URL},
+			},
+			Destination: &v1alpha1.OCMRepository{URL: destinationHost + "/destination", PlainHTTP: true},
+		},
+	}
+
+	ocmClient := NewClient(env.FakeKubeClient())
+	authenticated, err := ocmClient.CreateAuthenticatedOCMContext(context.Background(), obj)
+	require.NoError(t, err)
+
+	cv, err = ocmClient.GetComponentVersion(context.Background(), authenticated, obj, version)
+	require.NoError(t, err)
+	defer cv.Close()
+
+	require.NoError(t, ocmClient.TransferComponent(context.Background(), authenticated, obj, cv))
+
+	transferred, err := ocmClient.GetDestinationComponentVersion(context.Background(), authenticated, obj, version)
+	require.NoError(t, err)
+	defer transferred.Close()
+	assert.Len(t, transferred.GetResources(), 1)
+
+	assert.NotEmpty(t, proxied)
+	for _, host := range proxied {
+		assert.Equal(t, sourceHost, host, "only requests to the source must be sent through its proxy")
+	}
+}
+
+// newClientCertificate returns a self-signed PEM encoded client certificate and its key.
+func newClientCertificate(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+
+	key, err := gorsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "replication-controller"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+// parseProxyAuthorization returns the basic auth credentials of the Proxy-Authorization header.
+func parseProxyAuthorization(r *http.Request) (string, string, bool) {
+	req := &http.Request{Header: http.Header{"Authorization": r.Header.Values("Proxy-Authorization")}}
+
+	return req.BasicAuth()
+}
+
+func TestProxyFor(t *testing.T) {
+	mustParse := func(raw string) *url.URL {
+		u, err := url.Parse(raw)
+		require.NoError(t, err)
+
+		return u
+	}
+
+	proxies := []registryProxy{
+		{host: "ghcr.io", path: "acme/mirror", proxy: mustParse("http://mirror-proxy:3128")},
+		{host: "ghcr.io", path: "acme/mirror/nested", proxy: mustParse("http://nested-proxy:3128")},
+		{host: "ghcr.io", path: "acme/images", proxy: mustParse("http://images-proxy:3128")},
+	}
+
+	testCases := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{
+			name:     "repository",
+			url:      "https://ghcr.io/v2/acme/images/app/manifests/v1.0.0",
+			expected: "http://images-proxy:3128",
+		},
+		{
+			name:     "nested repository",
+			url:      "https://ghcr.io/v2/acme/mirror/nested/app/blobs/uploads/",
+			expected: "http://nested-proxy:3128",
+		},
+		{
+			name:     "other repository of the host",
+			url:      "https://ghcr.io/v2/acme/mirror-other/app/manifests/v1.0.0",
+			expected: "http://mirror-proxy:3128",
+		},
+		{
+			name:     "api version check",
+			url:      "https://ghcr.io/v2/",
+			expected: "http://mirror-proxy:3128",
+		},
+		{
+			name: "other host",
+			url:  "https://quay.io/v2/acme/images/app/manifests/v1.0.0",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			proxy := proxyFor(proxies, mustParse(tt.url))
+			if tt.expected == "" {
+				assert.Nil(t, proxy)
+
+				return
+			}
+
+			require.NotNil(t, proxy)
+			assert.Equal(t, tt.expected, proxy.String())
+		})
+	}
+}
+
+func TestClient_CrossNamespaceSecretReferences(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry-credentials", Namespace: "shared"},
+		Data: map[string][]byte{
+			"username": []byte("user"),
+			"password": []byte("pass"),
+		},
+	}
+	grant := func(from string, secretNames ...string) *v1alpha1.SecretReferenceGrant {
+		return &v1alpha1.SecretReferenceGrant{
+			ObjectMeta: metav1.ObjectMeta{Name: "grant-" + from, Namespace: "shared"},
+			Spec: v1alpha1.SecretReferenceGrantSpec{
+				From:        []string{from},
+				SecretNames: secretNames,
+			},
+		}
+	}
+
+	testCases := []struct {
+		name    string
+		objects []client.Object
+		opts    []ClientOption
+		err     string
+	}{
+		{
+			name:    "granted to the namespace",
+			objects: []client.Object{grant("default")},
+		},
+		{
+			name:    "granted to the namespace for the secret",
+			objects: []client.Object{grant("default", "registry-credentials")},
+		},
+		{
+			name: "no grant",
+			err:  "failed to configure credentials for source: secret shared/registry-credentials is not granted to namespace default",
+		},
+		{
+			name:    "granted to another namespace",
+			objects: []client.Object{grant("other")},
+			err:     "failed to configure credentials for source: secret shared/registry-credentials is not granted to namespace default",
+		},
+		{
+			name:    "granted for another secret",
+			objects: []client.Object{grant("default", "other-credentials")},
+			err:     "failed to configure credentials for source: secret shared/registry-credentials is not granted to namespace default",
+		},
+		{
+			name:    "cross-namespace references disabled",
+			objects: []client.Object{grant("default")},
+			opts:    []ClientOption{WithoutCrossNamespaceReferences()},
+			err:     "failed to configure credentials for source: cross-namespace reference to secret shared/registry-credentials is not allowed",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := &v1alpha1.ComponentSubscription{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-name", Namespace: "default"},
+				Spec: v1alpha1.ComponentSubscriptionSpec{
+					Component: "github.com/acme/component",
+					Source: v1alpha1.OCMRepository{
+						URL:       "ghcr.io/acme",
+						SecretRef: &corev1.SecretReference{Name: "registry-credentials", Namespace: "shared"},
+					},
+				},
+			}
+
+			ocmClient := NewClient(env.FakeKubeClient(WithObjects(append(tt.objects, secret)...)), tt.opts...)
+			octx, err := ocmClient.CreateAuthenticatedOCMContext(context.Background(), cs)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+
+				return
+			}
+			require.NoError(t, err)
+
+			id := cpi.ConsumerIdentity{
+				cpi.ID_TYPE:            identity.CONSUMER_TYPE,
+				identity.ID_HOSTNAME:   "ghcr.io",
+				identity.ID_PATHPREFIX: "acme",
+			}
+			creds, err := octx.CredentialsContext().GetCredentialsForConsumer(id)
+			require.NoError(t, err)
+			consumer, err := creds.Credentials(octx.CredentialsContext())
+			require.NoError(t, err)
+			assert.Equal(t, "user", consumer.Properties()["username"])
+			assert.Equal(t, "pass", consumer.Properties()["password"])
+		})
+	}
+}
+
+func TestClient_VerifyComponentCrossNamespaceKeys(t *testing.T) {
+	publicKey, err := os.ReadFile(filepath.Join("testdata", "public1_key.pem"))
+	require.NoError(t, err)
+	privateKey, err := os.ReadFile(filepath.Join("testdata", "private_key.pem"))
+	require.NoError(t, err)
+
+	component := "github.com/open-component-model/ocm-demo-index"
+	octx := ocmcontext.NewFakeOCMContext()
+	c := &ocmcontext.Component{
+		Name:    component,
+		Version: "v0.0.1",
+		Sign: &ocmcontext.Sign{
+			Name:    Signature,
+			PrivKey: privateKey,
+			PubKey:  publicKey,
+			Digest:  "3d879ecdea45acb7f8d85b89fd653288d84af4476eac4141822142ec59c13745",
+		},
+	}
+	require.NoError(t, octx.AddComponent(c))
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "sign-secret", Namespace: "keys"},
+		Data: map[string][]byte{
+			Signature: publicKey,
+		},
+	}
+	grant := &v1alpha1.SecretReferenceGrant{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenants", Namespace: "keys"},
+		Spec:       v1alpha1.SecretReferenceGrantSpec{From: []string{"tenant"}},
+	}
+	subscription := func(namespace string) *v1alpha1.ComponentSubscription {
+		return &v1alpha1.ComponentSubscription{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-name", Namespace: namespace},
+			Spec: v1alpha1.ComponentSubscriptionSpec{
+				Component:             component,
+				Source:                v1alpha1.OCMRepository{URL: "localhost"},
+				VerifySecretNamespace: "keys",
+				Verify: []ocmv1alpha1.Signature{
+					{
+						Name: Signature,
+						PublicKey: ocmv1alpha1.PublicKey{
+							SecretRef: &corev1.LocalObjectReference{Name: "sign-secret"},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	ocmClient := NewClient(env.FakeKubeClient(WithObjects(secret, grant)))
+
+	verified, err := ocmClient.VerifyComponent(context.Background(), subscription("tenant"), c)
+	require.NoError(t, err)
+	assert.True(t, verified)
+
+	_, err = ocmClient.VerifyComponent(context.Background(), subscription("default"), c)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "secret keys/sign-secret is not granted to namespace default")
+}
+
+func TestClient_CreateAuthenticatedOCMContextWithCredentialHelper(t *testing.T) {
+	helper, err := filepath.Abs(filepath.Join("testdata", "docker-credential-fake"))
+	require.NoError(t, err)
+
+	helperEnv := []v1alpha1.ExecEnvVar{
+		{Name: "FAKE_USERNAME", Value: "helper-user"},
+		{Name: "FAKE_SECRET", Value: "short-lived"},
+	}
+
+	testCases := []struct {
+		name       string
+		repository v1alpha1.OCMRepository
+		expected   map[string]string
+		err        string
+	}{
+		{
+			name: "username and password",
+			repository: v1alpha1.OCMRepository{
+				URL:         "ghcr.io/acme",
+				Credentials: &v1alpha1.RepositoryCredentials{Exec: &v1alpha1.ExecCredentials{Command: helper, Env: helperEnv}},
+			},
+			expected: map[string]string{"username": "helper-user", "password": "short-lived"},
+		},
+		{
+			name: "identity token",
+			repository: v1alpha1.OCMRepository{
+				URL: "ghcr.io/acme",
+				Credentials: &v1alpha1.RepositoryCredentials{
+					Exec: &v1alpha1.ExecCredentials{Command: helper, Args: []string{"--token"}, Env: helperEnv},
+				},
+			},
+			expected: map[string]string{"identityToken": "short-lived"},
+		},
+		{
+			name: "helper not allowed",
+			repository: v1alpha1.OCMRepository{
+				URL:         "ghcr.io/acme",
+				Credentials: &v1alpha1.RepositoryCredentials{Exec: &v1alpha1.ExecCredentials{Command: "/bin/sh"}},
+			},
+			err: "failed to configure credentials for source: failed to get credentials for ghcr.io: credential helper /bin/sh is not allowed",
+		},
+		{
+			name: "helper fails",
+			repository: v1alpha1.OCMRepository{
+				URL: "ghcr.io/acme",
+				Credentials: &v1alpha1.RepositoryCredentials{
+					Exec: &v1alpha1.ExecCredentials{Command: helper, Env: []v1alpha1.ExecEnvVar{{Name: "FAKE_ERROR", Value: "token expired"}}},
+				},
+			},
+			err: "token expired",
+		},
+		{
+			name: "secret and credential helper",
+			repository: v1alpha1.OCMRepository{
+				URL:         "ghcr.io/acme",
+				SecretRef:   &corev1.SecretReference{Name: "registry-credentials"},
+				Credentials: &v1alpha1.RepositoryCredentials{Exec: &v1alpha1.ExecCredentials{Command: helper, Env: helperEnv}},
+			},
+			err: "failed to configure credentials for source: secretRef and credentials are mutually exclusive for ghcr.io/acme",
+		},
+		{
+			name: "environment variable not allowed",
+			repository: v1alpha1.OCMRepository{
+				URL: "ghcr.io/acme",
+				Credentials: &v1alpha1.RepositoryCredentials{
+					Exec: &v1alpha1.ExecCredentials{Command: helper, Env: []v1alpha1.ExecEnvVar{{Name: "LD_PRELOAD", Value: "/tmp/evil.so"}}},
+				},
+			},
+			err: "environment variable LD_PRELOAD of credential helper " + helper + " is not allowed",
+		},
+		{
+			name: "positional argument",
+			repository: v1alpha1.OCMRepository{
+				URL: "ghcr.io/acme",
+				Credentials: &v1alpha1.RepositoryCredentials{
+					Exec: &v1alpha1.ExecCredentials{Command: helper, Args: []string{"erase"}, Env: helperEnv},
+				},
+			},
+			err: `argument "erase" of credential helper ` + helper + " is not an option",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := &v1alpha1.ComponentSubscription{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-name", Namespace: "default"},
+				Spec: v1alpha1.ComponentSubscriptionSpec{
+					Component: "github.com/acme/component",
+					Source:    tt.repository,
+				},
+			}
+
+			ocmClient := NewClient(env.FakeKubeClient(),
+				WithCredentialHelpers(helper),
+				WithCredentialHelperEnv("FAKE_USERNAME", "FAKE_SECRET", "FAKE_ERROR"),
+			)
+			octx, err := ocmClient.CreateAuthenticatedOCMContext(context.Background(), cs)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+
+				return
+			}
+			require.NoError(t, err)
+
+			id := cpi.ConsumerIdentity{
+				cpi.ID_TYPE:            identity.CONSUMER_TYPE,
+				identity.ID_HOSTNAME:   "ghcr.io",
+				identity.ID_PATHPREFIX: "acme",
+			}
+			creds, err := octx.CredentialsContext().GetCredentialsForConsumer(id)
+			require.NoError(t, err)
+			consumer, err := creds.Credentials(octx.CredentialsContext())
+			require.NoError(t, err)
+			for key, value := range tt.expected {
+				assert.Equal(t, value, consumer.Properties()[key])
+			}
+		})
+	}
+}
+
+func TestCredentialHelpersCache(t *testing.T) {
+	command, err := filepath.Abs(filepath.Join("testdata", "docker-credential-fake"))
+	require.NoError(t, err)
+
+	calls := filepath.Join(t.TempDir(), "calls")
+	helper := &v1alpha1.ExecCredentials{
+		Command: command,
+		Env: []v1alpha1.ExecEnvVar{
+			{Name: "FAKE_USERNAME", Value: "helper-user"},
+			{Name: "FAKE_SECRET", Value: "short-lived"},
+			{Name: "FAKE_CALLS", Value: calls},
+		},
+	}
+	countCalls := func() int {
+		data, err := os.ReadFile(calls)
+		require.NoError(t, err)
+
+		return strings.Count(string(data), "\n")
+	}
+
+	now := time.Now()
+	helpers := newCredentialHelpers()
+	helpers.allowed = []string{command}
+	helpers.allowedEnv = []string{"FAKE_USERNAME", "FAKE_SECRET", "FAKE_CALLS", "FAKE_EXPIRES_AT"}
+	helpers.now = func() time.Time { return now }
+
+	properties, err := helpers.get(context.Background(), helper, "ghcr.io")
+	require.NoError(t, err)
+	assert.Equal(t, "short-lived", properties["password"])
+
+	_, err = helpers.get(context.Background(), helper, "ghcr.io")
+	require.NoError(t, err)
+	assert.Equal(t, 1, countCalls(), "credentials should be cached")
+
+	_, err = helpers.get(context.Background(), helper, "quay.io")
+	require.NoError(t, err)
+	assert.Equal(t, 2, countCalls(), "credentials should be cached per host")
+
+	now = now.Add(defaultCredentialHelperTTL)
+	_, err = helpers.get(context.Background(), helper, "ghcr.io")
+	require.NoError(t, err)
+	assert.Equal(t, 3, countCalls(), "expired credentials should be renewed")
+
+	expiring := helper.DeepCopy()
+	expiring.Env = append(expiring.Env, v1alpha1.ExecEnvVar{Name: "FAKE_EXPIRES_AT", Value: now.Add(time.Minute).Format(time.RFC3339)})
+	_, err = helpers.get(context.Background(), expiring, "ghcr.io")
+	require.NoError(t, err)
+	now = now.Add(20 * time.Second)
+	_, err = helpers.get(context.Background(), expiring, "ghcr.io")
+	require.NoError(t, err)
+	assert.Equal(t, 4, countCalls(), "credentials should be cached until they expire")
+
+	now = now.Add(20 * time.Second)
+	_, err = helpers.get(context.Background(), expiring, "ghcr.io")
+	require.NoError(t, err)
+	assert.Equal(t, 5, countCalls(), "credentials should be renewed before they expire")
+
+	helpers.forget(v1alpha1.OCMRepository{URL: "ghcr.io/acme", Credentials: &v1alpha1.RepositoryCredentials{Exec: expiring}})
+	_, err = helpers.get(context.Background(), expiring, "ghcr.io")
+	require.NoError(t, err)
+	assert.Equal(t, 6, countCalls(), "forgotten credentials should be renewed")
+
+	now = now.Add(defaultCredentialHelperTTL)
+	_, err = helpers.get(context.Background(), helper, "ghcr.io")
+	require.NoError(t, err)
+	assert.Len(t, helpers.cache, 1, "expired credentials should be evicted")
+}
+
+func TestDockerConfig(t *testing.T) {
+	testCases := []struct {
+		name     string
+		secret   *corev1.Secret
+		expected string
+		err      string
+	}{
+		{
+			name: "dockerconfigjson without credential stores",
+			secret: &corev1.Secret{
+				Type: corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{
+					corev1.DockerConfigJsonKey: []byte(`{"auths":{"ghcr.io":{"auth":"dXNlcjpwYXNz"}},"credsStore":"desktop"}`),
+				},
+			},
+			expected: `{"auths":{"ghcr.io":{"auth":"dXNlcjpwYXNz"}}}`,
+		},
+		{
+			name: "legacy dockercfg",
+			secret: &corev1.Secret{
+				Type: corev1.SecretTypeDockercfg,
+				Data: map[string][]byte{
+					corev1.DockerConfigKey: []byte(`{"ghcr.io":{"auth":"dXNlcjpwYXNz"}}`),
+				},
+			},
+			expected: `{"auths":{"ghcr.io":{"auth":"dXNlcjpwYXNz"}}}`,
+		},
+		{
+			name: "basic auth",
+			secret: &corev1.Secret{
+				Type: corev1.SecretTypeBasicAuth,
+				Data: map[string][]byte{
+					corev1.BasicAuthUsernameKey: []byte("user"),
+					corev1.BasicAuthPasswordKey: []byte("pass"),
+				},
+			},
+			expected: `{"auths":{"registry.com":{"username":"user","password":"pass","auth":"dXNlcjpwYXNz"}}}`,
+		},
+		{
+			name: "token",
+			secret: &corev1.Secret{
+				Data: map[string][]byte{"token": []byte("registry-token")},
+			},
+			expected: `{"auths":{"registry.com":{"identitytoken":"registry-token"}}}`,
+		},
+		{
+			name: "identity token",
+			secret: &corev1.Secret{
+				Data: map[string][]byte{"identityToken": []byte("registry-token")},
+			},
+			expected: `{"auths":{"registry.com":{"identitytoken":"registry-token"}}}`,
+		},
+		{
+			name: "dockerconfigjson without key",
+			secret: &corev1.Secret{
+				Type: corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{"config.json": []byte(`{}`)},
+			},
+			err: `secret default/registry-credentials of type kubernetes.io/dockerconfigjson is missing the ".dockerconfigjson" key`,
+		},
+		{
+			name: "basic auth without password",
+			secret: &corev1.Secret{
+				Type: corev1.SecretTypeBasicAuth,
+				Data: map[string][]byte{corev1.BasicAuthUsernameKey: []byte("user")},
+			},
+			err: `secret default/registry-credentials of type kubernetes.io/basic-auth is missing the "password" key`,
+		},
+		{
+			name: "username without password",
+			secret: &corev1.Secret{
+				Data: map[string][]byte{"username": []byte("user")},
+			},
+			err: `secret default/registry-credentials is missing the "password" key`,
+		},
+		{
+			name: "invalid dockercfg",
+			secret: &corev1.Secret{
+				Type: corev1.SecretTypeDockercfg,
+				Data: map[string][]byte{corev1.DockerConfigKey: []byte(`[]`)},
+			},
+			err: "failed to parse .dockercfg in secret default/registry-credentials",
+		},
+		{
+			name: "no credentials",
+			secret: &corev1.Secret{
+				Data: map[string][]byte{"ca.crt": []byte("certificate")},
+			},
+			err: "secret default/registry-credentials contains no registry credentials, expected one of the keys " +
+				".dockerconfigjson, .dockercfg, username and password, identityToken or token",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.secret.ObjectMeta = metav1.ObjectMeta{Name: "registry-credentials", Namespace: "default"}
+
+			data, err := DockerConfig(tt.secret, v1alpha1.OCMRepository{URL: "http://registry.com/ocm", PlainHTTP: true})
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(data))
+		})
+	}
+}
+
+func TestClient_CreateAuthenticatedOCMContextSecretFormats(t *testing.T) {
+	basicAuth := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "basic-auth", Namespace: "default"},
+		Type:       corev1.SecretTypeBasicAuth,
+		Data: map[string][]byte{
+			corev1.BasicAuthUsernameKey: []byte("user"),
+			corev1.BasicAuthPasswordKey: []byte("pass"),
+		},
+	}
+	dockercfg := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "dockercfg", Namespace: "default"},
+		Type:       corev1.SecretTypeDockercfg,
+		Data: map[string][]byte{
+			corev1.DockerConfigKey: []byte(`{"ghcr.io":{"auth":"dXNlcjpwYXNz"}}`),
+		},
+	}
+	token := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: "default"},
+		Data: map[string][]byte{
+			"token": []byte("registry-token"),
+		},
+	}
+	account := func(secrets ...string) *corev1.ServiceAccount {
+		account := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "replication", Namespace: "default"}}
+		for _, secret := range secrets {
+			account.ImagePullSecrets = append(account.ImagePullSecrets, corev1.LocalObjectReference{Name: secret})
+		}
+
+		return account
+	}
+
+	testCases := []struct {
+		name     string
+		spec     v1alpha1.ComponentSubscriptionSpec
+		objects  []client.Object
+		expected map[string]string
+		err      string
+	}{
+		{
+			name: "basic auth secret ref",
+			spec: v1alpha1.ComponentSubscriptionSpec{
+				Source: v1alpha1.OCMRepository{URL: "ghcr.io/acme", SecretRef: &corev1.SecretReference{Name: "basic-auth"}},
+			},
+			expected: map[string]string{"username": "user", "password": "pass"},
+		},
+		{
+			name: "token secret ref",
+			spec: v1alpha1.ComponentSubscriptionSpec{
+				Source: v1alpha1.OCMRepository{URL: "ghcr.io/acme", SecretRef: &corev1.SecretReference{Name: "token"}},
+			},
+			expected: map[string]string{"identityToken": "registry-token"},
+		},
+		{
+			name: "dockercfg secret ref",
+			spec: v1alpha1.ComponentSubscriptionSpec{
+				Source: v1alpha1.OCMRepository{URL: "ghcr.io/acme", SecretRef: &corev1.SecretReference{Name: "dockercfg"}},
+			},
+			expected: map[string]string{"username": "user", "password": "pass"},
+		},
+		{
+			name: "dockercfg pull secret",
+			spec: v1alpha1.ComponentSubscriptionSpec{
+				Source:             v1alpha1.OCMRepository{URL: "ghcr.io/acme"},
+				ServiceAccountName: "replication",
+			},
+			objects:  []client.Object{account("dockercfg")},
+			expected: map[string]string{"username": "user", "password": "pass"},
+		},
+		{
+			name: "basic auth pull secret",
+			spec: v1alpha1.ComponentSubscriptionSpec{
+				Source:             v1alpha1.OCMRepository{URL: "ghcr.io/acme"},
+				ServiceAccountName: "replication",
+			},
+			objects: []client.Object{
+				account("ghcr-basic-auth"),
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "ghcr-basic-auth",
+						Namespace:   "default",
+						Annotations: map[string]string{v1alpha1.RegistryAnnotation: "ghcr.io"},
+					},
+					Type: corev1.SecretTypeBasicAuth,
+					Data: basicAuth.Data,
+				},
+			},
+			expected: map[string]string{"username": "user", "password": "pass"},
+		},
+		{
+			name: "token pull secret with registry key",
+			spec: v1alpha1.ComponentSubscriptionSpec{
+				Source:             v1alpha1.OCMRepository{URL: "ghcr.io/acme"},
+				ServiceAccountName: "replication",
+			},
+			objects: []client.Object{
+				account("ghcr-token"),
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "ghcr-token", Namespace: "default"},
+					Data: map[string][]byte{
+						"registry": []byte("https://ghcr.io"),
+						"token":    []byte("registry-token"),
+					},
+				},
+			},
+			expected: map[string]string{"identityToken": "registry-token"},
+		},
+		{
+			name: "basic auth pull secret without registry",
+			spec: v1alpha1.ComponentSubscriptionSpec{
+				Source:             v1alpha1.OCMRepository{URL: "ghcr.io/acme"},
+				ServiceAccountName: "replication",
+			},
+			objects: []client.Object{account("basic-auth")},
+			err: `failed to configure service account access: image pull secret default/basic-auth isn't a docker config, ` +
+				`name its registry with the delivery.ocm.software/registry annotation or a "registry" key`,
+		},
+		{
+			name: "pull secret without credentials",
+			spec: v1alpha1.ComponentSubscriptionSpec{
+				Source:             v1alpha1.OCMRepository{URL: "ghcr.io/acme"},
+				ServiceAccountName: "replication",
+			},
+			objects: []client.Object{
+				account("ca"),
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "ca", Namespace: "default"},
+					Type:       corev1.SecretTypeDockerConfigJson,
+				},
+			},
+			err: `failed to configure service account access: secret default/ca of type kubernetes.io/dockerconfigjson is missing the ".dockerconfigjson" key`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := &v1alpha1.ComponentSubscription{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-name", Namespace: "default"},
+				Spec:       tt.spec,
+			}
+
+			ocmClient := NewClient(env.FakeKubeClient(WithObjects(append(tt.objects, basicAuth, dockercfg, token)...)))
+			octx, err := ocmClient.CreateAuthenticatedOCMContext(context.Background(), cs)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+
+				return
+			}
+			require.NoError(t, err)
+
+			id := cpi.ConsumerIdentity{
+				cpi.ID_TYPE:            identity.CONSUMER_TYPE,
+				identity.ID_HOSTNAME:   "ghcr.io",
+				identity.ID_PATHPREFIX: "acme",
+			}
+			creds, err := octx.CredentialsContext().GetCredentialsForConsumer(id)
+			require.NoError(t, err)
+			consumer, err := creds.Credentials(octx.CredentialsContext())
+			require.NoError(t, err)
+			for key, value := range tt.expected {
+				assert.Equal(t, value, consumer.Properties()[key])
+			}
+		})
+	}
+}
+
+func TestIsUnauthorized(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "invalid authorization",
+			err:      fmt.Errorf("failed to push blob: %w", ctrdocker.ErrInvalidAuthorization),
+			expected: true,
+		},
+		{
+			name:     "unauthorized status",
+			err:      fmt.Errorf("failed to push blob: %w", remoteserrors.ErrUnexpectedStatus{StatusCode: http.StatusUnauthorized}),
+			expected: true,
+		},
+		{
+			name: "status in message only",
+			err:  errors.New("pulling from host ghcr.io failed with status code [manifests v0.0.1]: 401 Unauthorized"),
+		},
+		{
+			name: "forbidden status",
+			err:  remoteserrors.ErrUnexpectedStatus{StatusCode: http.StatusForbidden},
+		},
+		{
+			name: "other error",
+			err:  io.ErrUnexpectedEOF,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isUnauthorized(tt.err))
+		})
+	}
+}
+
+func TestRefreshingCredentials(t *testing.T) {
+	loads := 0
+	creds, err := newRefreshingCredentials(logr.Discard(), func() (common.Properties, error) {
+		loads++
+
+		return common.Properties{"username": "user", "password": fmt.Sprintf("password-%d", loads)}, nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "user", creds.GetProperty("username"))
+	assert.Equal(t, "password-1", creds.GetProperty("password"))
+	assert.True(t, creds.ExistsProperty("password"))
+	assert.Equal(t, 1, loads, "a lookup should load the credentials once")
+
+	creds.stale()
+	assert.Equal(t, "password-2", creds.GetProperty("password"))
+	assert.Equal(t, "password-2", creds.Properties()["password"])
+	assert.Equal(t, 2, loads, "stale credentials should be loaded again")
+}
+
+func TestClient_TransferComponentRotatedCredentials(t *testing.T) {
+	app := handlers.NewApp(context.Background(), &configuration.Configuration{
+		Storage: configuration.Storage{"inmemory": configuration.Parameters{}},
+	})
+
+	credentials := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "destination-credentials", Namespace: "default"},
+		Type:       corev1.SecretTypeBasicAuth,
+		Data: map[string][]byte{
+			corev1.BasicAuthUsernameKey: []byte("replication"),
+			corev1.BasicAuthPasswordKey: []byte("initial"),
+		},
+	}
+	kubeClient := env.FakeKubeClient(WithObjects(credentials))
+
+	// the destination rotates its password after the first blob, like a short-lived token expiring midway.
+	var mu sync.Mutex
+	password, rotated := "initial", false
+	registry := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/v2/destination/") {
+			mu.Lock()
+			current := password
+			mu.Unlock()
+
+			if username, pass, ok := r.BasicAuth(); !ok || username != "replication" || pass != current {
+				w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+				w.WriteHeader(http.StatusUnauthorized)
+
+				return
+			}
+
+			if r.Method == http.MethodPut && r.URL.Query().Has("digest") {
+				defer func() {
+					mu.Lock()
+					defer mu.Unlock()
+
+					if !rotated {
+						rotated, password = true, "rotated"
+						secret := credentials.DeepCopy()
+						secret.Data[corev1.BasicAuthPasswordKey] = []byte("rotated")
+						require.NoError(t, kubeClient.Update(context.Background(), secret))
+					}
+				}()
+			}
+		}
+
+		app.ServeHTTP(w, r)
+	}))
+	defer registry.Close()
+	host := strings.TrimPrefix(registry.URL, "https://")
+	transport := remote.WithTransport(registry.Client().Transport)
+
+	image, err := random.Image(1024, 3)
+	require.NoError(t, err)
+	imageRef, err := name.ParseReference(host + "/images/app:v1")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(imageRef, image, transport))
+	imageDigest, err := image.Digest()
+	require.NoError(t, err)
+
+	newContext := func() ocm.Context {
+		octx := ocm.New()
+		require.NoError(t, rootcertsattr.Get(octx).RegisterRootCertificates(registry.Certificate()))
+
+		return octx
+	}
+
+	component := "github.com/acme/component"
+	version := "v0.0.1"
+	repo, err := newContext().RepositoryForSpec(ocireg.NewRepositorySpec(host+"/source", nil))
+	require.NoError(t, err)
+	comp, err := repo.LookupComponent(component)
+	require.NoError(t, err)
+	cv, err := comp.NewVersion(version)
+	require.NoError(t, err)
+	cv.GetDescriptor().Provider.Name = "acme"
+	meta := compdesc.NewResourceMeta("app", resourcetypes.OCI_IMAGE, ocmmetav1.ExternalRelation)
+	meta.Version = "v1.0.0"
+	require.NoError(t, cv.SetResource(meta, ociartifact.New(imageRef.Context().Digest(imageDigest.String()).String())))
+	require.NoError(t, comp.AddVersion(cv))
+	require.NoError(t, cv.Close())
+	require.NoError(t, comp.Close())
+	require.NoError(t, repo.Close())
+
+	obj := &v1alpha1.ComponentSubscription{
+		ObjectMeta: metav1.ObjectMeta{Name: "subscription", Namespace: "default"},
+		Spec: v1alpha1.ComponentSubscriptionSpec{
+			Component: component,
+			Source:    v1alpha1.OCMRepository{URL: host + "/source"},
+			Destination: &v1alpha1.OCMRepository{
+				URL:       host + "/destination",
+				SecretRef: &corev1.SecretReference{Name: "destination-credentials"},
+			},
+		},
+	}
+
+	octx := newContext()
+	ocmClient := NewClient(kubeClient)
+	require.NoError(t, ocmClient.configureAccessCredentials(context.Background(), octx, *obj.Spec.Destination, obj.Namespace))
+
+	source, err := ocmClient.GetComponentVersion(context.Background(), octx, obj, version)
+	require.NoError(t, err)
+	defer source.Close()
+
+	require.NoError(t, ocmClient.TransferComponent(context.Background(), octx, obj, source))
+
+	mu.Lock()
+	assert.True(t, rotated, "the password should have been rotated during the transfer")
+	mu.Unlock()
+
+	destination, err := ocmClient.GetDestinationComponentVersion(context.Background(), octx, obj, version)
+	require.NoError(t, err)
+	defer destination.Close()
+	assert.Len(t, destination.GetResources(), 1)
+}
+
+func TestClient_ListComponents(t *testing.T) {
+	registry := httptest.NewTLSServer(handlers.NewApp(context.Background(), &configuration.Configuration{
+		Storage: configuration.Storage{"inmemory": configuration.Parameters{}},
+	}))
+	defer registry.Close()
+	host := strings.TrimPrefix(registry.URL, "https://")
+
+	newContext := func() ocm.Context {
+		octx := ocm.New()
+		require.NoError(t, rootcertsattr.Get(octx).RegisterRootCertificates(registry.Certificate()))
+
+		return octx
+	}
+
+	publish := func(repository, component string) {
+		repo, err := newContext().RepositoryForSpec(ocireg.NewRepositorySpec(host+"/"+repository, nil))
+		require.NoError(t, err)
+		defer repo.Close()
+		comp, err := repo.LookupComponent(component)
+		require.NoError(t, err)
+		defer comp.Close()
+		cv, err := comp.NewVersion("v0.0.1")
+		require.NoError(t, err)
+		defer cv.Close()
+		cv.GetDescriptor().Provider.Name = "acme"
+		require.NoError(t, comp.AddVersion(cv))
+	}
+
+	publish("source", "github.com/acme/platform/api")
+	publish("source", "github.com/acme/platform/ui")
+	publish("source", "github.com/acme/tools")
+	publish("other", "github.com/acme/platform/db")
+
+	testCases := []struct {
+		name     string
+		selector v1alpha1.ComponentSelector
+		expected []string
+		err      string
+	}{
+		{
+			name:     "pattern",
+			selector: v1alpha1.ComponentSelector{Pattern: "github.com/acme/platform/*"},
+			expected: []string{"github.com/acme/platform/api", "github.com/acme/platform/ui"},
+		},
+		{
+			name:     "regex matching the whole name",
+			selector: v1alpha1.ComponentSelector{Regex: "github.com/acme/(tools|platform/ui)"},
+			expected: []string{"github.com/acme/platform/ui", "github.com/acme/tools"},
+		},
+		{
+			name:     "no match",
+			selector: v1alpha1.ComponentSelector{Regex: "github.com/acme"},
+		},
+		{
+			name:     "invalid regex",
+			selector: v1alpha1.ComponentSelector{Regex: "github.com/(acme"},
+			err:      `invalid component regex "github.com/(acme"`,
+		},
+		{
+			name:     "pattern and regex",
+			selector: v1alpha1.ComponentSelector{Pattern: "*", Regex: ".*"},
+			err:      "component selector must set either a pattern or a regex",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &v1alpha1.ComponentSubscription{
+				ObjectMeta: metav1.ObjectMeta{Name: "subscription", Namespace: "default"},
+				Spec: v1alpha1.ComponentSubscriptionSpec{
+					ComponentSelector: &tt.selector,
+					Source:            v1alpha1.OCMRepository{URL: host + "/source"},
+				},
+			}
+
+			components, err := NewClient(env.FakeKubeClient()).ListComponents(context.Background(), newContext(), obj)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, components)
+		})
+	}
+
+	t.Run("without selector", func(t *testing.T) {
+		obj := &v1alpha1.ComponentSubscription{
+			ObjectMeta: metav1.ObjectMeta{Name: "subscription", Namespace: "default"},
+			Spec: v1alpha1.ComponentSubscriptionSpec{
+				Source: v1alpha1.OCMRepository{URL: host + "/source"},
+			},
+		}
+
+		components, err := NewClient(env.FakeKubeClient()).ListComponents(context.Background(), newContext(), obj)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"github.com/acme/platform/api", "github.com/acme/platform/ui", "github.com/acme/tools"}, components)
+	})
+}
+
+func TestClient_ListPendingVersions(t *testing.T) {
+	registry := httptest.NewTLSServer(handlers.NewApp(context.Background(), &configuration.Configuration{
+		Storage: configuration.Storage{"inmemory": configuration.Parameters{}},
+	}))
+	defer registry.Close()
+	host := strings.TrimPrefix(registry.URL, "https://")
+
+	newContext := func() ocm.Context {
+		octx := ocm.New()
+		require.NoError(t, rootcertsattr.Get(octx).RegisterRootCertificates(registry.Certificate()))
+
+		return octx
+	}
+
+	publish := func(repository, component, version string, labels ...func(cd *compdesc.ComponentDescriptor)) {
+		repo, err := newContext().RepositoryForSpec(ocireg.NewRepositorySpec(host+"/"+repository, nil))
+		require.NoError(t, err)
+		defer repo.Close()
+		comp, err := repo.LookupComponent(component)
+		require.NoError(t, err)
+		defer comp.Close()
+		cv, err := comp.NewVersion(version)
+		require.NoError(t, err)
+		defer cv.Close()
+		cv.GetDescriptor().Provider.Name = "acme"
+		for _, label := range labels {
+			label(cv.GetDescriptor())
+		}
+		require.NoError(t, comp.AddVersion(cv))
+	}
+
+	sourceDigest := func(component, version string) string {
+		repository, err := parseRepository(host + "/source")
+		require.NoError(t, err)
+		digest, err := manifestDigest(repository, component, version, remote.WithTransport(registry.Client().Transport))
+		require.NoError(t, err)
+
+		return digest
+	}
+
+	for _, version := range []string{"v0.0.1", "v0.0.2", "v0.0.3", "v0.1.0", "v1.0.0-rc.1"} {
+		publish("source", "github.com/acme/api", version)
+	}
+	publish("source", "github.com/acme/new", "v1.0.0")
+
+	for _, version := range []string{"v0.0.1", "v0.0.2", "v0.0.3"} {
+		publish("destination", "github.com/acme/api", version)
+	}
+
+	// v0.0.3 was mirrored before source digests were recorded.
+	mirrored := map[string]string{
+		"v0.0.1": sourceDigest("github.com/acme/api", "v0.0.1"),
+		"v0.0.2": "sha256:outdated",
+	}
+
+	testCases := []struct {
+		name      string
+		component string
+		semver    string
+		versions  []string
+		mirrored  map[string]string
+		pending   []string
+		err       string
+	}{
+		{
+			name:      "missing and changed versions are pending",
+			component: "github.com/acme/api",
+			mirrored:  mirrored,
+			versions:  []string{"v0.0.1", "v0.0.2", "v0.0.3", "v0.1.0", "v1.0.0-rc.1"},
+			pending:   []string{"v0.0.2", "v0.1.0", "v1.0.0-rc.1"},
+		},
+		{
+			name:      "semver constraint",
+			component: "github.com/acme/api",
+			mirrored:  mirrored,
+			semver:    ">=v0.0.2",
+			versions:  []string{"v0.0.2", "v0.0.3", "v0.1.0"},
+			pending:   []string{"v0.0.2", "v0.1.0"},
+		},
+		{
+			name:      "versions without recorded digests are up-to-date",
+			component: "github.com/acme/api",
+			versions:  []string{"v0.0.1", "v0.0.2", "v0.0.3", "v0.1.0", "v1.0.0-rc.1"},
+			pending:   []string{"v0.1.0", "v1.0.0-rc.1"},
+		},
+		{
+			name:      "component missing in the destination",
+			component: "github.com/acme/new",
+			versions:  []string{"v1.0.0"},
+			pending:   []string{"v1.0.0"},
+		},
+		{
+			name:      "invalid semver constraint",
+			component: "github.com/acme/api",
+			semver:    "latest",
+			err:       "failed to parse constraint version",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &v1alpha1.ComponentSubscription{
+				ObjectMeta: metav1.ObjectMeta{Name: "subscription", Namespace: "default"},
+				Spec: v1alpha1.ComponentSubscriptionSpec{
+					Component:   tt.component,
+					Semver:      tt.semver,
+					Source:      v1alpha1.OCMRepository{URL: host + "/source"},
+					Destination: &v1alpha1.OCMRepository{URL: host + "/destination"},
+				},
+			}
+
+			versions, pending, err := NewClient(env.FakeKubeClient()).ListPendingVersions(context.Background(), newContext(), obj, tt.mirrored)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.versions, versions)
+
+			var expected []v1alpha1.MirrorQueueEntry
+			for _, version := range tt.pending {
+				expected = append(expected, v1alpha1.MirrorQueueEntry{
+					Component: tt.component,
+					Version:   version,
+					Digest:    sourceDigest(tt.component, version),
+				})
+			}
+			assert.Equal(t, expected, pending)
+		})
+	}
+}
+
+func TestClient_TransferComponentFollowReferences(t *testing.T) {
+	registry := httptest.NewServer(handlers.NewApp(context.Background(), &configuration.Configuration{
+		Storage: configuration.Storage{"inmemory": configuration.Parameters{}},
+	}))
+	defer registry.Close()
+
+	octx := ocm.New()
+	repo, err := octx.RepositoryForSpec(ocireg.NewRepositorySpec(registry.URL+"/source", nil))
+	require.NoError(t, err)
+	defer repo.Close()
+
+	publish := func(component string, references ...compdesc.ComponentReference) {
+		comp, err := repo.LookupComponent(component)
+		require.NoError(t, err)
+		defer comp.Close()
+		cv, err := comp.NewVersion("v0.0.1")
+		require.NoError(t, err)
+		defer cv.Close()
+		cv.GetDescriptor().Provider.Name = "acme"
+		cv.GetDescriptor().References = references
+		require.NoError(t, comp.AddVersion(cv))
+	}
+
+	publish("github.com/acme/backend")
+	publish("github.com/acme/product", compdesc.ComponentReference{
+		ElementMeta:   compdesc.ElementMeta{Name: "backend", Version: "v0.0.1"},
+		ComponentName: "github.com/acme/backend",
+	})
+
+	for name, follow := range map[string]bool{"recursive": false, "follow references": true} {
+		t.Run(name, func(t *testing.T) {
+			obj := &v1alpha1.ComponentSubscription{
+				ObjectMeta: metav1.ObjectMeta{Name: "subscription", Namespace: "default"},
+				Spec: v1alpha1.ComponentSubscriptionSpec{
+					Component:        "github.com/acme/product",
+					Source:           v1alpha1.OCMRepository{URL: registry.URL + "/source"},
+					Destination:      &v1alpha1.OCMRepository{URL: registry.URL + "/" + strings.ReplaceAll(name, " ", "-")},
+					FollowReferences: follow,
+				},
+			}
+
+			ocmClient := NewClient(env.FakeKubeClient())
+			cv, err := ocmClient.GetComponentVersion(context.Background(), octx, obj, "v0.0.1")
+			require.NoError(t, err)
+			defer cv.Close()
+			require.NoError(t, ocmClient.TransferComponent(context.Background(), octx, obj, cv))
+
+			target, err := octx.RepositoryForSpec(ocireg.NewRepositorySpec(repositoryURL(*obj.Spec.Destination), nil))
+			require.NoError(t, err)
+			defer target.Close()
+
+			ok, err := target.ExistsComponentVersion("github.com/acme/product", "v0.0.1")
+			require.NoError(t, err)
+			assert.True(t, ok)
+
+			ok, err = target.ExistsComponentVersion("github.com/acme/backend", "v0.0.1")
+			assert.Equal(t, !follow, err == nil && ok, "followed references are replicated by subscriptions of their own")
+
+			replicated, err := ocmClient.GetDestinationComponentVersion(context.Background(), octx, obj, "v0.0.1")
+			require.NoError(t, err)
+			defer replicated.Close()
+
+			_, err = ocmClient.SignDestinationComponent(context.Background(), obj, replicated)
+			require.NoError(t, err, "references must be resolvable before they are replicated")
+
+			backend, err := repo.LookupComponentVersion("github.com/acme/backend", "v0.0.1")
+			require.NoError(t, err)
+			defer backend.Close()
+
+			references := replicated.GetDescriptor().References
+			require.Len(t, references, 1)
+			require.NotNil(t, references[0].Digest)
+			expected, err := compdesc.Hash(backend.GetDescriptor(), references[0].Digest.NormalisationAlgorithm, sha256.New())
+			require.NoError(t, err)
+			assert.Equal(t, expected, references[0].Digest.Value)
+			require.Len(t, replicated.GetDescriptor().Signatures, 1)
+		})
+	}
+}
+
+func TestClient_TransferComponentReferences(t *testing.T) {
+	registry := httptest.NewServer(handlers.NewApp(context.Background(), &configuration.Configuration{
+		Storage: configuration.Storage{"inmemory": configuration.Parameters{}},
+	}))
+	defer registry.Close()
+
+	octx := ocm.New()
+	publish := func(url, component string, references ...compdesc.ComponentReference) {
+		repo, err := octx.RepositoryForSpec(ocireg.NewRepositorySpec(url, nil))
+		require.NoError(t, err)
+		defer repo.Close()
+		comp, err := repo.LookupComponent(component)
+		require.NoError(t, err)
+		defer comp.Close()
+		cv, err := comp.NewVersion("v0.0.1")
+		require.NoError(t, err)
+		defer cv.Close()
+		cv.GetDescriptor().Provider.Name = "acme"
+		cv.GetDescriptor().References = references
+		require.NoError(t, comp.AddVersion(cv))
+	}
+
+	reference := func(name, component string) compdesc.ComponentReference {
+		return compdesc.ComponentReference{
+			ElementMeta:   compdesc.ElementMeta{Name: name, Version: "v0.0.1"},
+			ComponentName: component,
+		}
+	}
+
+	publish(registry.URL+"/source", "github.com/acme/addon")
+	publish(registry.URL+"/source", "github.com/acme/testing/e2e")
+	publish(registry.URL+"/source", "github.com/acme/backend", reference("addon", "github.com/acme/addon"))
+	publish(registry.URL+"/source", "github.com/acme/product",
+		reference("backend", "github.com/acme/backend"),
+		reference("e2e", "github.com/acme/testing/e2e"),
+	)
+	publish(registry.URL+"/fallback", "github.com/acme/testing/e2e")
+
+	testCases := []struct {
+		name        string
+		references  *v1alpha1.ReferenceSelection
+		transferred []bool
+		err         string
+	}{
+		{
+			name:        "all references are transferred by default",
+			transferred: []bool{true, true, true},
+		},
+		{
+			name: "excluded references are skipped",
+			references: &v1alpha1.ReferenceSelection{
+				Exclude: []v1alpha1.ReferenceRule{{Component: "github.com/acme/testing/*"}},
+			},
+			transferred: []bool{true, true, false},
+		},
+		{
+			name: "included references are selected at every level",
+			references: &v1alpha1.ReferenceSelection{
+				Include: []v1alpha1.ReferenceRule{{Name: "backend"}, {Name: "e2e"}},
+				Exclude: []v1alpha1.ReferenceRule{{Name: "e2e", Component: "github.com/acme/testing/*"}},
+			},
+			transferred: []bool{true, false, false},
+		},
+		{
+			name: "excluded references are resolved from the fallback repository",
+			references: &v1alpha1.ReferenceSelection{
+				Exclude:  []v1alpha1.ReferenceRule{{Name: "e2e"}},
+				Fallback: &v1alpha1.OCMRepository{URL: registry.URL + "/fallback"},
+			},
+			transferred: []bool{true, true, false},
+		},
+		{
+			name: "excluded references must be available in the fallback repository",
+			references: &v1alpha1.ReferenceSelection{
+				Exclude:  []v1alpha1.ReferenceRule{{Name: "addon"}},
+				Fallback: &v1alpha1.OCMRepository{URL: registry.URL + "/fallback"},
+			},
+			err: "excluded reference addon to github.com/acme/addon:v0.0.1 is missing in the fallback repository",
+		},
+	}
+
+	for i, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &v1alpha1.ComponentSubscription{
+				ObjectMeta: metav1.ObjectMeta{Name: "subscription", Namespace: "default"},
+				Spec: v1alpha1.ComponentSubscriptionSpec{
+					Component:   "github.com/acme/product",
+					Source:      v1alpha1.OCMRepository{URL: registry.URL + "/source"},
+					Destination: &v1alpha1.OCMRepository{URL: fmt.Sprintf("%s/destination-%d", registry.URL, i)},
+					Transfer:    &v1alpha1.TransferSpec{References: tt.references},
+				},
+			}
+
+			ocmClient := NewClient(env.FakeKubeClient())
+			cv, err := ocmClient.GetComponentVersion(context.Background(), octx, obj, "v0.0.1")
+			require.NoError(t, err)
+			defer cv.Close()
+
+			err = ocmClient.TransferComponent(context.Background(), octx, obj, cv)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			expected := []v1alpha1.TransferredReference{
+				{Parent: "github.com/acme/product:v0.0.1", Name: "backend", Component: "github.com/acme/backend:v0.0.1"},
+				{Parent: "github.com/acme/backend:v0.0.1", Name: "addon", Component: "github.com/acme/addon:v0.0.1"},
+				{Parent: "github.com/acme/product:v0.0.1", Name: "e2e", Component: "github.com/acme/testing/e2e:v0.0.1"},
+			}
+			for i := range expected {
+				expected[i].Transferred = tt.transferred[i]
+			}
+			assert.Equal(t, expected, obj.Status.References)
+
+			target, err := octx.RepositoryForSpec(ocireg.NewRepositorySpec(repositoryURL(*obj.Spec.Destination), nil))
+			require.NoError(t, err)
+			defer target.Close()
+
+			for _, ref := range expected {
+				name, version, _ := strings.Cut(ref.Component, ":")
+				ok, err := target.ExistsComponentVersion(name, version)
+				assert.Equal(t, ref.Transferred, err == nil && ok, ref.Component)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package ocm
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/open-component-model/ocm/pkg/blobaccess"
+	"github.com/open-component-model/ocm/pkg/common/accessobj"
+	"github.com/open-component-model/ocm/pkg/contexts/oci"
+	"github.com/open-component-model/ocm/pkg/contexts/oci/artdesc"
+	"github.com/open-component-model/ocm/pkg/contexts/oci/repositories/artifactset"
+	ociregistry "github.com/open-component-model/ocm/pkg/contexts/oci/repositories/ocireg"
+	ocitransfer "github.com/open-component-model/ocm/pkg/contexts/oci/transfer"
+	"github.com/open-component-model/ocm/pkg/contexts/oci/transfer/filters"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/accessmethods/ociartifact"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/attrs/keepblobattr"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/attrs/mapocirepoattr"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/attrs/signingattr"
+	storagecontext "github.com/open-component-model/ocm/pkg/contexts/ocm/blobhandler/handlers/oci"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/blobhandler/handlers/oci/ocirepo"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/compdesc"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/cpi"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/cpi/accspeccpi"
+	ocmerrors "github.com/open-component-model/ocm/pkg/errors"
+)
+
+// splitPlatform splits a platform in the form `os/arch` into its parts.
+func splitPlatform(platform string) (string, string, error) {
+	os, arch, ok := strings.Cut(platform, "/")
+	if !ok || os == "" || arch == "" || strings.Contains(arch, "/") {
+		return "", "", fmt.Errorf("invalid platform %q, expected os/arch", platform)
+	}
+
+	return os, arch, nil
+}
+
+// parsePlatforms returns a filter accepting images of any of the given platforms in the form `os/arch`.
+func parsePlatforms(platforms []string) (filters.Filter, error) {
+	var result []filters.Filter
+	for _, platform := range platforms {
+		os, arch, err := splitPlatform(platform)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, filters.Platform(os, arch, true))
+	}
+
+	return filters.Or(result...), nil
+}
+
+// platformHandler stores OCI artifacts in OCI registries like the default OCM blob handler, but trims image indexes
+// to the images matching the filter. The references of all stored artifacts whose digest changed are recorded, so
+// the digests of the resources pointing to them can be updated once the transfer is done.
+type platformHandler struct {
+	filter  filters.Filter
+	trimmed map[string]bool
+}
+
+var _ cpi.BlobHandler = &platformHandler{}
+
+// registerPlatformHandler replaces the blob handlers for OCI artifacts stored in OCI registries in the given context.
+func registerPlatformHandler(octx ocm.Context, filter filters.Filter) *platformHandler {
+	handler := &platformHandler{filter: filter, trimmed: map[string]bool{}}
+
+	for _, mime := range artdesc.ArchiveBlobTypes() {
+		for _, typ := range []string{ociregistry.Type, ociregistry.LegacyType, ociregistry.ShortType} {
+			octx.BlobHandlers().Register(handler, cpi.ForRepo(oci.CONTEXT_TYPE, typ), cpi.ForMimeType(mime))
+		}
+	}
+
+	return handler
+}
+
+// StoreBlob transfers the artifact into the namespace derived from the hint. It follows the implementation of the
+// default OCM handler for OCI registries.
+func (h *platformHandler) StoreBlob(blob cpi.BlobAccess, _, hint string, _ cpi.AccessSpec, ctx cpi.StorageContext) (_ cpi.AccessSpec, err error) {
+	mediaType := blob.MimeType()
+	if !artdesc.IsOCIMediaType(mediaType) || (!strings.HasSuffix(mediaType, "+tar") && !strings.HasSuffix(mediaType, "+tar+gzip")) {
+		return nil, nil
+	}
+
+	ocictx, ok := ctx.(*storagecontext.StorageContext)
+	if !ok {
+		return nil, fmt.Errorf("failed to assert type %T to storagecontext.StorageContext", ctx)
+	}
+
+	var art oci.ArtifactAccess
+
+	// transfer directly from the source registry if possible.
+	if m, ok := blob.(blobaccess.AnnotatedBlobAccess[accspeccpi.AccessMethodView]); ok && !keepblobattr.Get(ctx.GetContext()) {
+		if method, ok := m.Source().Unwrap().(ociartifact.AccessMethodImpl); ok {
+			if art, _, err = method.GetArtifact(); err != nil {
+				return nil, fmt.Errorf("failed to access source artifact: %w", err)
+			}
+
+			if art != nil {
+				defer art.Close()
+			}
+		}
+	}
+
+	namespace := ocictx.Namespace
+	var name, tag string
+	if hint != "" {
+		name, _, _ = strings.Cut(hint, "@")
+		if i := strings.LastIndex(name, ":"); i > 0 {
+			name, tag = name[:i], name[i+1:]
+		}
+
+		prefix := cpi.RepositoryPrefix(ctx.TargetComponentRepository().GetSpecification())
+		mapping := mapocirepoattr.Get(ctx.GetContext())
+		if mapping.Prefix != nil {
+			prefix = *mapping.Prefix
+		}
+
+		name = path.Join(prefix, mapping.Map(name))
+		if namespace, err = ocictx.Repository.LookupNamespace(name); err != nil {
+			return nil, fmt.Errorf("failed to lookup namespace %s: %w", name, err)
+		}
+		defer namespace.Close()
+	}
+
+	if art == nil {
+		set, err := artifactset.OpenFromBlob(accessobj.ACC_READONLY, blob)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open artifact set: %w", err)
+		}
+		defer set.Close()
+
+		if art, err = set.GetArtifact(set.GetMain().String()); err != nil {
+			return nil, fmt.Errorf("failed to get artifact from artifact set: %w", err)
+		}
+		defer art.Close()
+	}
+
+	// only indexes are trimmed, single images are transferred as they are.
+	var filter filters.Filter
+	if art.IsIndex() {
+		filter = h.filter
+	}
+
+	digest, err := ocitransfer.TransferArtifactWithFilter(art, namespace, filter, oci.AsTags(tag)...)
+	if err != nil {
+		if ocmerrors.IsErrNoMatch(err) {
+			return nil, fmt.Errorf("image index %s contains no image for the selected platforms", art.Digest())
+		}
+
+		return nil, fmt.Errorf("failed to transfer artifact to %s: %w", namespace.GetNamespace(), err)
+	}
+
+	version := "@" + digest.String()
+	if tag != "" {
+		version = ":" + tag
+	}
+
+	reference := path.Join(ocirepo.OCIRegBaseFunction(ocictx), namespace.GetNamespace()) + version
+	if *digest != art.Digest() {
+		h.trimmed[reference] = true
+	}
+
+	return ociartifact.New(reference), nil
+}
+
+// updateDigests recalculates the digests of all resources of the component version and its references accepted by
+// the filter that point to trimmed artifacts. The digests of references are recalculated bottom-up, so parents point
+// to the updated descriptors of their references. The signatures of affected component versions are removed, as they
+// are no longer valid.
+func (h *platformHandler) updateDigests(cv ocm.ComponentVersionAccess, selected referenceFilter) error {
+	if len(h.trimmed) == 0 {
+		return nil
+	}
+
+	_, err := h.updateComponentDigests(cv, selected, map[string]*compdesc.ComponentDescriptor{})
+
+	return err
+}
+
+// updateComponentDigests updates the digests of the references of the component version before its own digests and
+// reports whether its descriptor changed. Updated records the descriptors of visited references, nil if unchanged.
+func (h *platformHandler) updateComponentDigests(
+	cv ocm.ComponentVersionAccess,
+	selected referenceFilter,
+	updated map[string]*compdesc.ComponentDescriptor,
+) (bool, error) {
+	changed := false
+
+	for _, res := range cv.GetResources() {
+		spec, err := res.Access()
+		if err != nil {
+			return false, fmt.Errorf("failed to get access of resource %s: %w", res.Meta().GetName(), err)
+		}
+
+		acc, ok := spec.(*ociartifact.AccessSpec)
+		if !ok || !h.trimmed[acc.ImageReference] {
+			continue
+		}
+
+		meta := res.Meta().Copy()
+		meta.Digest = nil
+		if err := cv.SetResource(meta, spec, ocm.ModifyResource()); err != nil {
+			return false, fmt.Errorf("failed to update digest of resource %s: %w", meta.GetName(), err)
+		}
+
+		changed = true
+	}
+
+	cd := cv.GetDescriptor()
+	for i, ref := range cd.References {
+		key := fmt.Sprintf("%s:%s", ref.ComponentName, ref.Version)
+		if !selected(cv, ref) {
+			continue
+		}
+
+		nested, visited := updated[key]
+		if !visited {
+			var err error
+			if nested, err = h.updateReferenceDigests(cv, ref, selected, updated); err != nil {
+				return false, err
+			}
+
+			updated[key] = nested
+		}
+
+		if nested == nil || ref.Digest == nil {
+			continue
+		}
+
+		hasher := signingattr.Get(cv.GetContext()).GetHasher(ref.Digest.HashAlgorithm)
+		if hasher == nil {
+			return false, fmt.Errorf("unknown hash algorithm %s of reference %s", ref.Digest.HashAlgorithm, key)
+		}
+
+		value, err := compdesc.Hash(nested, ref.Digest.NormalisationAlgorithm, hasher.Create())
+		if err != nil {
+			return false, fmt.Errorf("failed to calculate digest of reference %s: %w", key, err)
+		}
+
+		if value == ref.Digest.Value {
+			continue
+		}
+
+		digest := *ref.Digest
+		digest.Value = value
+		cd.References[i].Digest = &digest
+		cd.Signatures = nil
+		changed = true
+	}
+
+	return changed, nil
+}
+
+// updateReferenceDigests updates the digests of the referenced component version and returns its descriptor if it
+// changed. Closing the component version persists the updated descriptor.
+func (h *platformHandler) updateReferenceDigests(
+	parent ocm.ComponentVersionAccess,
+	ref compdesc.ComponentReference,
+	selected referenceFilter,
+	updated map[string]*compdesc.ComponentDescriptor,
+) (_ *compdesc.ComponentDescriptor, err error) {
+	nested, err := parent.Repository().LookupComponentVersion(ref.ComponentName, ref.Version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up referenced component %s:%s: %w", ref.ComponentName, ref.Version, err)
+	}
+	defer func() {
+		err = errors.Join(err, nested.Close())
+	}()
+
+	changed, err := h.updateComponentDigests(nested, selected, updated)
+	if err != nil || !changed {
+		return nil, err
+	}
+
+	return nested.GetDescriptor().Copy(), nil
+}
@@ -0,0 +1,67 @@
+package ocm
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"github.com/open-component-model/ocm/pkg/contexts/ocm"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/compdesc"
+	metav1 "github.com/open-component-model/ocm/pkg/contexts/ocm/compdesc/meta/v1"
+
+	"github.com/open-component-model/replication-controller/api/v1alpha1"
+	"github.com/open-component-model/replication-controller/pkg/version"
+)
+
+// AddProvenance labels the replicated destination component with its origin: the source repository, the digest of the
+// source descriptor, the time of replication, the subscription and the controller version. The labels aren't relevant
+// for signing, so they don't change the digest of the component and the signatures transferred from the source, e.g.
+// by the publisher, stay valid. As no signature covers them, the signed record of the provenance is the attestation
+// created by AttestReplication. Like signing, the labels are only persisted once the destination component is closed.
+func (c *Client) AddProvenance(
+	_ context.Context,
+	obj *v1alpha1.ComponentSubscription,
+	source ocm.ComponentVersionAccess,
+	destination ocm.ComponentVersionAccess,
+) error {
+	digest, err := descriptorDigest(source.GetDescriptor())
+	if err != nil {
+		return err
+	}
+
+	labels := []struct {
+		name  string
+		value any
+	}{
+		{name: v1alpha1.SourceRepositoryLabel, value: obj.Spec.Source.URL},
+		{name: v1alpha1.SourceDigestLabel, value: digest},
+		{name: v1alpha1.ReplicatedAtLabel, value: time.Now().UTC().Format(time.RFC3339)},
+		{name: v1alpha1.SubscriptionLabel, value: obj.Namespace + "/" + obj.Name},
+		{name: v1alpha1.ControllerVersionLabel, value: version.ReleaseVersion},
+	}
+
+	cd := destination.GetDescriptor()
+	for _, l := range labels {
+		if err := cd.Labels.Set(l.name, l.value); err != nil {
+			return fmt.Errorf("failed to set label %s: %w", l.name, err)
+		}
+	}
+
+	return nil
+}
+
+// descriptorDigest returns the digest of a component descriptor as recorded by the source digest label.
+func descriptorDigest(cd *compdesc.ComponentDescriptor) (metav1.DigestSpec, error) {
+	digest, err := compdesc.Hash(cd, compdesc.JsonNormalisationV2, sha256.New())
+	if err != nil {
+		return metav1.DigestSpec{}, fmt.Errorf("failed to hash source component descriptor: %w", err)
+	}
+
+	return metav1.DigestSpec{
+		HashAlgorithm:          crypto.SHA256.String(),
+		NormalisationAlgorithm: compdesc.JsonNormalisationV2,
+		Value:                  digest,
+	}, nil
+}
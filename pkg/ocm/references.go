@@ -0,0 +1,105 @@
+package ocm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/containerd/containerd/errdefs"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/compdesc"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/transfer/transferhandler"
+	ocmerrors "github.com/open-component-model/ocm/pkg/errors"
+
+	"github.com/open-component-model/replication-controller/api/v1alpha1"
+)
+
+// referenceFilter decides whether a reference of the parent component version is followed.
+type referenceFilter func(parent ocm.ComponentVersionAccess, ref compdesc.ComponentReference) bool
+
+// selectedReferences returns a filter accepting the references selected by the transfer spec of the subscription.
+func selectedReferences(obj *v1alpha1.ComponentSubscription) referenceFilter {
+	return func(_ ocm.ComponentVersionAccess, ref compdesc.ComponentReference) bool {
+		return obj.Spec.Transfer.SelectsReference(ref.Name, ref.ComponentName)
+	}
+}
+
+// transferredReferences returns a filter accepting the references transferred with the component version of the
+// subscription. None of them are if the subscription follows them with subscriptions of their own.
+func transferredReferences(obj *v1alpha1.ComponentSubscription) referenceFilter {
+	selected := selectedReferences(obj)
+
+	return func(parent ocm.ComponentVersionAccess, ref compdesc.ComponentReference) bool {
+		return !obj.Spec.FollowReferences && selected(parent, ref)
+	}
+}
+
+// referenceHandler skips the references rejected by a filter at every level of a recursive transfer.
+type referenceHandler struct {
+	transferhandler.TransferHandler
+	selected referenceFilter
+}
+
+var _ transferhandler.TransferHandler = &referenceHandler{}
+
+// TransferVersion returns no component version for rejected references, so they aren't transferred. Transferred
+// references are handled by the referenceHandler as well.
+func (h *referenceHandler) TransferVersion(
+	repo ocm.Repository,
+	src ocm.ComponentVersionAccess,
+	meta *compdesc.ComponentReference,
+	tgt ocm.Repository,
+) (ocm.ComponentVersionAccess, transferhandler.TransferHandler, error) {
+	if src != nil && !h.selected(src, *meta) {
+		return nil, nil, nil
+	}
+
+	cv, handler, err := h.TransferHandler.TransferVersion(repo, src, meta, tgt)
+	if err != nil || handler == nil {
+		return cv, handler, err
+	}
+
+	return cv, &referenceHandler{TransferHandler: handler, selected: h.selected}, nil
+}
+
+// referenceTree returns every reference of the component version and of the references accepted by the filter,
+// and whether they are accepted. References of rejected references aren't listed.
+func referenceTree(cv ocm.ComponentVersionAccess, selected referenceFilter) ([]v1alpha1.TransferredReference, error) {
+	var tree []v1alpha1.TransferredReference
+	err := walkReferences(cv, func(parent ocm.ComponentVersionAccess, ref compdesc.ComponentReference) bool {
+		transferred := selected(parent, ref)
+		tree = append(tree, v1alpha1.TransferredReference{
+			Parent:      fmt.Sprintf("%s:%s", parent.GetName(), parent.GetVersion()),
+			Name:        ref.Name,
+			Component:   fmt.Sprintf("%s:%s", ref.ComponentName, ref.Version),
+			Transferred: transferred,
+		})
+
+		return transferred
+	}, func(ocm.ComponentVersionAccess) {})
+	if err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
+
+// checkFallback makes sure every excluded reference of the tree can be resolved from the fallback repository.
+func checkFallback(fallback ocm.Repository, tree []v1alpha1.TransferredReference) error {
+	for _, ref := range tree {
+		if ref.Transferred {
+			continue
+		}
+
+		name, version, _ := strings.Cut(ref.Component, ":")
+		found, err := fallback.ExistsComponentVersion(name, version)
+		if err != nil && !ocmerrors.IsErrNotFound(err) && !errdefs.IsNotFound(err) {
+			return fmt.Errorf("failed to look up excluded reference %s in the fallback repository: %w", ref.Component, err)
+		}
+
+		if !found {
+			return fmt.Errorf("excluded reference %s to %s is missing in the fallback repository", ref.Name, ref.Component)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,231 @@
+package ocm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	ctrdocker "github.com/containerd/containerd/remotes/docker"
+	remoteserrors "github.com/containerd/containerd/remotes/errors"
+	"github.com/go-logr/logr"
+	"github.com/open-component-model/ocm/pkg/common"
+	"github.com/open-component-model/ocm/pkg/contexts/credentials"
+	"github.com/open-component-model/ocm/pkg/contexts/credentials/builtin/oci/identity"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/compdesc"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/cpi"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/transfer/transferhandler"
+	ocmdocker "github.com/open-component-model/ocm/pkg/docker"
+	"github.com/open-component-model/ocm/pkg/generics"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/open-component-model/replication-controller/api/v1alpha1"
+)
+
+// maxTransferAttempts is how often the blob of a resource or source is transferred if the registry rejects the
+// credentials.
+const maxTransferAttempts = 3
+
+// credentialRefreshInterval is how long loaded credentials are used before they're loaded again. The registry
+// clients read several properties for every credential lookup, which share one load.
+const credentialRefreshInterval = 10 * time.Second
+
+// refreshingCredentials are loaded again when they're read after the refresh interval or after they were marked
+// stale. The registry clients read the credentials when a registry rejects a token, so long-running transfers pick
+// up rotated Secrets and renewed tokens of credential helpers instead of failing with the credentials they started
+// with.
+type refreshingCredentials struct {
+	load   func() (common.Properties, error)
+	logger logr.Logger
+
+	mu       sync.Mutex
+	last     common.Properties
+	loadedAt time.Time
+}
+
+var _ credentials.Credentials = &refreshingCredentials{}
+
+// newRefreshingCredentials loads the credentials once, failing if they can't be loaded.
+func newRefreshingCredentials(logger logr.Logger, load func() (common.Properties, error)) (*refreshingCredentials, error) {
+	properties, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	return &refreshingCredentials{load: load, logger: logger, last: properties, loadedAt: time.Now()}, nil
+}
+
+// properties returns the current credentials, loading them again if they're due. The last loaded credentials are
+// kept if they can't be loaded, e.g. because the Secret is being replaced.
+func (r *refreshingCredentials) properties() common.Properties {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.loadedAt.IsZero() && time.Since(r.loadedAt) < credentialRefreshInterval {
+		return r.last
+	}
+
+	properties, err := r.load()
+	if err != nil {
+		r.logger.Error(err, "failed to refresh credentials, using the last known credentials")
+
+		return r.last
+	}
+
+	r.last, r.loadedAt = properties, time.Now()
+
+	return properties
+}
+
+// stale makes the next read load the credentials again, e.g. because a registry rejected them.
+func (r *refreshingCredentials) stale() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.loadedAt = time.Time{}
+}
+
+func (r *refreshingCredentials) Credentials(credentials.Context, ...credentials.CredentialsSource) (credentials.Credentials, error) {
+	return r, nil
+}
+
+func (r *refreshingCredentials) ExistsProperty(name string) bool {
+	_, ok := r.properties()[name]
+
+	return ok
+}
+
+func (r *refreshingCredentials) GetProperty(name string) string {
+	return r.properties()[name]
+}
+
+func (r *refreshingCredentials) PropertyNames() generics.Set[string] {
+	return r.properties().Names()
+}
+
+func (r *refreshingCredentials) Properties() common.Properties {
+	return r.properties().Copy()
+}
+
+// withProperties returns the credentials with additional properties, keeping refreshing credentials refreshing.
+func withProperties(creds credentials.Credentials, properties common.Properties) credentials.Credentials {
+	if refreshing, ok := creds.(*refreshingCredentials); ok {
+		return &refreshingCredentials{
+			load: func() (common.Properties, error) {
+				loaded, err := refreshing.load()
+				if err != nil {
+					return nil, err
+				}
+
+				return mergeProperties(loaded, properties), nil
+			},
+			logger: refreshing.logger,
+			last:   refreshing.last,
+		}
+	}
+
+	loaded := common.Properties{}
+	if creds != nil {
+		loaded = creds.Properties()
+	}
+
+	return credentials.DirectCredentials(mergeProperties(loaded, properties))
+}
+
+func mergeProperties(properties, additional common.Properties) common.Properties {
+	merged := properties.Copy()
+	for name, value := range additional {
+		merged.SetNonEmptyValue(name, value)
+	}
+
+	return merged
+}
+
+// isUnauthorized returns whether the registry rejected the credentials of a request.
+func isUnauthorized(err error) bool {
+	if errors.Is(err, ctrdocker.ErrInvalidAuthorization) || errors.Is(err, ocmdocker.ErrInvalidAuthorization) {
+		return true
+	}
+
+	var status remoteserrors.ErrUnexpectedStatus
+
+	return errors.As(err, &status) && status.StatusCode == http.StatusUnauthorized
+}
+
+// retryingHandler transfers the blob of a resource or source again if a registry rejects the credentials midway,
+// e.g. because a token expired during a long-running transfer. The credentials are refreshed before retrying.
+type retryingHandler struct {
+	transferhandler.TransferHandler
+	refresh func()
+	logger  logr.Logger
+}
+
+var _ transferhandler.TransferHandler = &retryingHandler{}
+
+// newRetryingHandler wraps the handler, so rejected credentials of the repositories are refreshed and the blob
+// transfer is retried. Cached tokens of credential helpers are dropped and the other credentials are loaded again.
+func (c *Client) newRetryingHandler(
+	ctx context.Context,
+	octx ocm.Context,
+	repositories []v1alpha1.OCMRepository,
+	handler transferhandler.TransferHandler,
+) *retryingHandler {
+	return &retryingHandler{
+		TransferHandler: handler,
+		refresh: func() {
+			c.credentialHelpers.forget(repositories...)
+
+			for _, repository := range repositories {
+				id := identity.GetConsumerId(repositoryHost(repository), "")
+				if creds, err := octx.CredentialsContext().GetCredentialsForConsumer(id); err == nil {
+					if refreshing, ok := creds.(*refreshingCredentials); ok {
+						refreshing.stale()
+					}
+				}
+			}
+		},
+		logger: log.FromContext(ctx),
+	}
+}
+
+// TransferVersion retries the blob transfers of transferred references as well.
+func (h *retryingHandler) TransferVersion(
+	repo ocm.Repository,
+	src ocm.ComponentVersionAccess,
+	meta *compdesc.ComponentReference,
+	tgt ocm.Repository,
+) (ocm.ComponentVersionAccess, transferhandler.TransferHandler, error) {
+	cv, handler, err := h.TransferHandler.TransferVersion(repo, src, meta, tgt)
+	if err != nil || handler == nil {
+		return cv, handler, err
+	}
+
+	return cv, &retryingHandler{TransferHandler: handler, refresh: h.refresh, logger: h.logger}, nil
+}
+
+func (h *retryingHandler) HandleTransferResource(r ocm.ResourceAccess, m cpi.AccessMethod, hint string, t ocm.ComponentVersionAccess) error {
+	return h.retry(r.Meta().GetName(), func() error {
+		return h.TransferHandler.HandleTransferResource(r, m, hint, t)
+	})
+}
+
+func (h *retryingHandler) HandleTransferSource(r ocm.SourceAccess, m cpi.AccessMethod, hint string, t ocm.ComponentVersionAccess) error {
+	return h.retry(r.Meta().GetName(), func() error {
+		return h.TransferHandler.HandleTransferSource(r, m, hint, t)
+	})
+}
+
+func (h *retryingHandler) retry(name string, transfer func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := transfer()
+		if err == nil || attempt == maxTransferAttempts || !isUnauthorized(err) {
+			return err
+		}
+
+		h.logger.Info("registry rejected the credentials, retrying the blob transfer with refreshed credentials",
+			"artifact", name, "attempt", attempt, "error", err.Error())
+		h.refresh()
+	}
+}
@@ -0,0 +1,347 @@
+package ocm
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/open-component-model/ocm/pkg/common"
+	"github.com/open-component-model/ocm/pkg/contexts/credentials"
+	"github.com/open-component-model/ocm/pkg/contexts/credentials/builtin/oci/identity"
+	"github.com/open-component-model/ocm/pkg/contexts/datacontext/attrs/rootcertsattr"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/repositories/ocireg"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/open-component-model/replication-controller/api/v1alpha1"
+	"github.com/open-component-model/replication-controller/pkg/ocitransport"
+)
+
+// repositoryURL returns the URL of the repository as understood by OCM. Registries served over plain HTTP are
+// addressed with an explicit scheme.
+func repositoryURL(repository v1alpha1.OCMRepository) string {
+	if repository.PlainHTTP && !strings.Contains(repository.URL, "://") {
+		return "http://" + repository.URL
+	}
+
+	return repository.URL
+}
+
+// repositoryHost returns the host and port of the registry of the repository.
+func repositoryHost(repository v1alpha1.OCMRepository) string {
+	host := repository.URL
+	if _, rest, ok := strings.Cut(host, "://"); ok {
+		host = rest
+	}
+
+	host, _, _ = strings.Cut(host, "/")
+
+	return host
+}
+
+// repositoryPath returns the path of the repository below its registry host.
+func repositoryPath(repository v1alpha1.OCMRepository) string {
+	p := repository.URL
+	if _, rest, ok := strings.Cut(p, "://"); ok {
+		p = rest
+	}
+
+	_, p, _ = strings.Cut(p, "/")
+
+	return strings.Trim(p, "/")
+}
+
+// registryURL returns the base URL of the registry of the repository, keeping an explicit scheme.
+func registryURL(repository v1alpha1.OCMRepository) string {
+	u := repositoryURL(repository)
+	if scheme, _, ok := strings.Cut(u, "://"); ok {
+		return scheme + "://" + repositoryHost(repository)
+	}
+
+	return repositoryHost(repository)
+}
+
+// repositorySpec returns the OCM specification of the repository. Repositories needing the registry transport are
+// accessed through the one configured for their registry by configureRegistryTransport.
+func repositorySpec(octx ocm.Context, repository v1alpha1.OCMRepository) ocm.RepositorySpec {
+	spec := ocireg.NewRepositorySpec(repositoryURL(repository), nil)
+	if !needsRegistryTransport(repository) {
+		return spec
+	}
+
+	if alias, ok := octx.OCIContext().GetAlias(repositoryHost(repository)).(*ocitransport.RepositorySpec); ok {
+		spec.RepositorySpec = alias
+	}
+
+	return spec
+}
+
+// needsRegistryTransport returns whether the repository needs a transport the OCM client doesn't configure, i.e.
+// one sending requests through a proxy, skipping the certificate verification or presenting a client certificate.
+// Repositories with a certificate Secret are included, as it may contain a client certificate.
+func needsRegistryTransport(repository v1alpha1.OCMRepository) bool {
+	return repository.Proxy != nil || repository.Insecure || repository.CertSecretRef != nil
+}
+
+// configureRegistryTransport makes OCM send requests to the registries of repositories needing it through the
+// registry transport. The registries are registered as aliases of the OCI context, so artifacts referenced by
+// resources of the components are accessed the same way.
+func (c *Client) configureRegistryTransport(ctx context.Context, octx ocm.Context, obj *v1alpha1.ComponentSubscription) error {
+	proxies, err := c.registryProxies(ctx, obj)
+	if err != nil {
+		return err
+	}
+
+	repositories := ocmRepositories(obj)
+	transport := newRegistryTransport(octx, proxies, repositories...)
+
+	for _, repository := range repositories {
+		if !needsRegistryTransport(repository) {
+			continue
+		}
+
+		octx.OCIContext().SetAlias(repositoryHost(repository), ocitransport.NewRepositorySpec(registryURL(repository), transport))
+	}
+
+	return nil
+}
+
+// certificateSecret returns the Secret referenced by the certSecretRef of the repository.
+func (c *Client) certificateSecret(
+	ctx context.Context,
+	repository v1alpha1.OCMRepository,
+	namespace string,
+) (types.NamespacedName, *corev1.Secret, error) {
+	key := SecretReferenceKey(namespace, repository.CertSecretRef)
+	if err := c.AuthorizeSecretReference(ctx, namespace, key); err != nil {
+		return key, nil, err
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.client.Get(ctx, key, secret); err != nil {
+		return key, nil, fmt.Errorf("failed to get certificate secret %s: %w", key, err)
+	}
+
+	return key, secret, nil
+}
+
+// configureCertificates adds the CA bundle and client certificate of the repository to the credentials configured
+// for its registry, where both OCM and the registry transport pick them up.
+func (c *Client) configureCertificates(ctx context.Context, octx ocm.Context, repository v1alpha1.OCMRepository, namespace string) error {
+	key, secret, err := c.certificateSecret(ctx, repository, namespace)
+	if err != nil {
+		return err
+	}
+
+	ca, cert, privateKey := secret.Data[caCertKey], secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]
+	if len(ca) == 0 && len(cert) == 0 {
+		return fmt.Errorf("certificate secret %s contains neither %s nor %s", key, caCertKey, corev1.TLSCertKey)
+	}
+
+	if (len(cert) == 0) != (len(privateKey) == 0) {
+		return fmt.Errorf("certificate secret %s must contain both %s and %s", key, corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+	}
+
+	if len(ca) > 0 && !x509.NewCertPool().AppendCertsFromPEM(ca) {
+		return fmt.Errorf("failed to parse %s in certificate secret %s", caCertKey, key)
+	}
+
+	if len(cert) > 0 {
+		if _, err := tls.X509KeyPair(cert, privateKey); err != nil {
+			return fmt.Errorf("failed to parse client certificate in secret %s: %w", key, err)
+		}
+	}
+
+	host := repositoryHost(repository)
+
+	// keep the credentials configured for the registry so far, e.g. by the secretRef or the service account.
+	creds, err := identity.GetCredentials(octx, host, "")
+	if err != nil {
+		return fmt.Errorf("failed to get credentials for %s: %w", host, err)
+	}
+
+	properties := common.Properties{}
+	properties.SetNonEmptyValue(credentials.ATTR_CERTIFICATE_AUTHORITY, string(ca))
+	properties.SetNonEmptyValue(credentials.ATTR_CERTIFICATE, string(cert))
+	properties.SetNonEmptyValue(credentials.ATTR_PRIVATE_KEY, string(privateKey))
+
+	octx.CredentialsContext().SetCredentialsForConsumer(identity.GetConsumerId(host, ""), withProperties(creds, properties))
+
+	return nil
+}
+
+// registryTransport configures TLS per registry from the credentials of the OCM context, like the OCM client does.
+// A CA bundle replaces the root certificates of the context. Unlike the OCM client, it also presents client
+// certificates, skips the verification for insecure registries and sends requests through the proxies of the
+// repositories.
+type registryTransport struct {
+	octx     ocm.Context
+	insecure map[string]bool
+	proxies  []registryProxy
+
+	mu         sync.Mutex
+	transports map[transportKey]http.RoundTripper
+}
+
+type transportKey struct {
+	host  string
+	proxy string
+}
+
+var _ http.RoundTripper = &registryTransport{}
+
+// newRegistryTransport creates a transport for the registries of the given repositories. Requests to registries
+// without a proxy use the proxy environment variables.
+func newRegistryTransport(octx ocm.Context, proxies []registryProxy, repositories ...v1alpha1.OCMRepository) *registryTransport {
+	t := &registryTransport{
+		octx:       octx,
+		insecure:   map[string]bool{},
+		proxies:    proxies,
+		transports: map[transportKey]http.RoundTripper{},
+	}
+
+	for _, repository := range repositories {
+		if repository.Insecure {
+			t.insecure[repositoryHost(repository)] = true
+		}
+	}
+
+	return t
+}
+
+// RoundTrip sends the request with the transport configured for its host and repository.
+func (t *registryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport, err := t.transport(req.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	return transport.RoundTrip(req)
+}
+
+func (t *registryTransport) transport(u *url.URL) (http.RoundTripper, error) {
+	proxy := proxyFor(t.proxies, u)
+
+	key := transportKey{host: u.Host}
+	if proxy != nil {
+		key.proxy = proxy.String()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if transport, ok := t.transports[key]; ok {
+		return transport, nil
+	}
+
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		RootCAs:    rootcertsattr.Get(t.octx).GetRootCertPool(true),
+		//nolint:gosec // explicitly requested for the registry.
+		InsecureSkipVerify: t.insecure[u.Host],
+	}
+
+	creds, err := identity.GetCredentials(t.octx, u.Host, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials for %s: %w", u.Host, err)
+	}
+
+	if creds != nil {
+		if ca := creds.GetProperty(credentials.ATTR_CERTIFICATE_AUTHORITY); ca != "" {
+			config.RootCAs = x509.NewCertPool()
+			config.RootCAs.AppendCertsFromPEM([]byte(ca))
+		}
+
+		if config.Certificates, err = credentials.GetClientCerts(t.octx, creds); err != nil {
+			return nil, fmt.Errorf("failed to get client certificates for %s: %w", u.Host, err)
+		}
+	}
+
+	transport := remote.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	if proxy != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	t.transports[key] = transport
+
+	return transport, nil
+}
+
+// registryProxy is the proxy configured for a repository.
+type registryProxy struct {
+	host  string
+	path  string
+	proxy *url.URL
+}
+
+// proxyFor returns the proxy of the repository the request is sent to. Requests to the registry API of a repository
+// use its proxy, preferring the repository with the longest path. Other requests to the host, e.g. to check the API
+// version or to obtain a token, use the first proxy configured for it. Nil is returned for hosts without proxy.
+func proxyFor(proxies []registryProxy, u *url.URL) *url.URL {
+	var first, match *registryProxy
+	for i := range proxies {
+		candidate := &proxies[i]
+		if candidate.host != u.Host {
+			continue
+		}
+
+		if first == nil {
+			first = candidate
+		}
+
+		if strings.HasPrefix(u.Path, path.Join("/v2", candidate.path)+"/") && (match == nil || len(candidate.path) > len(match.path)) {
+			match = candidate
+		}
+	}
+
+	switch {
+	case match != nil:
+		return match.proxy
+	case first != nil:
+		return first.proxy
+	default:
+		return nil
+	}
+}
+
+// registryProxies returns the proxies configured for the repositories of the subscription. Credentials of the
+// proxies are added to their URLs.
+func (c *Client) registryProxies(ctx context.Context, obj *v1alpha1.ComponentSubscription) ([]registryProxy, error) {
+	var proxies []registryProxy
+	for _, repository := range RegistryRepositories(obj) {
+		if repository.Proxy == nil {
+			continue
+		}
+
+		proxy, err := url.Parse(repository.Proxy.URL)
+		if err != nil || proxy.Scheme == "" || proxy.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q for %s", repository.Proxy.URL, repository.URL)
+		}
+
+		if repository.Proxy.SecretRef != nil {
+			secret := &corev1.Secret{}
+			key := types.NamespacedName{Namespace: obj.Namespace, Name: repository.Proxy.SecretRef.Name}
+			if err := c.client.Get(ctx, key, secret); err != nil {
+				return nil, fmt.Errorf("failed to get proxy secret %s: %w", key, err)
+			}
+
+			proxy.User = url.UserPassword(string(secret.Data[identity.ATTR_USERNAME]), string(secret.Data[identity.ATTR_PASSWORD]))
+		}
+
+		proxies = append(proxies, registryProxy{
+			host:  repositoryHost(repository),
+			path:  repositoryPath(repository),
+			proxy: proxy,
+		})
+	}
+
+	return proxies, nil
+}
@@ -0,0 +1,299 @@
+package ocm
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/open-component-model/ocm/pkg/common"
+	"github.com/open-component-model/ocm/pkg/contexts/credentials"
+	"github.com/open-component-model/ocm/pkg/contexts/credentials/builtin/oci/identity"
+	"github.com/open-component-model/ocm/pkg/contexts/credentials/repositories/dockerconfig"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/open-component-model/replication-controller/api/v1alpha1"
+)
+
+// tokenKey holds a registry token in Secrets that aren't docker configs. It's used as identity token.
+const tokenKey = "token"
+
+// registryKey holds the registry host of the credentials in image pull Secrets that aren't docker configs, like the
+// v1alpha1.RegistryAnnotation.
+const registryKey = "registry"
+
+// dockerConfig is the content of a kubernetes.io/dockerconfigjson Secret.
+type dockerConfig struct {
+	Auths map[string]dockerConfigEntry `json:"auths"`
+}
+
+type dockerConfigEntry struct {
+	Username      string `json:"username,omitempty"`
+	Password      string `json:"password,omitempty"`
+	Auth          string `json:"auth,omitempty"`
+	IdentityToken string `json:"identitytoken,omitempty"`
+}
+
+// registryCredentials are the credentials stored in a Secret. Docker configs hold credentials per registry, the
+// other formats hold the credentials of the registry the Secret is used for.
+type registryCredentials struct {
+	dockerConfig []byte
+	properties   common.Properties
+}
+
+// parseRegistryCredentials reads the registry credentials of a kubernetes.io/dockerconfigjson, a legacy
+// kubernetes.io/dockercfg or a kubernetes.io/basic-auth Secret, or a Secret holding a token. The format of Opaque
+// Secrets is detected from their keys. Docker configs are reduced to their auths, so credential helpers and
+// stores named in them are never run.
+func parseRegistryCredentials(secret *corev1.Secret) (*registryCredentials, error) {
+	key := client.ObjectKeyFromObject(secret)
+	required := map[corev1.SecretType][]string{
+		corev1.SecretTypeDockerConfigJson: {corev1.DockerConfigJsonKey},
+		corev1.SecretTypeDockercfg:        {corev1.DockerConfigKey},
+		corev1.SecretTypeBasicAuth:        {corev1.BasicAuthUsernameKey, corev1.BasicAuthPasswordKey},
+	}
+	for _, name := range required[secret.Type] {
+		if _, ok := secret.Data[name]; !ok {
+			return nil, fmt.Errorf("secret %s of type %s is missing the %q key", key, secret.Type, name)
+		}
+	}
+
+	if data, ok := secret.Data[corev1.DockerConfigJsonKey]; ok {
+		config := struct {
+			Auths map[string]json.RawMessage `json:"auths"`
+		}{}
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse %s in secret %s: %w", corev1.DockerConfigJsonKey, key, err)
+		}
+
+		return newDockerConfigCredentials(config.Auths)
+	}
+
+	if data, ok := secret.Data[corev1.DockerConfigKey]; ok {
+		auths := map[string]json.RawMessage{}
+		if err := json.Unmarshal(data, &auths); err != nil {
+			return nil, fmt.Errorf("failed to parse %s in secret %s: %w", corev1.DockerConfigKey, key, err)
+		}
+
+		return newDockerConfigCredentials(auths)
+	}
+
+	username := string(secret.Data[identity.ATTR_USERNAME])
+	password := string(secret.Data[identity.ATTR_PASSWORD])
+	token := string(secret.Data[identity.ATTR_IDENTITY_TOKEN])
+	if token == "" {
+		token = string(secret.Data[tokenKey])
+	}
+
+	properties := common.Properties{}
+	switch {
+	case token != "":
+		properties.SetNonEmptyValue(identity.ATTR_USERNAME, username)
+		properties.SetNonEmptyValue(identity.ATTR_IDENTITY_TOKEN, token)
+	case username != "" && password != "":
+		properties.SetNonEmptyValue(identity.ATTR_USERNAME, username)
+		properties.SetNonEmptyValue(identity.ATTR_PASSWORD, password)
+	case username != "":
+		return nil, fmt.Errorf("secret %s is missing the %q key", key, identity.ATTR_PASSWORD)
+	case password != "":
+		return nil, fmt.Errorf("secret %s is missing the %q key", key, identity.ATTR_USERNAME)
+	default:
+		return nil, fmt.Errorf("secret %s contains no registry credentials, expected one of the keys %s, %s, %s and %s, %s or %s",
+			key, corev1.DockerConfigJsonKey, corev1.DockerConfigKey, identity.ATTR_USERNAME, identity.ATTR_PASSWORD,
+			identity.ATTR_IDENTITY_TOKEN, tokenKey)
+	}
+
+	return &registryCredentials{properties: properties}, nil
+}
+
+func newDockerConfigCredentials(auths map[string]json.RawMessage) (*registryCredentials, error) {
+	data, err := json.Marshal(map[string]any{"auths": auths})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal docker config: %w", err)
+	}
+
+	return &registryCredentials{dockerConfig: data}, nil
+}
+
+// configureRegistryCredentials configures the registry credentials stored in a Secret. Credentials that aren't
+// stored per registry are configured for the given registry hosts.
+func configureRegistryCredentials(octx ocm.Context, secret *corev1.Secret, hosts ...string) error {
+	creds, err := parseRegistryCredentials(secret)
+	if err != nil {
+		return err
+	}
+
+	if creds.dockerConfig != nil {
+		spec := dockerconfig.NewRepositorySpecForConfig(creds.dockerConfig, true)
+		if _, err := octx.CredentialsContext().RepositoryForSpec(spec); err != nil {
+			return fmt.Errorf("failed to configure credentials of secret %s: %w", client.ObjectKeyFromObject(secret), err)
+		}
+
+		return nil
+	}
+
+	for _, host := range hosts {
+		octx.CredentialsContext().SetCredentialsForConsumer(identity.GetConsumerId(host, ""), credentials.DirectCredentials(creds.properties))
+	}
+
+	return nil
+}
+
+// secretRegistry returns the registry host the credentials of a Secret that isn't a docker config are used for, as
+// named by the v1alpha1.RegistryAnnotation or the registry key. It's empty if the Secret names none.
+func secretRegistry(secret *corev1.Secret) string {
+	registry := secret.Annotations[v1alpha1.RegistryAnnotation]
+	if registry == "" {
+		registry = string(secret.Data[registryKey])
+	}
+
+	return dockerConfigHost(strings.TrimSpace(registry))
+}
+
+// forHost returns the credentials for the registry host, or nil if a docker config holds none for it.
+func (r *registryCredentials) forHost(host string) (common.Properties, error) {
+	if r.dockerConfig == nil {
+		return r.properties, nil
+	}
+
+	config := dockerConfig{}
+	if err := json.Unmarshal(r.dockerConfig, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse docker config: %w", err)
+	}
+
+	for server, entry := range config.Auths {
+		if dockerConfigHost(server) != dockerConfigHost(host) {
+			continue
+		}
+
+		properties := common.Properties{}
+		properties.SetNonEmptyValue(identity.ATTR_USERNAME, entry.Username)
+		properties.SetNonEmptyValue(identity.ATTR_PASSWORD, entry.Password)
+		properties.SetNonEmptyValue(identity.ATTR_IDENTITY_TOKEN, entry.IdentityToken)
+
+		if entry.Auth != "" {
+			auth, err := base64.StdEncoding.DecodeString(entry.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode auth of %s: %w", server, err)
+			}
+
+			username, password, _ := strings.Cut(string(auth), ":")
+			properties.SetNonEmptyValue(identity.ATTR_USERNAME, username)
+			properties.SetNonEmptyValue(identity.ATTR_PASSWORD, password)
+		}
+
+		return properties, nil
+	}
+
+	return nil, nil
+}
+
+// dockerConfigHost returns the registry host of a docker config server, which may be a URL.
+func dockerConfigHost(server string) string {
+	if _, rest, ok := strings.Cut(server, "://"); ok {
+		server = rest
+	}
+
+	server, _, _ = strings.Cut(server, "/")
+	switch server {
+	case "index.docker.io", "registry-1.docker.io":
+		return "docker.io"
+	}
+
+	return server
+}
+
+// configureSecretCredentials configures the registry credentials of the Secret for the registry host. The Secret is
+// read again whenever the credentials are used, so credentials rotated during long-running transfers take effect
+// once the registry rejects the old ones.
+func (c *Client) configureSecretCredentials(ctx context.Context, octx ocm.Context, key types.NamespacedName, host string) error {
+	secret := &corev1.Secret{}
+	if err := c.client.Get(ctx, key, secret); err != nil {
+		log.FromContext(ctx).V(v1alpha1.LevelDebug).Error(err, "failed to find credentials")
+
+		// we don't ignore not found errors
+		return fmt.Errorf("failed to get credentials secret %s: %w", key, err)
+	}
+
+	if err := configureRegistryCredentials(octx, secret, host); err != nil {
+		return err
+	}
+
+	load := func() (common.Properties, error) {
+		secret := &corev1.Secret{}
+		if err := c.client.Get(ctx, key, secret); err != nil {
+			return nil, fmt.Errorf("failed to get credentials secret %s: %w", key, err)
+		}
+
+		creds, err := parseRegistryCredentials(secret)
+		if err != nil {
+			return nil, err
+		}
+
+		properties, err := creds.forHost(host)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get credentials for %s from secret %s: %w", host, key, err)
+		}
+
+		if properties == nil {
+			return nil, fmt.Errorf("secret %s contains no credentials for %s", key, host)
+		}
+
+		return properties, nil
+	}
+
+	creds, err := parseRegistryCredentials(secret)
+	if err != nil {
+		return err
+	}
+
+	// docker configs without an entry for the host keep the credentials configured above.
+	if properties, err := creds.forHost(host); err != nil || properties == nil {
+		return err
+	}
+
+	refreshing, err := newRefreshingCredentials(log.FromContext(ctx), load)
+	if err != nil {
+		return err
+	}
+
+	octx.CredentialsContext().SetCredentialsForConsumer(identity.GetConsumerId(host, ""), refreshing)
+
+	return nil
+}
+
+// DockerConfig returns the registry credentials stored in a Secret as dockerconfigjson, e.g. for image pull
+// secrets. Credentials that aren't stored per registry are returned for the registry host of the repository. The
+// host is used for plain HTTP registries as well, as container runtimes look up credentials by host.
+func DockerConfig(secret *corev1.Secret, repository v1alpha1.OCMRepository) ([]byte, error) {
+	creds, err := parseRegistryCredentials(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if creds.dockerConfig != nil {
+		return creds.dockerConfig, nil
+	}
+
+	entry := dockerConfigEntry{
+		Username:      creds.properties[identity.ATTR_USERNAME],
+		Password:      creds.properties[identity.ATTR_PASSWORD],
+		IdentityToken: creds.properties[identity.ATTR_IDENTITY_TOKEN],
+	}
+
+	if entry.Password != "" {
+		entry.Auth = base64.StdEncoding.EncodeToString([]byte(entry.Username + ":" + entry.Password))
+	}
+
+	data, err := json.Marshal(dockerConfig{Auths: map[string]dockerConfigEntry{repositoryHost(repository): entry}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal docker config: %w", err)
+	}
+
+	return data, nil
+}
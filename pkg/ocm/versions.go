@@ -0,0 +1,162 @@
+package ocm
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/containerd/containerd/errdefs"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/repositories/genericocireg"
+	"github.com/open-component-model/ocm/pkg/contexts/ocm/repositories/genericocireg/componentmapping"
+	ocmerrors "github.com/open-component-model/ocm/pkg/errors"
+
+	"github.com/open-component-model/replication-controller/api/v1alpha1"
+)
+
+// ListPendingVersions lists the versions of the component of the subscription in the source repository, and those
+// of them that must be mirrored to the destination repository together with their source manifest digest. A version
+// must be mirrored if it's missing in the destination, or if its source digest differs from the one it was mirrored
+// with according to mirrored, which maps versions to the source digests recorded when they were transferred.
+// Versions in the destination without a recorded digest are considered up-to-date. Only the manifests of the source
+// versions are looked up, neither descriptor is downloaded. The semver constraint of the subscription restricts the
+// versions if set. Versions are sorted in ascending order, versions that aren't valid semantic versions last.
+func (c *Client) ListPendingVersions(
+	ctx context.Context,
+	octx ocm.Context,
+	obj *v1alpha1.ComponentSubscription,
+	mirrored map[string]string,
+) (versions []string, pending []v1alpha1.MirrorQueueEntry, err error) {
+	if obj.Spec.Destination == nil {
+		return nil, nil, fmt.Errorf("destination repository is not set")
+	}
+
+	source, err := octx.RepositoryForSpec(repositorySpec(octx, obj.Spec.Source))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get repository for spec: %w", err)
+	}
+	defer source.Close()
+
+	destination, err := octx.RepositoryForSpec(repositorySpec(octx, *obj.Spec.Destination))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get repository for spec: %w", err)
+	}
+	defer destination.Close()
+
+	versions, err = listVersions(source, obj.Spec.Component)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list source versions: %w", err)
+	}
+
+	if versions, err = filterVersions(versions, obj.Spec.Semver); err != nil {
+		return nil, nil, err
+	}
+
+	// components that weren't mirrored yet have no versions in the destination.
+	existing, err := listVersions(destination, obj.Spec.Component)
+	if err != nil && !ocmerrors.IsErrNotFound(err) && !errdefs.IsNotFound(err) {
+		return nil, nil, fmt.Errorf("failed to list destination versions: %w", err)
+	}
+
+	repository, err := parseRepository(repositoryURL(obj.Spec.Source))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	opts, err := c.registryOptions(ctx, octx, obj)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, version := range versions {
+		recorded, ok := mirrored[version]
+		exists := slices.Contains(existing, version)
+		if exists && !ok {
+			continue
+		}
+
+		digest, err := manifestDigest(repository, obj.Spec.Component, version, opts...)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if exists && digest == recorded {
+			continue
+		}
+
+		pending = append(pending, v1alpha1.MirrorQueueEntry{Component: obj.Spec.Component, Version: version, Digest: digest})
+	}
+
+	return versions, pending, nil
+}
+
+// listVersions lists the versions of a component in a repository.
+func listVersions(repo ocm.Repository, component string) ([]string, error) {
+	access, err := repo.LookupComponent(component)
+	if err != nil {
+		return nil, fmt.Errorf("component error: %w", err)
+	}
+	defer access.Close()
+
+	return access.ListVersions()
+}
+
+// filterVersions sorts the versions and drops those not satisfying the semver constraint, if one is given.
+func filterVersions(versions []string, constraint string) ([]string, error) {
+	var filter *semver.Constraints
+	if constraint != "" {
+		var err error
+		if filter, err = semver.NewConstraint(constraint); err != nil {
+			return nil, fmt.Errorf("failed to parse constraint version: %w", err)
+		}
+	}
+
+	parsed := make(map[string]*semver.Version, len(versions))
+	filtered := make([]string, 0, len(versions))
+	for _, version := range versions {
+		v, err := semver.NewVersion(version)
+		if err == nil {
+			parsed[version] = v
+		}
+
+		if filter != nil && (v == nil || !filter.Check(v)) {
+			continue
+		}
+
+		filtered = append(filtered, version)
+	}
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		a, b := parsed[filtered[i]], parsed[filtered[j]]
+		switch {
+		case a != nil && b != nil:
+			return a.LessThan(b)
+		case a != nil || b != nil:
+			return a != nil
+		default:
+			return filtered[i] < filtered[j]
+		}
+	})
+
+	return filtered, nil
+}
+
+// manifestDigest returns the digest of the manifest a component version is stored with in a repository. It changes
+// with the descriptor and the local blobs of the component version.
+func manifestDigest(repository name.Repository, component, version string, opts ...remote.Option) (string, error) {
+	tag := strings.ReplaceAll(version, "+", genericocireg.META_SEPARATOR)
+	ref := repository.Registry.Repo(path.Join(repository.RepositoryStr(), componentmapping.ComponentDescriptorNamespace, component)).Tag(tag)
+
+	desc, err := remote.Head(ref, opts...)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve version %s: %w", version, err)
+	}
+
+	return desc.Digest.String(), nil
+}
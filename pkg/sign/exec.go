@@ -0,0 +1,145 @@
+package sign
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"time"
+
+	"github.com/open-component-model/replication-controller/pkg/keys"
+)
+
+const defaultExecTimeout = 30 * time.Second
+
+// ExecSigner delegates signing to an external binary. The binary is called with the configured arguments
+// followed by a command:
+//
+//	public-key   prints the PEM encoded public key or certificate chain of the signing key to stdout.
+//	sign         reads an ExecSignRequest as JSON from stdin and prints an ExecSignResponse as JSON to stdout.
+//
+// A non-zero exit code fails the operation, anything printed to stderr is included in the error.
+type ExecSigner struct {
+	command   string
+	args      []string
+	timeout   time.Duration
+	public    crypto.PublicKey
+	publicPEM []byte
+}
+
+// ExecSignRequest is passed to the signing binary to sign a digest.
+type ExecSignRequest struct {
+	// Hash is the name of the hash function used to compute the digest, e.g. SHA-256.
+	Hash string `json:"hash"`
+	// Digest is the digest to sign.
+	Digest []byte `json:"digest"`
+}
+
+// ExecSignResponse is returned by the signing binary.
+type ExecSignResponse struct {
+	// Signature is the RSASSA-PKCS1-V1_5 signature of the digest.
+	Signature []byte `json:"signature"`
+}
+
+var _ Signer = &ExecSigner{}
+
+// ExecSignerOption configures optional behaviour of the ExecSigner.
+type ExecSignerOption func(s *ExecSigner)
+
+// WithExecArgs configures arguments passed to the signing binary before the command.
+func WithExecArgs(args ...string) ExecSignerOption {
+	return func(s *ExecSigner) {
+		s.args = args
+	}
+}
+
+// WithExecTimeout configures how long a call to the signing binary may take.
+func WithExecTimeout(timeout time.Duration) ExecSignerOption {
+	return func(s *ExecSigner) {
+		s.timeout = timeout
+	}
+}
+
+// NewExecSigner creates a Signer calling the given binary. The public key is requested once on creation.
+func NewExecSigner(ctx context.Context, command string, opts ...ExecSignerOption) (*ExecSigner, error) {
+	s := &ExecSigner{
+		command: command,
+		timeout: defaultExecTimeout,
+	}
+
+	for _, o := range opts {
+		o(s)
+	}
+
+	out, err := s.run(ctx, "public-key", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public key: %w", err)
+	}
+
+	key, err := keys.ParseKey(out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key returned by %s: %w", command, err)
+	}
+
+	s.public = key.PublicKey
+	s.publicPEM = out
+
+	return s, nil
+}
+
+// Public returns the public key of the signing key.
+func (s *ExecSigner) Public() crypto.PublicKey {
+	return s.public
+}
+
+// PublicKeyPEM returns the PEM encoded public key or certificate chain of the signing key.
+func (s *ExecSigner) PublicKeyPEM() []byte {
+	return s.publicPEM
+}
+
+// Sign calls the signing binary to sign the digest.
+func (s *ExecSigner) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	request, err := json.Marshal(ExecSignRequest{
+		Hash:   opts.HashFunc().String(),
+		Digest: digest,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal sign request: %w", err)
+	}
+
+	out, err := s.run(context.Background(), "sign", request)
+	if err != nil {
+		return nil, err
+	}
+
+	response := ExecSignResponse{}
+	if err := json.Unmarshal(out, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal sign response: %w", err)
+	}
+
+	if len(response.Signature) == 0 {
+		return nil, fmt.Errorf("%s returned an empty signature", s.command)
+	}
+
+	return response.Signature, nil
+}
+
+func (s *ExecSigner) run(ctx context.Context, command string, stdin []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.command, append(append([]string{}, s.args...), command)...) //nolint:gosec // configured by the operator
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to run %s %s: %w: %s", s.command, command, err, stderr.String())
+	}
+
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,103 @@
+package sign
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	helperKeyEnv  = "EXEC_SIGNER_HELPER_KEY"
+	helperFailEnv = "EXEC_SIGNER_HELPER_FAIL"
+)
+
+// TestExecSignerHelper is not a real test, it acts as the signing binary called by the ExecSigner.
+func TestExecSignerHelper(t *testing.T) {
+	keyFile := os.Getenv(helperKeyEnv)
+	if keyFile == "" {
+		t.Skip("only run as signing binary")
+	}
+
+	os.Exit(runHelper(keyFile, os.Args[len(os.Args)-1]))
+}
+
+func runHelper(keyFile, command string) int {
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	block, _ := pem.Decode(data)
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	switch command {
+	case "public-key":
+		_, _ = os.Stdout.Write(encodePublicKeyToPEM(&key.PublicKey))
+	case "sign":
+		if os.Getenv(helperFailEnv) != "" {
+			fmt.Fprintln(os.Stderr, "key is locked")
+			return 1
+		}
+
+		request := ExecSignRequest{}
+		if err := json.NewDecoder(os.Stdin).Decode(&request); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, request.Digest)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		_ = json.NewEncoder(os.Stdout).Encode(ExecSignResponse{Signature: sig})
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %s\n", command)
+		return 2
+	}
+
+	return 0
+}
+
+func TestExecSigner(t *testing.T) {
+	priv, pub, err := GenerateSigningKeyPEMPair()
+	require.NoError(t, err)
+
+	keyFile := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(keyFile, priv, 0o600))
+	t.Setenv(helperKeyEnv, keyFile)
+
+	signer, err := NewExecSigner(context.Background(), os.Args[0], WithExecArgs("-test.run=TestExecSignerHelper", "--"))
+	require.NoError(t, err)
+	assert.Equal(t, pub, signer.PublicKeyPEM())
+
+	digest := sha256.Sum256([]byte("component descriptor"))
+	sig, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	require.NoError(t, err)
+
+	publicKey, ok := signer.Public().(*rsa.PublicKey)
+	require.True(t, ok)
+	assert.NoError(t, rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], sig))
+
+	t.Setenv(helperFailEnv, "true")
+	_, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	assert.ErrorContains(t, err, "key is locked")
+}
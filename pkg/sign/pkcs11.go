@@ -0,0 +1,100 @@
+//go:build pkcs11
+
+package sign
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+
+	"github.com/ThalesIgnite/crypto11"
+)
+
+// PKCS11Signer signs with a private key stored in a PKCS#11 token, e.g. an HSM or SoftHSM. It requires the
+// controller to be built with cgo and the `pkcs11` build tag.
+type PKCS11Signer struct {
+	ctx       *crypto11.Context
+	signer    crypto11.Signer
+	publicPEM []byte
+}
+
+var _ Signer = &PKCS11Signer{}
+
+// NewPKCS11Signer opens a session to the token described by the config and looks up the key pair with the
+// given label. If the token holds a certificate with the same label, its certificate is published instead of
+// the plain public key.
+func NewPKCS11Signer(config PKCS11Config) (*PKCS11Signer, error) {
+	ctx, err := crypto11.Configure(&crypto11.Config{
+		Path:       config.Module,
+		TokenLabel: config.TokenLabel,
+		SlotNumber: config.Slot,
+		Pin:        config.Pin,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure PKCS#11 module: %w", err)
+	}
+
+	signer, err := ctx.FindKeyPair(nil, []byte(config.KeyLabel))
+	if err != nil {
+		_ = ctx.Close()
+
+		return nil, fmt.Errorf("failed to find key pair %s: %w", config.KeyLabel, err)
+	}
+
+	if signer == nil {
+		_ = ctx.Close()
+
+		return nil, fmt.Errorf("key pair %s not found", config.KeyLabel)
+	}
+
+	s := &PKCS11Signer{
+		ctx:    ctx,
+		signer: signer,
+	}
+
+	cert, err := ctx.FindCertificate(nil, []byte(config.KeyLabel), nil)
+	if err != nil {
+		_ = ctx.Close()
+
+		return nil, fmt.Errorf("failed to find certificate %s: %w", config.KeyLabel, err)
+	}
+
+	if cert != nil {
+		s.publicPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+
+		return s, nil
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		_ = ctx.Close()
+
+		return nil, fmt.Errorf("failed to marshal public key: %w", err)
+	}
+
+	s.publicPEM = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	return s, nil
+}
+
+// Public returns the public key of the signing key.
+func (s *PKCS11Signer) Public() crypto.PublicKey {
+	return s.signer.Public()
+}
+
+// PublicKeyPEM returns the PEM encoded public key or certificate of the signing key.
+func (s *PKCS11Signer) PublicKeyPEM() []byte {
+	return s.publicPEM
+}
+
+// Sign signs the digest on the token.
+func (s *PKCS11Signer) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return s.signer.Sign(rand, digest, opts)
+}
+
+// Close closes the session to the token.
+func (s *PKCS11Signer) Close() error {
+	return s.ctx.Close()
+}
@@ -0,0 +1,15 @@
+package sign
+
+// PKCS11Config describes the PKCS#11 token and key pair used by the PKCS11Signer.
+type PKCS11Config struct {
+	// Module is the path to the PKCS#11 library, e.g. /usr/lib/softhsm/libsofthsm2.so.
+	Module string
+	// TokenLabel selects the token by its label.
+	TokenLabel string
+	// Slot selects the token by its slot number if no token label is set.
+	Slot *int
+	// Pin is the user PIN of the token.
+	Pin string
+	// KeyLabel is the label of the key pair used for signing.
+	KeyLabel string
+}
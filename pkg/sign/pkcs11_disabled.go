@@ -0,0 +1,39 @@
+//go:build !pkcs11
+
+package sign
+
+import (
+	"crypto"
+	"fmt"
+	"io"
+)
+
+// PKCS11Signer is not available, the controller was built without the `pkcs11` build tag.
+type PKCS11Signer struct{}
+
+var _ Signer = &PKCS11Signer{}
+
+// NewPKCS11Signer always fails, the controller was built without the `pkcs11` build tag.
+func NewPKCS11Signer(_ PKCS11Config) (*PKCS11Signer, error) {
+	return nil, fmt.Errorf("PKCS#11 support is not available, rebuild the controller with cgo and the pkcs11 build tag")
+}
+
+// Public returns nil.
+func (s *PKCS11Signer) Public() crypto.PublicKey {
+	return nil
+}
+
+// PublicKeyPEM returns nil.
+func (s *PKCS11Signer) PublicKeyPEM() []byte {
+	return nil
+}
+
+// Sign always fails.
+func (s *PKCS11Signer) Sign(_ io.Reader, _ []byte, _ crypto.SignerOpts) ([]byte, error) {
+	return nil, fmt.Errorf("PKCS#11 support is not available")
+}
+
+// Close does nothing.
+func (s *PKCS11Signer) Close() error {
+	return nil
+}
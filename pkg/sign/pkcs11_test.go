@@ -0,0 +1,91 @@
+//go:build pkcs11
+
+package sign
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"testing"
+
+	"github.com/ThalesIgnite/crypto11"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The tests run against a SoftHSM token, e.g. one initialised with
+// `softhsm2-util --init-token --free --label replication --pin 1234 --so-pin 1234`.
+const (
+	softHSMModuleEnv     = "SOFTHSM_MODULE"
+	softHSMTokenLabelEnv = "SOFTHSM_TOKEN_LABEL"
+	softHSMPinEnv        = "SOFTHSM_PIN"
+)
+
+// softHSMConfig returns the configuration of the SoftHSM token, skipping the test if it isn't set up.
+func softHSMConfig(t *testing.T, keyLabel string) PKCS11Config {
+	t.Helper()
+
+	config := PKCS11Config{
+		Module:     os.Getenv(softHSMModuleEnv),
+		TokenLabel: os.Getenv(softHSMTokenLabelEnv),
+		Pin:        os.Getenv(softHSMPinEnv),
+		KeyLabel:   keyLabel,
+	}
+	if config.Module == "" || config.TokenLabel == "" || config.Pin == "" {
+		t.Skipf("%s, %s and %s must be set to test the PKCS#11 signer", softHSMModuleEnv, softHSMTokenLabelEnv, softHSMPinEnv)
+	}
+
+	return config
+}
+
+// generateKeyPair creates an RSA key pair with the label on the token and removes it once the test is done.
+func generateKeyPair(t *testing.T, config PKCS11Config) {
+	t.Helper()
+
+	ctx, err := crypto11.Configure(&crypto11.Config{
+		Path:       config.Module,
+		TokenLabel: config.TokenLabel,
+		Pin:        config.Pin,
+	})
+	require.NoError(t, err)
+
+	signer, err := ctx.GenerateRSAKeyPairWithLabel([]byte(config.KeyLabel), []byte(config.KeyLabel), 2048)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, signer.Delete())
+		assert.NoError(t, ctx.Close())
+	})
+}
+
+func TestPKCS11Signer(t *testing.T) {
+	config := softHSMConfig(t, "replication-controller-test")
+	generateKeyPair(t, config)
+
+	signer, err := NewPKCS11Signer(config)
+	require.NoError(t, err)
+	defer signer.Close()
+
+	block, _ := pem.Decode(signer.PublicKeyPEM())
+	require.NotNil(t, block)
+	assert.Equal(t, "PUBLIC KEY", block.Type)
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	require.NoError(t, err)
+	assert.Equal(t, signer.Public(), pub)
+
+	digest := sha256.Sum256([]byte("component descriptor"))
+	signature, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	require.NoError(t, err)
+	assert.NoError(t, rsa.VerifyPKCS1v15(pub.(*rsa.PublicKey), crypto.SHA256, digest[:], signature))
+}
+
+func TestPKCS11SignerMissingKey(t *testing.T) {
+	config := softHSMConfig(t, "replication-controller-missing")
+
+	_, err := NewPKCS11Signer(config)
+	assert.EqualError(t, err, "key pair replication-controller-missing not found")
+}
@@ -0,0 +1,88 @@
+package sign
+
+import (
+	"crypto"
+	"crypto/rand"
+	gorsa "crypto/rsa"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/open-component-model/ocm/pkg/contexts/credentials"
+	"github.com/open-component-model/ocm/pkg/signing"
+	"github.com/open-component-model/ocm/pkg/signing/handlers/rsa"
+	"github.com/open-component-model/ocm/pkg/signing/signutils"
+)
+
+// Signer signs components with a private key the controller has no direct access to, e.g. a key kept in an HSM
+// or by an external signing service.
+type Signer interface {
+	crypto.Signer
+
+	// PublicKeyPEM returns the PEM encoded public key or certificate chain of the signing key.
+	PublicKeyPEM() []byte
+}
+
+// Handler is an OCM signing handler that creates RSASSA-PKCS1-V1_5 signatures using a Signer passed as the
+// private key. The signatures are identical to the ones created by the OCM RSA handler, so consumers verify
+// them without knowing how they were created.
+type Handler struct{}
+
+var _ signing.Signer = Handler{}
+
+// Algorithm returns the name of the signature algorithm.
+func (Handler) Algorithm() string {
+	return rsa.Algorithm
+}
+
+// Sign signs the digest with the Signer provided as private key by the signing context.
+func (Handler) Sign(_ credentials.Context, digest string, sctx signing.SigningContext) (*signing.Signature, error) {
+	signer, ok := sctx.GetPrivateKey().(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("private key of type %T is not a signer", sctx.GetPrivateKey())
+	}
+
+	public, ok := signer.Public().(*gorsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported public key type %T, only RSA keys are supported", signer.Public())
+	}
+
+	hashed, err := hex.DecodeString(digest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode digest: %w", err)
+	}
+
+	sig, err := signer.Sign(rand.Reader, hashed, sctx.GetHash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign digest: %w", err)
+	}
+
+	signature := &signing.Signature{
+		Value:     hex.EncodeToString(sig),
+		MediaType: rsa.MediaType,
+		Algorithm: rsa.Algorithm,
+	}
+
+	if sctx.GetPublicKey() == nil {
+		return signature, nil
+	}
+
+	certs, err := signutils.GetCertificateChain(sctx.GetPublicKey(), false)
+	if err != nil || len(certs) == 0 {
+		// a plain public key, nothing to embed into the signature.
+		return signature, nil
+	}
+
+	if !public.Equal(certs[0].PublicKey) {
+		return nil, fmt.Errorf("certificate does not match the public key of the signer")
+	}
+
+	if err := signutils.VerifyCertificate(certs[0], certs[1:], sctx.GetRootCerts(), sctx.GetIssuer()); err != nil {
+		return nil, fmt.Errorf("failed to verify signing certificate: %w", err)
+	}
+
+	signature.MediaType = rsa.MediaTypePEM
+	signature.Value = string(signutils.SignatureBytesToPem(rsa.Algorithm, sig, certs...))
+	signature.Issuer = certs[0].Subject.String()
+
+	return signature, nil
+}